@@ -0,0 +1,423 @@
+// acao.go - Ações dos jogadores e comandos que as executam
+// Teclado, rede, gravações e bots produzem a mesma sequência de ações (Acao),
+// que o loop principal transforma em comandos (Comando) e executa em ordem.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// TipoAcao identifica o que o jogador quer fazer
+type TipoAcao int
+
+const (
+	AcaoNenhuma TipoAcao = iota
+	AcaoMover
+	AcaoInteragir
+	AcaoAtacar
+	AcaoUsarItem
+	AcaoPausar
+	AcaoSalvar
+	AcaoDesfazer
+	AcaoMenuTeclas
+	AcaoMissoes
+	AcaoHistorico
+	AcaoDepuracao
+	AcaoSair
+)
+
+// Nomes usados ao serializar as ações
+var nomesTipoAcao = map[TipoAcao]string{
+	AcaoNenhuma:    "",
+	AcaoMover:      "mover",
+	AcaoInteragir:  "interagir",
+	AcaoAtacar:     "atacar",
+	AcaoUsarItem:   "usar_item",
+	AcaoPausar:     "pausar",
+	AcaoSalvar:     "salvar",
+	AcaoDesfazer:   "desfazer",
+	AcaoMenuTeclas: "teclas",
+	AcaoMissoes:    "missoes",
+	AcaoHistorico:  "historico",
+	AcaoDepuracao:  "depuracao",
+	AcaoSair:       "sair",
+}
+
+func (t TipoAcao) String() string {
+	return nomesTipoAcao[t]
+}
+
+func (t TipoAcao) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *TipoAcao) UnmarshalText(texto []byte) error {
+	for tipo, nome := range nomesTipoAcao {
+		if nome == string(texto) {
+			*t = tipo
+			return nil
+		}
+	}
+	return fmt.Errorf("ação desconhecida: %q", texto)
+}
+
+// Direcao é o sentido de um movimento
+type Direcao int
+
+const (
+	DirecaoNenhuma Direcao = iota
+	DirecaoCima
+	DirecaoBaixo
+	DirecaoEsquerda
+	DirecaoDireita
+)
+
+var nomesDirecao = map[Direcao]string{
+	DirecaoNenhuma:  "",
+	DirecaoCima:     "cima",
+	DirecaoBaixo:    "baixo",
+	DirecaoEsquerda: "esquerda",
+	DirecaoDireita:  "direita",
+}
+
+func (d Direcao) String() string {
+	return nomesDirecao[d]
+}
+
+func (d Direcao) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Direcao) UnmarshalText(texto []byte) error {
+	for dir, nome := range nomesDirecao {
+		if nome == string(texto) {
+			*d = dir
+			return nil
+		}
+	}
+	return fmt.Errorf("direção desconhecida: %q", texto)
+}
+
+// Retorna o deslocamento (dx, dy) da direção
+func (d Direcao) Delta() (int, int) {
+	switch d {
+	case DirecaoCima:
+		return 0, -1
+	case DirecaoBaixo:
+		return 0, 1
+	case DirecaoEsquerda:
+		return -1, 0
+	case DirecaoDireita:
+		return 1, 0
+	}
+	return 0, 0
+}
+
+// Acao é uma intenção de um jogador, independente de onde ela veio
+type Acao struct {
+	Tipo    TipoAcao `json:"acao"`
+	Direcao Direcao  `json:"direcao,omitempty"`
+	Jogador int      `json:"jogador"` // índice do jogador na partida
+}
+
+// Comando executa uma ação no jogo e, quando possível, sabe desfazê-la
+type Comando interface {
+	Executar(jogo *Jogo) bool // retorna false quando o jogo deve terminar
+	Desfazer(jogo *Jogo) bool // retorna false se o comando não pode ser desfeito
+	Acao() Acao               // ação que originou o comando, usada para serializar
+}
+
+// Cria o comando correspondente a uma ação
+func comandoNovo(a Acao) Comando {
+	switch a.Tipo {
+	case AcaoMover:
+		return &ComandoMover{acao: a}
+	case AcaoInteragir:
+		return &ComandoInteragir{acao: a}
+	case AcaoAtacar:
+		return &ComandoAtacar{acao: a}
+	case AcaoUsarItem:
+		return &ComandoUsarItem{acao: a}
+	case AcaoSalvar:
+		return &ComandoSalvar{acao: a}
+	case AcaoSair:
+		return &ComandoSair{acao: a}
+	}
+	return nil
+}
+
+// Retorna o jogador que executa a ação, ou nil se ele não está na partida
+func acaoJogador(jogo *Jogo, a Acao) *Jogador {
+	if a.Jogador < 0 || a.Jogador >= len(jogo.Jogadores) {
+		return nil
+	}
+	return jogo.Jogadores[a.Jogador]
+}
+
+// ComandoMover anda uma célula na direção da ação
+type ComandoMover struct {
+	acao    Acao
+	moveu   bool    // só desfaz se o personagem realmente andou
+	antes   Posicao // onde o personagem estava
+	direcao Direcao // para onde ele estava virado
+}
+
+func (c *ComandoMover) Acao() Acao { return c.acao }
+
+func (c *ComandoMover) Executar(jogo *Jogo) bool {
+	if j := acaoJogador(jogo, c.acao); j != nil {
+		c.antes, c.direcao = *j.Posicao, j.Direcao
+		c.moveu = personagemMover(c.acao.Direcao, j, jogo)
+	}
+	return true
+}
+
+// Volta o personagem para onde estava, sem publicar o passo nem disparar os gatilhos
+// da célula: desfazer não é andar
+func (c *ComandoMover) Desfazer(jogo *Jogo) bool {
+	j := acaoJogador(jogo, c.acao)
+	if j == nil {
+		return false
+	}
+	if c.moveu {
+		// alguém ocupou a célula de onde o personagem saiu
+		if !jogoPodeMoverPara(jogo, c.antes.X, c.antes.Y) {
+			return false
+		}
+		jogo.Ocupantes.Mover(j.Ocupante, c.antes.X, c.antes.Y)
+	}
+	j.Direcao = c.direcao
+	return true
+}
+
+// ComandoInteragir abre caixas próximas ao jogador
+type ComandoInteragir struct{ acao Acao }
+
+func (c *ComandoInteragir) Acao() Acao { return c.acao }
+
+func (c *ComandoInteragir) Executar(jogo *Jogo) bool {
+	if j := acaoJogador(jogo, c.acao); j != nil {
+		personagemInteragir(j, jogo)
+	}
+	return true
+}
+
+// abrir uma caixa não pode ser desfeito
+func (c *ComandoInteragir) Desfazer(jogo *Jogo) bool { return false }
+
+// ComandoAtacar ataca o monstro se ele estiver ao lado do jogador
+type ComandoAtacar struct{ acao Acao }
+
+func (c *ComandoAtacar) Acao() Acao { return c.acao }
+
+func (c *ComandoAtacar) Executar(jogo *Jogo) bool {
+	if j := acaoJogador(jogo, c.acao); j != nil {
+		personagemAtacar(j, jogo)
+	}
+	return true
+}
+
+func (c *ComandoAtacar) Desfazer(jogo *Jogo) bool { return false }
+
+// ComandoUsarItem usa um item do inventário do jogador
+type ComandoUsarItem struct{ acao Acao }
+
+func (c *ComandoUsarItem) Acao() Acao { return c.acao }
+
+func (c *ComandoUsarItem) Executar(jogo *Jogo) bool {
+	j := acaoJogador(jogo, c.acao)
+	if j == nil || len(j.Itens) == 0 {
+		jogo.SetMessage(tr("item.nenhum"), 2*time.Second)
+		return true
+	}
+	// por enquanto os itens só servem como moeda de troca com o Guian
+	jogo.SetMessage(tr("item.lista", trItens(j.Itens)), 3*time.Second)
+	return true
+}
+
+func (c *ComandoUsarItem) Desfazer(jogo *Jogo) bool { return false }
+
+// ComandoSalvar grava a partida no arquivo de salvamento
+type ComandoSalvar struct{ acao Acao }
+
+func (c *ComandoSalvar) Acao() Acao { return c.acao }
+
+func (c *ComandoSalvar) Executar(jogo *Jogo) bool {
+	if err := jogoSalvar(jogo, ArquivoSalvamento); err != nil {
+		jogo.SetMessage(tr("salvar.erro", err), 5*time.Second)
+	} else {
+		jogo.SetMessage(tr("salvar.ok", ArquivoSalvamento), 3*time.Second)
+	}
+	return true
+}
+
+func (c *ComandoSalvar) Desfazer(jogo *Jogo) bool { return false }
+
+// ComandoSair encerra a partida
+type ComandoSair struct{ acao Acao }
+
+func (c *ComandoSair) Acao() Acao { return c.acao }
+
+func (c *ComandoSair) Executar(jogo *Jogo) bool { return false }
+
+func (c *ComandoSair) Desfazer(jogo *Jogo) bool { return false }
+
+// Executa uma ação de um jogador, registrando-a no histórico e na gravação.
+// Retorna false quando o jogo deve terminar.
+func jogoExecutarAcao(jogo *Jogo, a Acao) bool {
+	// com o jogo pausado ninguém age (a pausa é aberta e fechada pelo menu de pausa)
+	if jogo.Pausa.Ativa() && a.Tipo != AcaoSair {
+		return true
+	}
+
+	// desfazer não vira comando: ele consome o histórico, mas é gravado como os outros
+	if a.Tipo == AcaoDesfazer {
+		if jogo.Gravador != nil {
+			jogo.Gravador.Registrar(a)
+		}
+		jogoDesfazer(jogo)
+		return true
+	}
+
+	cmd := comandoNovo(a)
+	if cmd == nil {
+		return true
+	}
+
+	if jogo.Gravador != nil {
+		jogo.Gravador.Registrar(a)
+	}
+
+	continuar := cmd.Executar(jogo)
+
+	// no modo quebra-cabeça guardamos os comandos que alteram o mapa para poder desfazê-los
+	if jogo.QuebraCabeca && a.Tipo != AcaoSalvar {
+		jogo.Historico = append(jogo.Historico, cmd)
+	}
+	return continuar
+}
+
+// Desfaz o último comando do histórico (apenas no modo quebra-cabeça)
+func jogoDesfazer(jogo *Jogo) {
+	if !jogo.QuebraCabeca {
+		return
+	}
+	if len(jogo.Historico) == 0 {
+		jogo.SetMessage(tr("desfazer.vazio"), 2*time.Second)
+		return
+	}
+
+	ultimo := jogo.Historico[len(jogo.Historico)-1]
+	if !ultimo.Desfazer(jogo) {
+		// ações irreversíveis (como abrir uma caixa) encerram o histórico
+		jogo.Historico = nil
+		jogo.SetMessage(tr("desfazer.impossivel"), 2*time.Second)
+		return
+	}
+	jogo.Historico = jogo.Historico[:len(jogo.Historico)-1]
+}
+
+// AcaoGravada é uma ação com o instante em que aconteceu, relativo ao início da gravação
+type AcaoGravada struct {
+	Instante time.Duration `json:"t"`
+	Acao
+}
+
+// CabecalhoGravacao é a primeira linha da gravação: o que é preciso para repetir a
+// partida, como ela estava ao começar (depois das escolhas feitas na tela de título)
+type CabecalhoGravacao struct {
+	Semente      int64          `json:"semente"`
+	Mapa         string         `json:"mapa,omitempty"`
+	Dificuldade  string         `json:"dificuldade,omitempty"`
+	Meta         int            `json:"meta,omitempty"`
+	Modo         string         `json:"modo,omitempty"`
+	Disputa      string         `json:"disputa,omitempty"`
+	Local        bool           `json:"local,omitempty"`         // segundo jogador no mesmo teclado
+	QuebraCabeca bool           `json:"quebra_cabeca,omitempty"` // desfazer ligado
+	Campanha     *CampanhaSalva `json:"campanha,omitempty"`      // nível, pontos e itens com que o nível começou
+}
+
+// GravadorComandos grava a sequência de ações em JSON, uma por linha
+type GravadorComandos struct {
+	arquivo *os.File
+	enc     *json.Encoder
+	inicio  time.Time
+}
+
+// Cria o arquivo de gravação, começando pelo cabeçalho
+func gravadorNovo(nome string, cabecalho CabecalhoGravacao) (*GravadorComandos, error) {
+	arq, err := os.Create(nome)
+	if err != nil {
+		return nil, err
+	}
+	g := &GravadorComandos{arquivo: arq, enc: json.NewEncoder(arq), inicio: time.Now()}
+	if err := g.enc.Encode(cabecalho); err != nil {
+		arq.Close()
+		return nil, err
+	}
+	return g, nil
+}
+
+// Acrescenta uma ação à gravação
+func (g *GravadorComandos) Registrar(a Acao) {
+	g.enc.Encode(AcaoGravada{Instante: time.Since(g.inicio), Acao: a})
+}
+
+// Fecha o arquivo de gravação
+func (g *GravadorComandos) Fechar() error {
+	return g.arquivo.Close()
+}
+
+// Lê uma gravação: o cabeçalho (vazio nas gravações antigas, que não o têm) e as ações
+func gravacaoLer(nome string) (CabecalhoGravacao, []AcaoGravada, error) {
+	var cabecalho CabecalhoGravacao
+	arq, err := os.Open(nome)
+	if err != nil {
+		return cabecalho, nil, err
+	}
+	defer arq.Close()
+
+	var acoes []AcaoGravada
+	dec := json.NewDecoder(arq)
+	for linha := 1; ; linha++ {
+		var bruta map[string]json.RawMessage
+		if err := dec.Decode(&bruta); err == io.EOF {
+			break
+		} else if err != nil {
+			return cabecalho, nil, fmt.Errorf("%s:%d: %w", nome, linha, err)
+		}
+		dados, _ := json.Marshal(bruta)
+		if _, ok := bruta["semente"]; ok && linha == 1 {
+			if err := json.Unmarshal(dados, &cabecalho); err != nil {
+				return cabecalho, nil, fmt.Errorf("%s:%d: %w", nome, linha, err)
+			}
+			continue
+		}
+		var a AcaoGravada
+		if err := json.Unmarshal(dados, &a); err != nil {
+			return cabecalho, nil, fmt.Errorf("%s:%d: %w", nome, linha, err)
+		}
+		acoes = append(acoes, a)
+	}
+	return cabecalho, acoes, nil
+}
+
+// Envia as ações no canal nos mesmos instantes em que foram gravadas, divididos pela
+// velocidade (2 reproduz duas vezes mais rápido); fecha o canal no fim da gravação
+func gravacaoReproduzir(acoes []AcaoGravada, velocidade float64) <-chan Acao {
+	saida := make(chan Acao)
+	go func() {
+		defer close(saida)
+		inicio := time.Now()
+		for _, a := range acoes {
+			instante := time.Duration(float64(a.Instante) / velocidade)
+			time.Sleep(time.Until(inicio.Add(instante)))
+			saida <- a.Acao
+		}
+	}()
+	return saida
+}
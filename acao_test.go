@@ -0,0 +1,140 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Desfazer um passo volta o personagem para onde estava sem publicar outro passo
+func TestDesfazerMover(t *testing.T) {
+	jogo := jogoNovo()
+	if err := jogoCarregarMapa("mapa.txt", &jogo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogoEncerrarEntidades(&jogo) })
+	passos := jogo.Eventos.Assinar(EventoJogadorMoveu)
+	defer passos.Cancelar()
+
+	j := jogo.Jogadores[0]
+	antes, direcao := *j.Posicao, j.Direcao
+	var cmd *ComandoMover
+	for _, d := range []Direcao{DirecaoCima, DirecaoBaixo, DirecaoEsquerda, DirecaoDireita} {
+		cmd = &ComandoMover{acao: Acao{Tipo: AcaoMover, Direcao: d}}
+		if cmd.Executar(&jogo); cmd.moveu {
+			break
+		}
+	}
+	if !cmd.moveu {
+		t.Fatal("o personagem não tem para onde andar")
+	}
+	select {
+	case <-passos.C:
+	case <-time.After(time.Second):
+		t.Fatal("o passo não foi publicado")
+	}
+
+	if !cmd.Desfazer(&jogo) {
+		t.Fatal("o passo não pôde ser desfeito")
+	}
+	if *j.Posicao != antes || j.Direcao != direcao {
+		t.Errorf("depois de desfazer: %v virado para %v, esperado %v virado para %v", *j.Posicao, j.Direcao, antes, direcao)
+	}
+	if ids := jogo.Ocupantes.Em(antes.X, antes.Y); len(ids) != 1 || ids[0] != j.Ocupante {
+		t.Errorf("entidades em %v: %v", antes, ids)
+	}
+	select {
+	case ev := <-passos.C:
+		t.Errorf("desfazer publicou um passo para (%d, %d)", ev.X, ev.Y)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// Cada nova partida recomeça a gravação com as escolhas feitas antes dela
+func TestGravacaoCabecalhoDaPartida(t *testing.T) {
+	jogo := jogoNovo()
+	jogo.Semente = 7
+	jogo.QuebraCabeca = true
+	t.Cleanup(func() { jogoEncerrarEntidades(&jogo) })
+	arquivo := filepath.Join(t.TempDir(), "partida.jsonl")
+	s := &Sessao{Jogo: &jogo, Mapa: "mapa.txt", Configuracao: jogo.Configuracao, Modo: ModoPadrao,
+		Disputa: DisputaCompetitiva, Gravar: arquivo}
+	if err := s.NovaPartida(); err != nil {
+		t.Fatal(err)
+	}
+	// escolhas da tela de título para a próxima partida
+	s.Local = true
+	if err := s.NovaPartida(); err != nil {
+		t.Fatal(err)
+	}
+	jogo.Gravador.Registrar(Acao{Tipo: AcaoMover, Direcao: DirecaoCima})
+	jogo.Gravador.Fechar()
+	jogo.Gravador = nil
+
+	cab, acoes, err := gravacaoLer(arquivo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperado := CabecalhoGravacao{Semente: 7, Mapa: "mapa.txt", Dificuldade: jogo.Configuracao.Dificuldade,
+		Meta: jogo.MetaTesouros, Modo: ModoPadrao, Disputa: "competitivo", Local: true, QuebraCabeca: true}
+	if cab != esperado {
+		t.Errorf("cabeçalho %+v, esperado %+v", cab, esperado)
+	}
+	if len(acoes) != 1 {
+		t.Errorf("%d ações gravadas, esperada 1 (a da última partida)", len(acoes))
+	}
+}
+
+// Com o jogo pausado, desfazer não faz nada; fora da pausa, desfaz e é gravado
+func TestDesfazerPausadoEGravado(t *testing.T) {
+	jogo := jogoNovo()
+	jogo.QuebraCabeca = true
+	if err := jogoCarregarMapa("mapa.txt", &jogo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogoEncerrarEntidades(&jogo) })
+	arquivo := filepath.Join(t.TempDir(), "partida.jsonl")
+	gravador, err := gravadorNovo(arquivo, CabecalhoGravacao{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jogo.Gravador = gravador
+
+	j := jogo.Jogadores[0]
+	antes := *j.Posicao
+	for _, d := range []Direcao{DirecaoCima, DirecaoBaixo, DirecaoEsquerda, DirecaoDireita} {
+		if jogoExecutarAcao(&jogo, Acao{Tipo: AcaoMover, Direcao: d}); *j.Posicao != antes {
+			break
+		}
+	}
+	depois := *j.Posicao
+	if depois == antes {
+		t.Fatal("o personagem não tem para onde andar")
+	}
+
+	jogo.Pausa.Pausar()
+	jogoExecutarAcao(&jogo, Acao{Tipo: AcaoDesfazer})
+	if *j.Posicao != depois {
+		t.Errorf("desfeito com o jogo pausado: %v, esperado %v", *j.Posicao, depois)
+	}
+	jogo.Pausa.Retomar()
+	jogoExecutarAcao(&jogo, Acao{Tipo: AcaoDesfazer})
+	if *j.Posicao != antes {
+		t.Errorf("depois de desfazer: %v, esperado %v", *j.Posicao, antes)
+	}
+
+	jogo.Gravador.Fechar()
+	jogo.Gravador = nil
+	_, acoes, err := gravacaoLer(arquivo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(acoes); n == 0 || acoes[n-1].Tipo != AcaoDesfazer {
+		t.Fatalf("desfazer não gravado: %+v", acoes)
+	}
+	for _, a := range acoes[:len(acoes)-1] {
+		if a.Tipo == AcaoDesfazer {
+			t.Errorf("desfazer gravado durante a pausa: %+v", acoes)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// animacao.go - Animações desenhadas pelo renderizador
+// Uma animação é uma sequência de quadros com duração, presa a uma célula do mapa (a
+// caixa que acabou de abrir) ou a uma entidade (o jogador que perdeu um tesouro). As
+// goroutines do jogo só mudam o estado e publicam eventos; quem anima é o renderizador,
+// que a cada desenho escolhe o quadro atual de cada animação.
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Quadro é um momento da animação: como o alvo aparece e por quanto tempo
+type Quadro struct {
+	Duracao   time.Duration
+	Oculto    bool // o alvo não aparece neste quadro
+	Simbolo   rune // 0: mantém o símbolo do alvo
+	Cor       Cor  // 0: mantém a cor do alvo
+	Fundo     Cor  // 0: mantém o fundo do alvo
+	Atributos Cor  // somados à cor do alvo (ex: termbox.AttrDim)
+}
+
+// Sequências de quadros de cada animação, pelo nome
+var animacoes = map[string][]Quadro{
+	// a caixa aberta mostra o conteúdo e pisca até sumir
+	"caixa_aberta": piscar(500*time.Millisecond, 5, 100*time.Millisecond),
+
+	// com tesouro, ela ainda brilha antes de sumir
+	"caixa_tesouro": append(piscar(500*time.Millisecond, 5, 100*time.Millisecond),
+		Quadro{Duracao: 120 * time.Millisecond, Simbolo: '✦', Cor: CorAmarela},
+		Quadro{Duracao: 120 * time.Millisecond, Simbolo: '✧', Cor: CorAmarela},
+		Quadro{Duracao: 120 * time.Millisecond, Simbolo: '·', Cor: CorAmarela},
+	),
+
+	// o jogador atingido pisca em vermelho
+	"dano": {
+		{Duracao: 100 * time.Millisecond, Fundo: CorVermelho},
+		{Duracao: 100 * time.Millisecond},
+		{Duracao: 100 * time.Millisecond, Fundo: CorVermelho},
+		{Duracao: 100 * time.Millisecond},
+		{Duracao: 100 * time.Millisecond, Fundo: CorVermelho},
+	},
+
+	// o monstro derrotado esmaece até desaparecer
+	"sumir": {
+		{Duracao: 150 * time.Millisecond},
+		{Duracao: 150 * time.Millisecond, Atributos: termbox.AttrDim},
+		{Duracao: 150 * time.Millisecond, Cor: CorCinzaEscuro},
+		{Duracao: 150 * time.Millisecond, Simbolo: '·', Cor: CorCinzaEscuro},
+	},
+}
+
+// Mostra o alvo por um tempo e depois o faz piscar algumas vezes
+func piscar(espera time.Duration, vezes int, intervalo time.Duration) []Quadro {
+	quadros := []Quadro{{Duracao: espera}}
+	for i := 0; i < vezes; i++ {
+		quadros = append(quadros, Quadro{Duracao: intervalo, Oculto: true}, Quadro{Duracao: intervalo})
+	}
+	return quadros
+}
+
+// Entidade identifica um personagem animado; o cliente da rede recria os jogadores
+// a cada estado, então a identificação não pode ser um ponteiro
+type Entidade struct {
+	Tipo string `json:"tipo"` // "jogador", "monstro" ou "guian"
+	ID   int    `json:"id"`
+}
+
+// Disparo pede uma animação, numa célula (com o papel do tema que ela mostra) ou numa entidade
+type Disparo struct {
+	Nome     string    `json:"nome"`
+	Papel    string    `json:"papel,omitempty"` // elemento mostrado na célula (ex: "caixa_tesouro")
+	X        int       `json:"x,omitempty"`
+	Y        int       `json:"y,omitempty"`
+	Entidade *Entidade `json:"entidade,omitempty"`
+}
+
+// animacaoAtiva é uma animação em andamento
+type animacaoAtiva struct {
+	quadros  []Quadro
+	inicio   time.Time
+	atual    int // quadro atual, escolhido em Avancar
+	x, y     int
+	elemento Elemento // o que a célula mostra (animações de célula)
+}
+
+// Animacoes guarda as animações em andamento; pode ser usado por várias goroutines
+type Animacoes struct {
+	mu        sync.Mutex
+	celulas   []*animacaoAtiva
+	entidades map[Entidade]*animacaoAtiva
+}
+
+func animacoesNovas() *Animacoes {
+	return &Animacoes{entidades: make(map[Entidade]*animacaoAtiva)}
+}
+
+// Começa a animação pedida; uma nova animação no mesmo alvo substitui a anterior
+func (a *Animacoes) Iniciar(d Disparo, agora time.Time) {
+	quadros, ok := animacoes[d.Nome]
+	if !ok {
+		return
+	}
+	nova := &animacaoAtiva{quadros: quadros, inicio: agora, x: d.X, y: d.Y}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if d.Entidade != nil {
+		a.entidades[*d.Entidade] = nova
+		return
+	}
+	e, ok := temaPapeisElementos()[d.Papel]
+	if !ok {
+		return
+	}
+	nova.elemento = e
+	for i, c := range a.celulas {
+		if c.x == d.X && c.y == d.Y {
+			a.celulas[i] = nova
+			return
+		}
+	}
+	a.celulas = append(a.celulas, nova)
+}
+
+// Escolhe o quadro atual de cada animação e descarta as que terminaram
+func (a *Animacoes) Avancar(agora time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ativas := a.celulas[:0]
+	for _, c := range a.celulas {
+		if c.avancar(agora) {
+			ativas = append(ativas, c)
+		}
+	}
+	clear(a.celulas[len(ativas):])
+	a.celulas = ativas
+
+	for alvo, e := range a.entidades {
+		if !e.avancar(agora) {
+			delete(a.entidades, alvo)
+		}
+	}
+}
+
+// Atualiza o quadro atual; retorna false quando a animação acabou
+func (e *animacaoAtiva) avancar(agora time.Time) bool {
+	decorrido := agora.Sub(e.inicio)
+	for i, q := range e.quadros {
+		if decorrido < q.Duracao {
+			e.atual = i
+			return true
+		}
+		decorrido -= q.Duracao
+	}
+	return false
+}
+
+// Desenha as animações presas às células do mapa
+func (a *Animacoes) DesenharCelulas() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, c := range a.celulas {
+		interfaceDesenharQuadro(c.x, c.y, c.elemento, c.quadros[c.atual])
+	}
+}
+
+// Desenha uma entidade, com o quadro da sua animação se houver uma em andamento
+func (a *Animacoes) DesenharEntidade(alvo Entidade, x, y int, elem Elemento) {
+	a.mu.Lock()
+	e, ok := a.entidades[alvo]
+	var q Quadro
+	if ok {
+		q = e.quadros[e.atual]
+	}
+	a.mu.Unlock()
+	interfaceDesenharQuadro(x, y, elem, q)
+}
+
+// Descarta todas as animações (ao começar outra partida)
+func (a *Animacoes) Limpar() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.celulas = nil
+	clear(a.entidades)
+}
+
+// Como o elemento aparece no quadro, partindo da aparência do tema
+func (q Quadro) aplicar(e Elemento) Elemento {
+	e = temaElemento(e)
+	if q.Simbolo != 0 {
+		e.simbolo = q.Simbolo
+	}
+	if q.Cor != 0 {
+		e.cor = temaCor(q.Cor)
+	}
+	if q.Fundo != 0 {
+		e.corFundo = temaCor(q.Fundo)
+	}
+	e.cor |= q.Atributos
+	return e
+}
+
+// Animações que cada evento da partida dispara
+func animacoesDoEvento(ev Evento) []Disparo {
+	var disparos []Disparo
+	jogador := func() {
+		if ev.Jogador != nil {
+			disparos = append(disparos, Disparo{Nome: "dano", Entidade: &Entidade{"jogador", ev.JogadorID}})
+		}
+	}
+	switch ev.Tipo {
+	case EventoCaixaVazia:
+		disparos = append(disparos, Disparo{Nome: "caixa_aberta", Papel: "caixa_vazia", X: ev.X, Y: ev.Y})
+	case EventoTesouroEncontrado:
+		disparos = append(disparos, Disparo{Nome: "caixa_tesouro", Papel: "caixa_tesouro", X: ev.X, Y: ev.Y})
+	case EventoArmadilha:
+		disparos = append(disparos, Disparo{Nome: "caixa_aberta", Papel: "caixa_armadilha", X: ev.X, Y: ev.Y})
+		jogador()
+	case EventoTesouroRoubado:
+		jogador()
+	case EventoMonstroDerrotado:
+		disparos = append(disparos, Disparo{Nome: "sumir", Papel: "monstro", X: ev.X, Y: ev.Y})
+	}
+	return disparos
+}
+
+// Tipos de evento que disparam animações
+var eventosAnimados = []TipoEvento{
+	EventoCaixaVazia, EventoTesouroEncontrado, EventoArmadilha, EventoTesouroRoubado, EventoMonstroDerrotado,
+}
+
+// Assina os eventos da partida e começa as animações que eles disparam.
+// Vale para todas as partidas da sessão, até o canal parar ser fechado.
+func animacoesAssinar(jogo *Jogo, parar <-chan struct{}) {
+	jogoAssinar(jogo, parar, func(ev Evento) {
+		for _, d := range animacoesDoEvento(ev) {
+			jogo.Animacoes.Iniciar(d, time.Now())
+		}
+	}, eventosAnimados...)
+}
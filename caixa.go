@@ -1,126 +1,157 @@
 package main
 
 import (
+	"fmt"
+	"log/slog"
 	"math/rand"
-	"sync"
 	"time"
 )
 
-type TipoCaixa int 
+type TipoCaixa int
 
 const (
-	VAZIA      TipoCaixa = 0
-	TESOURO    TipoCaixa = 1
-	ARMADILHA  TipoCaixa = 2
+	VAZIA     TipoCaixa = 0
+	TESOURO   TipoCaixa = 1
+	ARMADILHA TipoCaixa = 2
 )
 
+// nome do conteúdo da caixa no registro (log.go), que não depende do idioma
+func (t TipoCaixa) Nome() string {
+	switch t {
+	case TESOURO:
+		return "tesouro"
+	case ARMADILHA:
+		return "armadilha"
+	}
+	return "vazia"
+}
+
+// nome do conteúdo da caixa, usado quando o Guian revela uma caixa
+func (t TipoCaixa) String() string {
+	switch t {
+	case TESOURO:
+		return tr("caixa.tesouro")
+	case ARMADILHA:
+		return tr("caixa.armadilha")
+	}
+	return tr("caixa.vazia")
+}
+
+// caixas fechadas cujo conteúdo já foi revelado pelo Guian ganham a cor do conteúdo
+var caixasReveladas = map[TipoCaixa]Elemento{
+	VAZIA:     {'▣', CorCinzaEscuro, CorPadrao, true},
+	TESOURO:   {'▣', CorVerde, CorPadrao, true},
+	ARMADILHA: {'▣', CorVermelho, CorPadrao, true},
+}
+
 type Caixa struct {
-	X, Y        int
+	*Posicao
+	Ocupante    IDEntidade // entidade da caixa no mapa (ecs.go)
 	Tipo        TipoCaixa
-	Mapa        *[][] Elemento 
-	Mutex       *sync.Mutex
-	Interacao   chan bool
+	desenho     *Desenhavel // aparência da caixa, que muda quando ela é revelada
 	Interagindo bool
-	Removida     bool
+	Removida    bool
+	Revelada    bool // se o Guian já contou o que há dentro
 }
 
-// iniciando uma goroutine para a caixa mudar de lugar
-// a cada 20 segundos
+// como a caixa fechada aparece no mapa
+func (c *Caixa) elemento() Elemento {
+	if c.Revelada {
+		return caixasReveladas[c.Tipo]
+	}
+	return CaixaElemento
+}
 
-func (c *Caixa) Iniciar(jogo *Jogo) {
-	go func() {
-		for !c.Removida {
-			select {
-				case <-time.After(20 * time.Second):
-					c.mover();
-				case <-c.Interacao:
-					c.efeito(jogo)
-					return
-			}
-		}
-	}()
+// revela o conteúdo da caixa (chamado pelo dono do estado, mundo.go)
+func (c *Caixa) Revelar() {
+	c.Revelada = true
+	c.desenho.Elemento = c.elemento()
 }
 
-// movendo a caixa aleatoriamente
-func (c *Caixa) mover() {
-	c.Mutex.Lock()
-	defer c.Mutex.Unlock()
+// registrando a caixa como entidade: ela bloqueia a passagem e, de tempos em tempos,
+// sua IA escolhe pelo retrato do mundo um lugar para onde ela vai; quem muda a caixa
+// de lugar é o dono do estado
+func (c *Caixa) Iniciar(jogo *Jogo) {
+	o := jogo.Ocupantes
+	c.Ocupante = o.Criar()
+	c.desenho.Alvo = Entidade{"caixa", int(c.Ocupante)}
+	o.Colocar(c.Ocupante, c.Posicao)
+	o.Donos[c.Ocupante] = c
+	o.Desenhos[c.Ocupante] = c.desenho
+	o.Bloqueantes[c.Ocupante] = Bloqueante{}
+	o.Interativos[c.Ocupante] = &Interativo{c.interagir}
+	o.IAs[c.Ocupante] = &IA{
+		Nome:      fmt.Sprintf("caixa (%d, %d)", c.X, c.Y),
+		Intervalo: time.Duration(jogo.Configuracao.CaixaMove),
+		Decidir:   c.decidir,
+	}
+	sistemaIAIniciar(jogo, c.Ocupante)
+}
 
-	(*c.Mapa)[c.Y][c.X] = Vazio
+func (c *Caixa) decidir(r *Retrato, eu Posicao) Intencao {
+	if x, y, ok := caixaSortearDestino(r); ok {
+		return IntencaoMoverCaixa{c, x, y}
+	}
+	return nil
+}
 
-	largura := len((*c.Mapa)[0])
-	altura := len(*c.Mapa)
+// sorteia uma célula vazia e livre do retrato para onde a caixa pode ir
+func caixaSortearDestino(r *Retrato) (int, int, bool) {
+	if len(r.Mapa) == 0 || len(r.Mapa[0]) == 0 {
+		return 0, 0, false
+	}
+	largura := len(r.Mapa[0])
+	altura := len(r.Mapa)
 
-	for {
+	for tentativas := 0; tentativas < largura*altura; tentativas++ {
 		novoX := rand.Intn(largura)
 		novoY := rand.Intn(altura)
 
-		if (*c.Mapa)[novoY][novoX] == Vazio {
-			c.X = novoX
-			c.Y = novoY
-			(*c.Mapa)[novoY][novoX] = CaixaElemento
-			break
+		if r.Mapa[novoY][novoX] == Vazio && r.Livre(novoX, novoY) {
+			return novoX, novoY, true
 		}
 	}
+	return 0, 0, false
 }
 
-// consequencias de cada tipo de caixa
-func (c *Caixa) efeito(jogo *Jogo) {
-	c.Mutex.Lock()
-	defer c.Mutex.Unlock()
-
-	// comportamento de cada caixa
-	switch c.Tipo {
-		case VAZIA:
-			jogo.SetMessage("...CAIXA VAZIA!", 3*time.Second)
-			(*c.Mapa)[c.Y][c.X] = Elemento{'■', CorCinzaEscuro, CorPadrao, false}
-		
-		case TESOURO:
-			jogo.SetMessage("TESOURO ENCONTRADO!", 3*time.Second)
-			jogo.Tesouros++
-			(*c.Mapa)[c.Y][c.X] = Elemento{'■', CorVerde, CorPadrao, false}
-			exibirMensagemTesouros(jogo)
-			
-			if jogo.Tesouros == 4 {
-				jogo.SetMessage("Parabéns! Você encontrou todos os 4 tesouros!", 6*time.Second)
-				jogo.FimDeJogo = true
-				return
-			}
-		
-		case ARMADILHA:
-			jogo.SetMessage("GAME OVER!", 6*time.Second)
-			(*c.Mapa)[c.Y][c.X] = Elemento{'■', CorVermelho, CorPadrao, false}
-			jogo.FimDeJogo = true
-			return
-		}
-
-	// atualizando a tela para mostrar a cor da caixa
-	interfaceDesenharJogo(jogo)
-	interfaceAtualizarTela()
-
-	time.Sleep(500 * time.Millisecond)
+// IntencaoMoverCaixa pede para a caixa mudar para (X, Y)
+type IntencaoMoverCaixa struct {
+	Caixa *Caixa
+	X, Y  int
+}
 
-	// caixa desaparecendo
-	for i := 0; i < 10; i++ {
-		time.Sleep(100 * time.Millisecond)
+// movendo a caixa; desde o retrato a caixa pode ter sido aberta ou o destino ocupado
+func (in IntencaoMoverCaixa) Aplicar(jogo *Jogo) {
+	c := in.Caixa
+	if c.Removida || in.Y >= len(jogo.Mapa) || in.X >= len(jogo.Mapa[in.Y]) ||
+		jogo.Mapa[in.Y][in.X] != Vazio || jogo.Ocupantes.Bloqueado(in.X, in.Y) {
+		return
+	}
 
-		if i%2 == 0 {
-			(*c.Mapa)[c.Y][c.X] = Vazio
-		} else {
-			switch c.Tipo {
-				case VAZIA: (*c.Mapa)[c.Y][c.X] = Elemento{'■', CorCinzaEscuro, CorPadrao, false}
+	slog.Debug("caixa mudou de lugar", "de_x", c.X, "de_y", c.Y, "x", in.X, "y", in.Y)
+	jogo.Ocupantes.Mover(c.Ocupante, in.X, in.Y)
+}
 
-				case TESOURO: (*c.Mapa)[c.Y][c.X] = Elemento{'■', CorVerde, CorPadrao, false}
+// consequencias de cada tipo de caixa; a caixa some do mapa na hora e quem
+// mostra ela abrindo e piscando é a animação disparada pelo evento (animacao.go)
+func (c *Caixa) efeito(jogo *Jogo, j *Jogador) {
+	c.Removida = true
+	jogo.Ocupantes.Remover(c.Ocupante) // encerra também a IA da caixa
+	slog.Info("caixa aberta", "x", c.X, "y", c.Y, "tipo", c.Tipo.Nome(), "jogador", j.ID)
 
-				case ARMADILHA: (*c.Mapa)[c.Y][c.X] = Elemento{'■', CorVermelho, CorPadrao, false}
-			}
-		}
-		
-		interfaceDesenharJogo(jogo)
-		interfaceAtualizarTela()
+	// comportamento de cada caixa
+	switch c.Tipo {
+	case VAZIA:
+		jogoPublicar(jogo, Evento{Tipo: EventoCaixaVazia, Jogador: j, X: c.X, Y: c.Y})
+
+	case TESOURO:
+		jogo.Tesouros++
+		j.Tesouros++
+		// o modo de jogo decide se a partida acabou (modo.go)
+		jogoPublicar(jogo, Evento{Tipo: EventoTesouroEncontrado, Jogador: j, X: c.X, Y: c.Y})
+
+	case ARMADILHA:
+		jogoFerir(jogo, j.Ocupante, 1)
+		jogoPublicar(jogo, Evento{Tipo: EventoArmadilha, Jogador: j, X: c.X, Y: c.Y})
 	}
-
-	// após a animação, removemos a caixa permanentemente
-	(*c.Mapa)[c.Y][c.X] = Vazio
-	c.Removida = true
 }
@@ -0,0 +1,273 @@
+// campanha.go - Campanha: mapas jogados em sequência, ligados por portais
+// Uma campanha é um arquivo JSON com os níveis na ordem em que são jogados, por exemplo:
+//
+//	{
+//	  "nome": "A busca pelos tesouros",
+//	  "niveis": [
+//	    {"nome": "A floresta", "mapa": "mapa.txt"},
+//	    {"nome": "O labirinto", "mapa": "maze.txt"}
+//	  ]
+//	}
+//
+// Os mapas são relativos à pasta do arquivo da campanha. Um mapa pode ter portais ('◊'):
+// eles se abrem quando os objetivos principais do nível são concluídos, e o nível só
+// termina quando um jogador entra num deles. Os itens dos jogadores e os pontos passam
+// de um nível para o outro; os tesouros contam só para a meta de cada nível.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Pontos ganhos na partida
+const (
+	PontosTesouro  = 100 // por tesouro encontrado
+	PontosMonstro  = 50  // por monstro derrotado
+	PontosObjetivo = 25  // por objetivo concluído
+	PontosNivel    = 200 // por nível vencido
+)
+
+// NivelCampanha é um dos mapas da campanha
+type NivelCampanha struct {
+	Nome string `json:"nome"`
+	Mapa string `json:"mapa"`
+}
+
+// Campanha é a lista de níveis, na ordem em que são jogados
+type Campanha struct {
+	Nome   string          `json:"nome"`
+	Niveis []NivelCampanha `json:"niveis"`
+}
+
+// ProgressoCampanha é o andamento da campanha na sessão; ao contrário do resto do
+// estado da partida, sobrevive à troca de mapa (jogoLimpar). O perfil do jogador o
+// guarda a cada nível vencido, para a campanha continuar na próxima sessão.
+type ProgressoCampanha struct {
+	Arquivo  string     `json:"campanha"`
+	Campanha *Campanha  `json:"-"`
+	Nivel    int        `json:"nivel"`  // índice do nível atual em Campanha.Niveis
+	Pontos   int        `json:"pontos"` // pontos feitos nos níveis já vencidos
+	Itens    [][]string `json:"itens"`  // itens de cada jogador ao começar o nível atual
+}
+
+// Lê e confere o arquivo de uma campanha
+func campanhaCarregar(nome string) (*Campanha, error) {
+	dados, err := os.ReadFile(nome)
+	if err != nil {
+		return nil, err
+	}
+	var c Campanha
+	if err := json.Unmarshal(dados, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", nome, err)
+	}
+	if len(c.Niveis) == 0 {
+		return nil, fmt.Errorf("%s: a campanha não tem níveis", nome)
+	}
+	if c.Nome == "" {
+		c.Nome = strings.TrimSuffix(filepath.Base(nome), filepath.Ext(nome))
+	}
+	pasta := filepath.Dir(nome)
+	for i := range c.Niveis {
+		n := &c.Niveis[i]
+		if n.Mapa == "" {
+			return nil, fmt.Errorf("%s: nível %d sem mapa", nome, i+1)
+		}
+		if !filepath.IsAbs(n.Mapa) {
+			n.Mapa = filepath.Join(pasta, n.Mapa)
+		}
+		if _, err := os.Stat(n.Mapa); err != nil {
+			return nil, fmt.Errorf("%s: nível %d: %w", nome, i+1, err)
+		}
+		if n.Nome == "" {
+			n.Nome = filepath.Base(n.Mapa)
+		}
+	}
+	return &c, nil
+}
+
+// Começa a campanha pelo primeiro nível
+func progressoNovo(arquivo string, c *Campanha) *ProgressoCampanha {
+	return &ProgressoCampanha{Arquivo: arquivo, Campanha: c}
+}
+
+// Continua a campanha de onde o arquivo de andamento parou, se ele for desta campanha;
+// sem arquivo, ela começa do primeiro nível
+func (p *ProgressoCampanha) Restaurar(nome string) error {
+	dados, err := os.ReadFile(nome)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var salvo ProgressoCampanha
+	if err := json.Unmarshal(dados, &salvo); err != nil {
+		return fmt.Errorf("%s: %w", nome, err)
+	}
+	arquivo, _ := filepath.Abs(p.Arquivo)
+	if salvo.Arquivo != arquivo || salvo.Nivel < 0 || salvo.Nivel >= len(p.Campanha.Niveis) {
+		return nil
+	}
+	p.Nivel, p.Pontos, p.Itens = salvo.Nivel, salvo.Pontos, salvo.Itens
+	return nil
+}
+
+// Grava o andamento da campanha, identificada pelo caminho completo do arquivo dela
+func (p *ProgressoCampanha) Gravar(nome string) error {
+	salvo := *p
+	salvo.Arquivo, _ = filepath.Abs(p.Arquivo)
+	dados, err := json.MarshalIndent(salvo, "", "  ")
+	if err != nil {
+		return err
+	}
+	return gravarAtomico(nome, dados)
+}
+
+// Nível sendo jogado
+func (p *ProgressoCampanha) Atual() NivelCampanha {
+	return p.Campanha.Niveis[p.Nivel]
+}
+
+// Indica se o nível atual é o último da campanha
+func (p *ProgressoCampanha) Ultimo() bool {
+	return p.Nivel == len(p.Campanha.Niveis)-1
+}
+
+// Texto "Nível 1/2: A floresta"
+func (p *ProgressoCampanha) Texto() string {
+	return tr("campanha.nivel", p.Nivel+1, len(p.Campanha.Niveis), p.Atual().Nome)
+}
+
+// Guarda os pontos e os itens do nível vencido e passa ao seguinte
+func (p *ProgressoCampanha) Avancar(jogo *Jogo) error {
+	if p.Ultimo() {
+		return errors.New(tr("campanha.sem_proximo"))
+	}
+	p.Pontos += jogo.Pontos
+	p.Itens = nil
+	for _, j := range jogo.Jogadores {
+		p.Itens = append(p.Itens, append([]string(nil), j.Itens...))
+	}
+	p.Nivel++
+	return nil
+}
+
+// Devolve aos jogadores os itens que eles tinham ao começar o nível
+func (p *ProgressoCampanha) RestaurarItens(jogo *Jogo) {
+	for i, j := range jogo.Jogadores {
+		if i < len(p.Itens) {
+			j.Itens = append([]string(nil), p.Itens[i]...)
+		}
+	}
+}
+
+// Pontos da sessão: os do nível atual mais os dos níveis já vencidos da campanha
+func jogoPontosTotal(jogo *Jogo) int {
+	if p := jogo.Campanha; p != nil {
+		return p.Pontos + jogo.Pontos
+	}
+	return jogo.Pontos
+}
+
+// Pontos que cada evento vale
+func pontosDoEvento(ev Evento) int {
+	switch ev.Tipo {
+	case EventoTesouroEncontrado:
+		return PontosTesouro
+	case EventoMonstroDerrotado:
+		return PontosMonstro
+	case EventoObjetivoConcluido:
+		return PontosObjetivo
+	}
+	return 0
+}
+
+// Elementos visuais do portal
+var (
+	PortalFechado = Elemento{'◊', CorCinzaEscuro, CorPadrao, false}
+	PortalAberto  = Elemento{'◊', CorRoxa, CorPadrao, false}
+)
+
+// Portal leva ao próximo nível; ele se abre quando o nível é vencido
+type Portal struct {
+	*Posicao
+	Ocupante IDEntidade // entidade do portal no mapa (ecs.go)
+	Aberto   bool
+	Mensagem string // motivo da vitória, exibido quando um jogador entra no portal aberto
+	desenho  *Desenhavel
+}
+
+// Coloca um portal fechado em (x, y)
+func jogoAdicionarPortal(jogo *Jogo, x, y int) *Portal {
+	p := &Portal{Posicao: &Posicao{x, y}, desenho: &Desenhavel{Elemento: PortalFechado, Camada: CamadaObjeto}}
+	o := jogo.Ocupantes
+	p.Ocupante = o.Criar()
+	p.desenho.Alvo = Entidade{"portal", int(p.Ocupante)}
+	o.Colocar(p.Ocupante, p.Posicao)
+	o.Donos[p.Ocupante] = p
+	o.Desenhos[p.Ocupante] = p.desenho
+	o.Gatilhos[p.Ocupante] = &Gatilho{p.aoEntrar}
+	return p
+}
+
+// Abre o portal; quem entrar nele vence o nível com a mensagem informada
+func (p *Portal) Abrir(mensagem string) {
+	p.Aberto = true
+	p.Mensagem = mensagem
+	p.desenho.Elemento = PortalAberto
+}
+
+func (p *Portal) aoEntrar(jogo *Jogo, j *Jogador) {
+	if !p.Aberto {
+		jogo.SetMessage(tr("portal.fechado"), 2*time.Second)
+		return
+	}
+	slog.Info("jogador entrou no portal", "x", p.X, "y", p.Y, "jogador", j.ID)
+	jogoEncerrar(jogo, true, p.Mensagem)
+}
+
+// Vence o nível. Numa campanha, num mapa com portais, eles se abrem e o nível só
+// termina quando um jogador entra num deles; fora da campanha ou sem portais, termina
+// na hora.
+func jogoVencer(jogo *Jogo, mensagem string) {
+	if jogo.Campanha == nil {
+		jogoEncerrar(jogo, true, mensagem)
+		return
+	}
+	var portais []*Portal
+	for _, dono := range jogo.Ocupantes.Donos {
+		if p, ok := dono.(*Portal); ok {
+			if p.Aberto {
+				return // já vencido, esperando alguém entrar
+			}
+			portais = append(portais, p)
+		}
+	}
+	if len(portais) == 0 {
+		jogoEncerrar(jogo, true, mensagem)
+		return
+	}
+	for _, p := range portais {
+		p.Abrir(mensagem)
+	}
+	jogo.Notificar(tr("portal.aberto"), PrioridadeAviso, 5*time.Second)
+	slog.Info("portais abertos", "portais", len(portais))
+}
+
+// Indica se o nível já foi vencido e os portais esperam um jogador
+func jogoPortaisAbertos(jogo *Jogo) bool {
+	for _, dono := range jogo.Ocupantes.Donos {
+		if p, ok := dono.(*Portal); ok && p.Aberto {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Fora de uma campanha, cumprir os objetivos encerra a partida mesmo num mapa com portais
+func TestVencerSemCampanha(t *testing.T) {
+	jogo := jogoNovo()
+	if err := jogoCarregarMapa("mapa.txt", &jogo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogoEncerrarEntidades(&jogo) })
+
+	jogoVencer(&jogo, "vitória")
+	if !jogo.FimDeJogo || !jogo.Vitoria {
+		t.Fatal("a partida não terminou com a vitória")
+	}
+	if jogoPortaisAbertos(&jogo) {
+		t.Error("portais abertos fora de uma campanha")
+	}
+}
+
+// Numa campanha, os portais se abrem e o relógio do contra o relógio para
+func TestVencerNaCampanhaParaRelogio(t *testing.T) {
+	jogo := jogoNovo()
+	if err := jogoCarregarMapa("mapa.txt", &jogo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogoEncerrarEntidades(&jogo) })
+	jogo.Campanha = &ProgressoCampanha{}
+	modo := &ModoContraRelogio{}
+	jogo.Modo = modo
+	modo.Iniciar(&jogo)
+
+	jogoVencer(&jogo, "vitória")
+	if jogo.FimDeJogo {
+		t.Fatal("o nível terminou antes de alguém entrar no portal")
+	}
+	if !jogoPortaisAbertos(&jogo) {
+		t.Fatal("os portais não se abriram")
+	}
+	restante := modo.Restante
+	modo.Tratar(&jogo, Evento{Tipo: EventoTempo, Tempo: restante + time.Second})
+	if jogo.FimDeJogo || modo.Restante != restante {
+		t.Errorf("o relógio continuou depois da vitória: restam %v, fim %v", modo.Restante, jogo.FimDeJogo)
+	}
+}
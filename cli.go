@@ -0,0 +1,537 @@
+// cli.go - Linha de comando: subcomandos, ajuda e códigos de saída
+// Cada subcomando tem suas próprias opções. Sem subcomando, o programa joga, como
+// antes (jogo -local maze.txt). Os códigos de saída são 0 (sucesso), 1 (erro ao
+// executar, como um arquivo inválido) e 2 (uso incorreto da linha de comando).
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Versão do programa; pode ser trocada na compilação com -ldflags "-X main.Versao=1.2.0"
+var Versao = "dev"
+
+// Subcomando da linha de comando
+type Subcomando struct {
+	Nome     string
+	Apelidos []string // nomes alternativos (em inglês)
+	Uso      string   // argumentos depois das opções
+	Resumo   string
+	Executar func(args []string) int
+}
+
+var subcomandos []Subcomando
+
+func init() {
+	// a lista é montada aqui porque "ajuda" a percorre
+	subcomandos = []Subcomando{
+		{"jogar", []string{"play"}, "[opções] [mapa]", "joga uma partida (o padrão, sem subcomando)", cliJogar},
+		{"validar", []string{"validate"}, "[opções] [mapas...]", "confere mapas, missões, teclas, diálogos, temas, configuração e idiomas", cliValidar},
+		{"gerar", []string{"generate"}, "[opções]", "gera um mapa aleatório (um labirinto)", cliGerar},
+		{"reproduzir", []string{"replay"}, "[opções] gravação", "reproduz uma partida gravada com jogar -gravar", cliReproduzir},
+		{"desenhar-mapa", []string{"render-map"}, "[opções] mapa", "mostra um mapa na saída padrão, com as cores do tema", cliDesenharMapa},
+		{"recordes", []string{"scores"}, "[opções]", "mostra a tabela de recordes, com as estatísticas de cada partida", cliRecordes},
+		{"versao", []string{"version"}, "", "mostra a versão do programa", cliVersao},
+		{"ajuda", []string{"help"}, "[subcomando]", "mostra esta ajuda ou a de um subcomando", cliAjuda},
+	}
+}
+
+// ErroUso é um erro na linha de comando (opção ou valor inválido): sai com código 2
+type ErroUso struct {
+	error
+}
+
+func erroUso(formato string, args ...any) error {
+	return ErroUso{fmt.Errorf(formato, args...)}
+}
+
+// Mostra o erro e retorna o código de saída correspondente
+func cliErro(err error) int {
+	fmt.Fprintln(os.Stderr, "erro:", err)
+	if errors.As(err, &ErroUso{}) {
+		return 2
+	}
+	return 1
+}
+
+// Procura um subcomando pelo nome ou apelido
+func cliSubcomando(nome string) (Subcomando, bool) {
+	for _, s := range subcomandos {
+		if s.Nome == nome {
+			return s, true
+		}
+		for _, a := range s.Apelidos {
+			if a == nome {
+				return s, true
+			}
+		}
+	}
+	return Subcomando{}, false
+}
+
+// Executa a linha de comando e retorna o código de saída
+func cliExecutar(args []string) int {
+	if len(args) > 0 {
+		if s, ok := cliSubcomando(args[0]); ok {
+			return s.Executar(args[1:])
+		}
+		// sem subcomando, o primeiro argumento é uma opção de jogar ou o arquivo do mapa
+		if !strings.HasPrefix(args[0], "-") {
+			if _, err := os.Stat(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "erro: subcomando desconhecido: %q\n\n", args[0])
+				cliUso(os.Stderr)
+				return 2
+			}
+		}
+	}
+	return cliJogar(args)
+}
+
+// Ajuda geral, com a lista de subcomandos
+func cliUso(w io.Writer) {
+	fmt.Fprintln(w, "uso: jogo [subcomando] [opções] [argumentos]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "subcomandos:")
+	for _, s := range subcomandos {
+		nome := s.Nome
+		if len(s.Apelidos) > 0 {
+			nome += " (" + strings.Join(s.Apelidos, ", ") + ")"
+		}
+		fmt.Fprintf(w, "  %-28s %s\n", nome, s.Resumo)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "\"jogo ajuda <subcomando>\" mostra as opções de cada um.")
+}
+
+// Cria o conjunto de opções de um subcomando, com a ajuda no formato do programa
+func cliOpcoes(nome string) *flag.FlagSet {
+	s, _ := cliSubcomando(nome)
+	fs := flag.NewFlagSet(nome, flag.ContinueOnError)
+	fs.Usage = func() {
+		w := fs.Output()
+		fmt.Fprintf(w, "uso: jogo %s %s\n\n%s\n", s.Nome, s.Uso, s.Resumo)
+		var opcoes int
+		fs.VisitAll(func(*flag.Flag) { opcoes++ })
+		if opcoes > 0 {
+			fmt.Fprintln(w, "\nopções:")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+// Lê as opções; retorna o código de saída quando o programa deve parar (-h ou erro)
+func cliLerOpcoes(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0, false
+		}
+		return 2, false
+	}
+	return 0, true
+}
+
+func cliAjuda(args []string) int {
+	if len(args) == 0 {
+		cliUso(os.Stdout)
+		return 0
+	}
+	s, ok := cliSubcomando(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "erro: subcomando desconhecido: %q\n", args[0])
+		return 2
+	}
+	return s.Executar([]string{"-h"})
+}
+
+func cliJogar(args []string) int {
+	fs := cliOpcoes("jogar")
+	o := opcoesRegistrar(fs)
+	fs.StringVar(&o.Host, "host", "", "hospeda uma partida cooperativa no endereço informado (ex: 127.0.0.1:4000)")
+	fs.StringVar(&o.Conectar, "conectar", "", "entra na partida hospedada no endereço informado")
+	fs.BoolVar(&o.Local, "local", false, "dois jogadores no mesmo teclado (WASD+E e setas+Enter)")
+	fs.BoolVar(&o.QuebraCabeca, "quebra-cabeca", false, "modo quebra-cabeça: permite desfazer movimentos")
+	fs.StringVar(&o.Gravar, "gravar", "", "grava as ações dos jogadores no arquivo informado (veja reproduzir)")
+	fs.StringVar(&o.Carregar, "carregar", "", "continua uma partida salva (F5 salva em "+ArquivoSalvamento+")")
+	fs.StringVar(&o.Campanha, "campanha", "", "joga os níveis da campanha informada em sequência (ex: campanha.json)")
+	fs.StringVar(&o.Recordes, "recordes", ArquivoRecordes, "arquivo da tabela de recordes (vazio: não registra as partidas; com um perfil, o dele)")
+	fs.StringVar(&o.Conquistas, "conquistas", ArquivoConquistas, "arquivo com as definições das conquistas (vazio: sem conquistas)")
+	fs.StringVar(&o.Progresso, "progresso", ArquivoProgresso, "arquivo onde as conquistas desbloqueadas são guardadas (com um perfil, o dele)")
+	if codigo, ok := cliLerOpcoes(fs, args); !ok {
+		return codigo
+	}
+	o.Escolhidas = opcoesEscolhidas(fs)
+
+	// o mapa também pode vir como argumento, como nas versões anteriores
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		o.Mapa = fs.Arg(0)
+	default:
+		return cliErro(erroUso("argumentos demais: %s", strings.Join(fs.Args()[1:], " ")))
+	}
+	// uma gravação sem semente não pode ser repetida
+	if o.Gravar != "" && o.Semente == 0 {
+		o.Semente = time.Now().UnixNano()
+	}
+	return jogar(o)
+}
+
+func cliReproduzir(args []string) int {
+	fs := cliOpcoes("reproduzir")
+	o := opcoesRegistrar(fs)
+	velocidade := fs.Float64("velocidade", 1, "multiplica a velocidade da reprodução (2: duas vezes mais rápido)")
+	if codigo, ok := cliLerOpcoes(fs, args); !ok {
+		return codigo
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	if *velocidade <= 0 {
+		return cliErro(erroUso("velocidade deve ser positiva"))
+	}
+
+	cabecalho, acoes, err := gravacaoLer(fs.Arg(0))
+	if err != nil {
+		return cliErro(err)
+	}
+	// o que não foi escolhido na linha de comando vem do cabeçalho da gravação
+	escolhidas := opcoesEscolhidas(fs)
+	if !escolhidas["semente"] {
+		o.Semente = cabecalho.Semente
+	}
+	if !escolhidas["mapa"] && cabecalho.Mapa != "" {
+		o.Mapa = cabecalho.Mapa
+	}
+	if !escolhidas["dificuldade"] && !escolhidas["configuracao"] {
+		o.Dificuldade = cabecalho.Dificuldade
+	}
+	if !escolhidas["modo"] {
+		o.Modo = cmp.Or(cabecalho.Modo, ModoPadrao) // gravações antigas são do modo clássico
+	}
+	if !escolhidas["meta"] {
+		o.Meta = cabecalho.Meta
+	}
+	if !escolhidas["disputa"] && cabecalho.Disputa != "" {
+		o.Disputa = cabecalho.Disputa
+	}
+	// o segundo jogador e o desfazer mudam as ações possíveis: valem os da gravação
+	o.Local = cabecalho.Local
+	o.QuebraCabeca = cabecalho.QuebraCabeca
+	if c := cabecalho.Campanha; c != nil && !escolhidas["campanha"] {
+		o.Campanha = c.Arquivo
+		o.Reproduzida = c
+	}
+	o.Escolhidas = escolhidas
+	o.Roteiro = gravacaoReproduzir(acoes, *velocidade)
+	return jogar(o)
+}
+
+func cliValidar(args []string) int {
+	fs := cliOpcoes("validar")
+	arquivoTeclas := fs.String("teclas", "teclas.json", "arquivo com as ligações de teclas")
+	arquivoDialogos := fs.String("dialogos", "dialogos.json", "arquivo com as conversas do Guian (e suas traduções)")
+	arquivoConfiguracao := fs.String("configuracao", "", "arquivo de configuração (padrão: configuracao.json, se existir)")
+	arquivoCampanha := fs.String("campanha", "", "arquivo de campanha, cujos mapas também são conferidos (padrão: campanha.json, se existir)")
+	arquivoConquistas := fs.String("conquistas", ArquivoConquistas, "arquivo com as definições das conquistas (e suas traduções)")
+	if codigo, ok := cliLerOpcoes(fs, args); !ok {
+		return codigo
+	}
+	mapas := fs.Args()
+	if len(mapas) == 0 {
+		mapas = []string{"mapa.txt"}
+	}
+	if *arquivoConfiguracao == "" {
+		if _, err := os.Stat("configuracao.json"); err == nil {
+			*arquivoConfiguracao = "configuracao.json"
+		}
+	}
+	if *arquivoCampanha == "" {
+		if _, err := os.Stat("campanha.json"); err == nil {
+			*arquivoCampanha = "campanha.json"
+		}
+	}
+
+	problemas := 0
+	conferir := func(nome string, err error) {
+		if err != nil {
+			problemas++
+			fmt.Fprintf(os.Stderr, "erro  %s: %v\n", nome, err)
+			return
+		}
+		fmt.Printf("ok    %s\n", nome)
+	}
+
+	// catálogos de textos
+	faltas := i18nVerificar()
+	for _, p := range faltas {
+		conferir("idiomas", errors.New(p))
+	}
+	if len(faltas) == 0 {
+		conferir("idiomas ("+strings.Join(i18nIdiomas(), ", ")+")", nil)
+	}
+
+	_, err := teclasCarregar(*arquivoTeclas)
+	conferir(*arquivoTeclas, err)
+	if *arquivoConfiguracao != "" {
+		_, err := configuracaoCarregar(*arquivoConfiguracao, "")
+		conferir(*arquivoConfiguracao, err)
+	}
+	if *arquivoCampanha != "" {
+		c, err := campanhaCarregar(*arquivoCampanha)
+		conferir(*arquivoCampanha, err)
+		if err == nil {
+			for _, n := range c.Niveis {
+				if !slices.Contains(mapas, n.Mapa) {
+					mapas = append(mapas, n.Mapa)
+				}
+			}
+		}
+	}
+	temas, _ := filepath.Glob(filepath.Join(PastaTemas, "*.json"))
+	for _, t := range temas {
+		_, err := temaCarregar(t)
+		conferir(t, err)
+	}
+
+	// diálogos, conquistas e mapas (com as missões) em cada idioma que tem uma tradução deles
+	vistos := map[string]bool{}
+	idioma := idiomaAtual
+	for _, id := range i18nIdiomas() {
+		i18nDefinirIdioma(id)
+		if nome := i18nArquivo(*arquivoDialogos); !vistos[nome] {
+			vistos[nome] = true
+			_, err := dialogosCarregar(nome)
+			conferir(nome, err)
+		}
+		if nome := i18nArquivo(*arquivoConquistas); !vistos[nome] {
+			vistos[nome] = true
+			_, err := conquistasCarregar(nome)
+			conferir(nome, err)
+		}
+		for _, mapa := range mapas {
+			chave := mapa + " (" + missoesArquivo(mapa) + ")"
+			if !vistos[chave] {
+				vistos[chave] = true
+				conferir(chave, validarMapa(mapa))
+			}
+		}
+	}
+	i18nDefinirIdioma(idioma)
+
+	if problemas > 0 {
+		fmt.Fprintf(os.Stderr, "%d problema(s) encontrado(s)\n", problemas)
+		return 1
+	}
+	return 0
+}
+
+// Carrega o mapa e as missões dele como numa partida, sem deixar a partida rodando
+func validarMapa(nome string) error {
+	jogo := jogoNovo()
+	err := jogoCarregarMapa(nome, &jogo)
+	if fim := jogoEncerrarEntidades(&jogo); err == nil {
+		err = fim
+	}
+	return err
+}
+
+func cliGerar(args []string) int {
+	fs := cliOpcoes("gerar")
+	largura := fs.Int("largura", 79, "largura do mapa (ímpar; pares são reduzidos em um)")
+	altura := fs.Int("altura", 29, "altura do mapa (ímpar; pares são reduzidos em um)")
+	semente := fs.Int64("semente", 0, "semente do sorteio (0: uma nova a cada vez)")
+	vegetacao := fs.Float64("vegetacao", 0.08, "fração dos corredores coberta de vegetação (0 a 1)")
+	saida := fs.String("saida", "", "arquivo onde gravar o mapa (padrão: a saída padrão)")
+	if codigo, ok := cliLerOpcoes(fs, args); !ok {
+		return codigo
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	if *largura < mapaGeradoMinimo || *altura < mapaGeradoMinimo {
+		return cliErro(erroUso("o mapa deve ter pelo menos %dx%d", mapaGeradoMinimo, mapaGeradoMinimo))
+	}
+	if *vegetacao < 0 || *vegetacao > 1 {
+		return cliErro(erroUso("vegetação deve estar entre 0 e 1"))
+	}
+	if *semente == 0 {
+		*semente = time.Now().UnixNano()
+	}
+
+	linhas := mapaGerar(*largura, *altura, *vegetacao, rand.New(rand.NewSource(*semente)))
+	texto := strings.Join(linhas, "\n") + "\n"
+	if *saida == "" {
+		fmt.Print(texto)
+		return 0
+	}
+	if err := os.WriteFile(*saida, []byte(texto), 0644); err != nil {
+		return cliErro(err)
+	}
+	fmt.Fprintf(os.Stderr, "mapa gravado em %s (semente %d)\n", *saida, *semente)
+	return 0
+}
+
+func cliDesenharMapa(args []string) int {
+	fs := cliOpcoes("desenhar-mapa")
+	arquivoTema := fs.String("tema", "", "tema de cores: um nome da pasta "+PastaTemas+" ou um arquivo .json")
+	cores := fs.String("cores", "auto", "cores do terminal: auto, 8, 256, truecolor ou nenhuma (só os símbolos)")
+	acessivel := fs.Bool("acessivel", false, "modo de acessibilidade: símbolos próprios para o conteúdo das caixas")
+	if codigo, ok := cliLerOpcoes(fs, args); !ok {
+		return codigo
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	semCores := *cores == "nenhuma"
+	modo := Cores8
+	if !semCores {
+		var err error
+		if modo, err = modoCoresLer(*cores); err != nil {
+			return cliErro(ErroUso{err})
+		}
+	}
+	tema, err := temaCarregar(*arquivoTema)
+	if err != nil {
+		return cliErro(err)
+	}
+	temaAplicar(tema, modo, *acessivel)
+
+	dados, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return cliErro(err)
+	}
+	var saida strings.Builder
+	for _, linha := range strings.Split(strings.TrimRight(string(dados), "\n"), "\n") {
+		anterior := ""
+		for _, ch := range strings.TrimRight(linha, "\r") {
+			e := temaElemento(elementoDoMapa(ch))
+			// só troca as cores quando elas mudam
+			if cor := corAnsi(e.cor, e.corFundo, modo); !semCores && cor != anterior {
+				saida.WriteString(cor)
+				anterior = cor
+			}
+			saida.WriteRune(e.simbolo)
+		}
+		if !semCores {
+			saida.WriteString("\x1b[0m")
+		}
+		saida.WriteByte('\n')
+	}
+	fmt.Print(saida.String())
+	return 0
+}
+
+func cliRecordes(args []string) int {
+	fs := cliOpcoes("recordes")
+	arquivo := fs.String("arquivo", "", "arquivo da tabela de recordes (padrão: o do perfil, senão "+ArquivoRecordes+")")
+	perfil := fs.String("perfil", "", "mostra os recordes deste perfil (padrão: o último escolhido)")
+	n := fs.Int("n", RecordesNaTela, "quantas partidas mostrar (0: todas)")
+	mapa := fs.String("mapa", "", "mostra só as partidas deste mapa (ex: maze.txt)")
+	modo := fs.String("modo", "", "mostra só as partidas deste modo de jogo: "+strings.Join(ordemModos, ", "))
+	comoJSON := fs.Bool("json", false, "escreve as partidas em JSON, com todas as estatísticas")
+	if codigo, ok := cliLerOpcoes(fs, args); !ok {
+		return codigo
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+	if *n < 0 {
+		return cliErro(erroUso("-n não pode ser negativo"))
+	}
+	if *modo != "" {
+		if _, err := modoNovo(*modo); err != nil {
+			return cliErro(ErroUso{err})
+		}
+	}
+	if *arquivo == "" {
+		*arquivo = ArquivoRecordes
+		p, err := perfilAbrir(cmp.Or(*perfil, perfilAtual()))
+		switch {
+		case err == nil:
+			*arquivo = p.Recordes()
+		case *perfil != "":
+			return cliErro(err)
+		}
+	}
+
+	lista, err := recordesCarregar(*arquivo)
+	if err != nil {
+		return cliErro(err)
+	}
+	if *mapa != "" {
+		lista = slices.DeleteFunc(lista, func(e Estatisticas) bool {
+			return filepath.Base(e.Mapa) != filepath.Base(*mapa)
+		})
+	}
+	if *modo != "" {
+		lista = slices.DeleteFunc(lista, func(e Estatisticas) bool { return e.Modo != *modo })
+	}
+	if *n > 0 && len(lista) > *n {
+		lista = lista[:*n]
+	}
+
+	if *comoJSON {
+		dados, err := json.MarshalIndent(lista, "", "  ")
+		if err != nil {
+			return cliErro(err)
+		}
+		fmt.Println(string(dados))
+		return 0
+	}
+	if len(lista) == 0 {
+		fmt.Println("nenhuma partida registrada em", *arquivo)
+		return 0
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "#\tresultado\tpontos\ttempo\ttesouros\tcaixas\tarmadilhas\tpassos\troubados\trecuperados\tmapa\tdificuldade\tmodo\tsemente\tdata\t")
+	for i, e := range lista {
+		resultado := "derrota"
+		if e.Vitoria {
+			resultado = "vitória"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s\t%s\t%s\t%d\t%s\t\n", i+1, resultado, e.Pontos,
+			recordesTempo(e.Tempo), e.Tesouros, e.CaixasAbertas, e.Armadilhas, e.Passos, e.Roubados, e.Recuperados,
+			filepath.Base(e.Mapa), e.Dificuldade, e.Modo, e.Semente, e.Data.Format("2006-01-02 15:04"))
+	}
+	w.Flush()
+	return 0
+}
+
+func cliVersao(args []string) int {
+	fs := cliOpcoes("versao")
+	if codigo, ok := cliLerOpcoes(fs, args); !ok {
+		return codigo
+	}
+	versao := Versao
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" && len(s.Value) >= 7 {
+				versao += " (" + s.Value[:7] + ")"
+			}
+		}
+	}
+	fmt.Printf("jogo %s\n", versao)
+	fmt.Printf("%s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Printf("protocolo de rede %d, salvamento %d\n", VersaoProtocolo, VersaoSalvamento)
+	return 0
+}
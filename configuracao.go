@@ -0,0 +1,198 @@
+// configuracao.go - Dificuldades e arquivo de configuração da partida
+// Os tempos e quantidades que definem o quão difícil é uma partida ficam numa
+// Configuracao. Cada dificuldade (fácil, normal, difícil) é uma configuração pronta;
+// um arquivo JSON pode escolher uma delas e mudar só os valores que quiser.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Duracao é um time.Duration escrito no JSON como texto (ex: "30s", "500ms")
+type Duracao time.Duration
+
+func (d Duracao) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duracao) UnmarshalJSON(dados []byte) error {
+	var texto string
+	if err := json.Unmarshal(dados, &texto); err != nil {
+		return fmt.Errorf("duração deve ser um texto como \"30s\" ou \"500ms\"")
+	}
+	v, err := time.ParseDuration(texto)
+	if err != nil {
+		return err
+	}
+	*d = Duracao(v)
+	return nil
+}
+
+// Configuracao reúne os valores ajustáveis da partida
+type Configuracao struct {
+	Dificuldade       string  `json:"dificuldade,omitempty"` // dificuldade de onde partem os valores
+	MonstroSurge      Duracao `json:"monstro_surge"`         // espera até o monstro aparecer (e reaparecer)
+	MonstroVelocidade Duracao `json:"monstro_velocidade"`    // intervalo entre os passos do monstro
+	CaixaMove         Duracao `json:"caixa_move"`            // intervalo entre as mudanças de lugar das caixas
+	Caixas            int     `json:"caixas"`                // caixas espalhadas pelo mapa
+	Meta              int     `json:"meta"`                  // tesouros necessários para vencer
+	NPCIntervalo      Duracao `json:"npc_intervalo"`         // intervalo entre os passos do Guian
+	Dicas             [3]int  `json:"dicas"`                 // distâncias máximas para "muito quente", "quente" e "morno"
+	Tempo             Duracao `json:"tempo"`                 // tempo inicial do relógio no modo contra o relógio
+	TempoCaixa        Duracao `json:"tempo_caixa"`           // tempo que cada caixa aberta acrescenta ao relógio
+	Onda              Duracao `json:"onda"`                  // duração de cada onda de monstros no modo sobrevivência
+}
+
+// Dificuldades prontas; a normal tem os valores originais do jogo
+var dificuldades = map[string]Configuracao{
+	"facil": {
+		MonstroSurge:      Duracao(60 * time.Second),
+		MonstroVelocidade: Duracao(3 * time.Second),
+		CaixaMove:         Duracao(40 * time.Second),
+		Caixas:            12,
+		Meta:              3,
+		NPCIntervalo:      Duracao(400 * time.Millisecond),
+		Dicas:             [3]int{2, 5, 9},
+		Tempo:             Duracao(120 * time.Second),
+		TempoCaixa:        Duracao(15 * time.Second),
+		Onda:              Duracao(45 * time.Second),
+	},
+	"normal": {
+		MonstroSurge:      Duracao(30 * time.Second),
+		MonstroVelocidade: Duracao(2 * time.Second),
+		CaixaMove:         Duracao(20 * time.Second),
+		Caixas:            10,
+		Meta:              4,
+		NPCIntervalo:      Duracao(500 * time.Millisecond),
+		Dicas:             [3]int{1, 3, 6},
+		Tempo:             Duracao(90 * time.Second),
+		TempoCaixa:        Duracao(10 * time.Second),
+		Onda:              Duracao(30 * time.Second),
+	},
+	"dificil": {
+		MonstroSurge:      Duracao(15 * time.Second),
+		MonstroVelocidade: Duracao(1 * time.Second),
+		CaixaMove:         Duracao(10 * time.Second),
+		Caixas:            12,
+		Meta:              5,
+		NPCIntervalo:      Duracao(700 * time.Millisecond),
+		Dicas:             [3]int{1, 2, 4},
+		Tempo:             Duracao(60 * time.Second),
+		TempoCaixa:        Duracao(8 * time.Second),
+		Onda:              Duracao(20 * time.Second),
+	},
+}
+
+// Ordem em que as dificuldades aparecem no menu
+var ordemDificuldades = []string{"facil", "normal", "dificil"}
+
+const DificuldadePadrao = "normal"
+
+// Retorna a configuração de uma dificuldade pronta
+func configuracaoDificuldade(nome string) (*Configuracao, error) {
+	c, ok := dificuldades[nome]
+	if !ok {
+		return nil, fmt.Errorf("dificuldade desconhecida: %q (use %s)", nome, strings.Join(ordemDificuldades, ", "))
+	}
+	c.Dificuldade = nome
+	return &c, nil
+}
+
+// Configuração usada quando nenhuma é escolhida
+func configuracaoPadrao() *Configuracao {
+	c, _ := configuracaoDificuldade(DificuldadePadrao)
+	return c
+}
+
+// Carrega a configuração: parte da dificuldade (a informada, senão a do arquivo, senão a
+// normal) e aplica por cima os valores presentes no arquivo, se houver um
+func configuracaoCarregar(arquivo, dificuldade string) (*Configuracao, error) {
+	var dados []byte
+	if arquivo != "" {
+		var err error
+		dados, err = os.ReadFile(arquivo)
+		if err != nil {
+			return nil, err
+		}
+		if dificuldade == "" {
+			var escolha struct {
+				Dificuldade string `json:"dificuldade"`
+			}
+			if err := json.Unmarshal(dados, &escolha); err != nil {
+				return nil, fmt.Errorf("%s: %w", arquivo, err)
+			}
+			dificuldade = escolha.Dificuldade
+		}
+	}
+	if dificuldade == "" {
+		dificuldade = DificuldadePadrao
+	}
+
+	c, err := configuracaoDificuldade(dificuldade)
+	if err != nil {
+		return nil, err
+	}
+	if dados != nil {
+		leitor := json.NewDecoder(bytes.NewReader(dados))
+		leitor.DisallowUnknownFields()
+		if err := leitor.Decode(c); err != nil {
+			return nil, fmt.Errorf("%s: %w", arquivo, err)
+		}
+		// a dificuldade informada vale mais que a do arquivo
+		c.Dificuldade = dificuldade
+	}
+	if err := c.Validar(); err != nil {
+		if arquivo != "" {
+			return nil, fmt.Errorf("%s: %w", arquivo, err)
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// Confere se os valores fazem sentido para uma partida
+func (c *Configuracao) Validar() error {
+	tempos := []struct {
+		nome  string
+		valor Duracao
+		min   time.Duration
+	}{
+		{"monstro_surge", c.MonstroSurge, 0},
+		{"monstro_velocidade", c.MonstroVelocidade, 100 * time.Millisecond},
+		{"caixa_move", c.CaixaMove, time.Second},
+		{"npc_intervalo", c.NPCIntervalo, 50 * time.Millisecond},
+		{"tempo", c.Tempo, 10 * time.Second},
+		{"tempo_caixa", c.TempoCaixa, 0},
+		{"onda", c.Onda, 5 * time.Second},
+	}
+	for _, t := range tempos {
+		if time.Duration(t.valor) < t.min {
+			return fmt.Errorf("%s deve ser de pelo menos %v", t.nome, t.min)
+		}
+	}
+	if c.Caixas < 1 {
+		return fmt.Errorf("caixas deve ser pelo menos 1")
+	}
+	if c.Meta < 1 {
+		return fmt.Errorf("meta deve ser pelo menos 1")
+	}
+	if c.Meta > c.Caixas {
+		return fmt.Errorf("meta (%d) maior que o número de caixas (%d)", c.Meta, c.Caixas)
+	}
+	if c.Dicas[0] < 0 || !slices.IsSorted(c.Dicas[:]) {
+		return fmt.Errorf("dicas devem ser distâncias crescentes e não negativas")
+	}
+	return nil
+}
+
+// Próxima dificuldade pronta, na ordem do menu
+func dificuldadeSeguinte(nome string) string {
+	i := slices.Index(ordemDificuldades, nome)
+	return ordemDificuldades[(i+1)%len(ordemDificuldades)]
+}
@@ -0,0 +1,283 @@
+// conquistas.go - Conquistas: metas de longo prazo, conferidas a cada evento da partida
+// As conquistas são definidas num arquivo JSON (com versões traduzidas, como os diálogos).
+// Cada uma diz em quais eventos é conferida e que condições os valores da partida
+// precisam cumprir naquele momento, por exemplo:
+//
+//	{
+//	  "id": "pes_leves",
+//	  "nome": "Pés leves",
+//	  "descricao": "Abra todas as caixas seguras sem cair numa armadilha",
+//	  "eventos": ["tesouro_encontrado", "caixa_vazia"],
+//	  "maximo": {"armadilhas": 0, "caixas_seguras_fechadas": 0}
+//	}
+//
+// Os valores são as estatísticas da partida (recordes.go). Com "acumulado", a conquista
+// é conferida no fim de cada partida contra os totais de todas as partidas já jogadas.
+// As conquistas desbloqueadas e os totais ficam no arquivo de progresso do jogador.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Arquivos padrão das definições das conquistas e do progresso do jogador
+const (
+	ArquivoConquistas = "conquistas.json"
+	ArquivoProgresso  = "progresso.json"
+)
+
+// Conquista é a definição de uma conquista
+type Conquista struct {
+	ID        string         `json:"id"`
+	Nome      string         `json:"nome"`
+	Descricao string         `json:"descricao"`
+	Eventos   []string       `json:"eventos"`             // nomes dos eventos (eventos.go) em que é conferida
+	Vitoria   bool           `json:"vitoria,omitempty"`   // só vale numa partida vencida
+	Mapa      string         `json:"mapa,omitempty"`      // só vale neste mapa (o nome do arquivo)
+	Acumulado bool           `json:"acumulado,omitempty"` // confere os totais de todas as partidas
+	Minimo    map[string]int `json:"minimo,omitempty"`
+	Maximo    map[string]int `json:"maximo,omitempty"`
+}
+
+// Valores que as condições de uma partida podem usar
+var valoresPartida = []string{
+	"tesouros", "caixas_abertas", "caixas_seguras_fechadas", "armadilhas", "passos", "tesouros_roubados",
+	"tesouros_recuperados", "monstros", "monstros_derrotados", "segundos", "pontos",
+}
+
+// Valores que as condições acumuladas podem usar
+var valoresAcumulados = []string{
+	"partidas", "vitorias", "tesouros", "caixas_abertas", "armadilhas", "passos", "tesouros_roubados",
+	"tesouros_recuperados", "monstros", "monstros_derrotados", "segundos", "pontos",
+}
+
+// Lê e confere o arquivo de definições das conquistas
+func conquistasCarregar(nome string) ([]Conquista, error) {
+	dados, err := os.ReadFile(nome)
+	if err != nil {
+		return nil, err
+	}
+	var lista []Conquista
+	if err := json.Unmarshal(dados, &lista); err != nil {
+		return nil, fmt.Errorf("%s: %w", nome, err)
+	}
+	ids := map[string]bool{}
+	for _, c := range lista {
+		if c.ID == "" || c.Nome == "" {
+			return nil, fmt.Errorf("%s: conquista sem id ou sem nome", nome)
+		}
+		if ids[c.ID] {
+			return nil, fmt.Errorf("%s: conquista repetida: %q", nome, c.ID)
+		}
+		ids[c.ID] = true
+		if err := c.validar(); err != nil {
+			return nil, fmt.Errorf("%s: conquista %q: %w", nome, c.ID, err)
+		}
+	}
+	return lista, nil
+}
+
+func (c *Conquista) validar() error {
+	if len(c.Eventos) == 0 {
+		return errors.New("nenhum evento")
+	}
+	eventos := slices.Collect(maps.Values(nomesEventos))
+	for _, e := range c.Eventos {
+		if !slices.Contains(eventos, e) {
+			return fmt.Errorf("evento desconhecido: %q", e)
+		}
+	}
+	if c.Acumulado && !slices.Equal(c.Eventos, []string{nomesEventos[EventoFimDeJogo]}) {
+		return errors.New("conquistas acumuladas só são conferidas no fim_de_jogo")
+	}
+	validos := valoresPartida
+	if c.Acumulado {
+		validos = valoresAcumulados
+	}
+	for _, condicao := range []map[string]int{c.Minimo, c.Maximo} {
+		for v := range condicao {
+			if !slices.Contains(validos, v) {
+				return fmt.Errorf("valor desconhecido: %q (use %s)", v, strings.Join(validos, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// Indica se os valores cumprem os mínimos e os máximos da conquista
+func (c *Conquista) Cumprida(valores map[string]int) bool {
+	for v, n := range c.Minimo {
+		if valores[v] < n {
+			return false
+		}
+	}
+	for v, n := range c.Maximo {
+		if valores[v] > n {
+			return false
+		}
+	}
+	return true
+}
+
+// ProgressoConquistas é o que o jogador já alcançou, guardado entre as sessões
+type ProgressoConquistas struct {
+	Conquistas map[string]time.Time `json:"conquistas"` // quando cada conquista foi desbloqueada
+	Totais     map[string]int       `json:"totais"`     // soma das estatísticas de todas as partidas
+}
+
+// Lê o progresso do jogador; sem arquivo, ele ainda não conquistou nada
+func progressoCarregar(nome string) (*ProgressoConquistas, error) {
+	p := &ProgressoConquistas{Conquistas: map[string]time.Time{}, Totais: map[string]int{}}
+	dados, err := os.ReadFile(nome)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(dados, p); err != nil {
+		return nil, fmt.Errorf("%s: %w", nome, err)
+	}
+	if p.Conquistas == nil {
+		p.Conquistas = map[string]time.Time{}
+	}
+	if p.Totais == nil {
+		p.Totais = map[string]int{}
+	}
+	return p, nil
+}
+
+// Grava o progresso de uma vez (recordes.go)
+func (p *ProgressoConquistas) Gravar(nome string) error {
+	dados, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return gravarAtomico(nome, dados)
+}
+
+// Conquistas reúne as definições e o progresso do jogador durante a sessão
+type Conquistas struct {
+	Definicoes []Conquista
+	Progresso  *ProgressoConquistas
+	Arquivo    string // onde o progresso é gravado
+}
+
+// Valores da partida atual, para as condições das conquistas
+func conquistasValores(jogo *Jogo) map[string]int {
+	e := jogo.Estatisticas
+	fechadas := 0
+	for _, c := range jogo.Caixas {
+		if !c.Removida && c.Tipo != ARMADILHA {
+			fechadas++
+		}
+	}
+	return map[string]int{
+		"tesouros":                e.Tesouros,
+		"caixas_abertas":          e.CaixasAbertas,
+		"caixas_seguras_fechadas": fechadas,
+		"armadilhas":              e.Armadilhas,
+		"passos":                  e.Passos,
+		"tesouros_roubados":       e.Roubados,
+		"tesouros_recuperados":    e.Recuperados,
+		"monstros":                e.Monstros,
+		"monstros_derrotados":     e.Derrotados,
+		"segundos":                int(time.Duration(e.Tempo) / time.Second),
+		"pontos":                  jogo.Pontos,
+	}
+}
+
+// Confere as conquistas ainda bloqueadas contra o evento (chamada pelo dono do estado,
+// nas regras da partida). As desbloqueadas são gravadas na hora e anunciadas com
+// EventoConquista.
+func conquistasProcessar(jogo *Jogo, ev Evento) {
+	cs := jogo.Conquistas
+	if cs == nil || jogo.Estatisticas == nil {
+		return
+	}
+	p := cs.Progresso
+	valores := conquistasValores(jogo)
+	alterado := false
+	if ev.Tipo == EventoFimDeJogo {
+		// a partida entra nos totais antes de as conquistas acumuladas serem conferidas
+		for _, v := range valoresAcumulados {
+			p.Totais[v] += valores[v]
+		}
+		p.Totais["partidas"]++
+		if jogo.Vitoria {
+			p.Totais["vitorias"]++
+		}
+		alterado = true
+	}
+
+	mapa := filepath.Base(jogo.Estatisticas.Mapa)
+	var novas []Conquista
+	for _, c := range cs.Definicoes {
+		if _, ok := p.Conquistas[c.ID]; ok || !slices.Contains(c.Eventos, ev.Tipo.String()) {
+			continue
+		}
+		if (c.Vitoria && !jogo.Vitoria) || (c.Mapa != "" && c.Mapa != mapa) {
+			continue
+		}
+		v := valores
+		if c.Acumulado {
+			v = p.Totais
+		}
+		if c.Cumprida(v) {
+			p.Conquistas[c.ID] = time.Now()
+			novas = append(novas, c)
+			alterado = true
+		}
+	}
+
+	if alterado && cs.Arquivo != "" {
+		if err := p.Gravar(cs.Arquivo); err != nil {
+			slog.Error("falha ao gravar o progresso", "arquivo", cs.Arquivo, "erro", err)
+		}
+	}
+	for _, c := range novas {
+		slog.Info("conquista desbloqueada", "conquista", c.ID)
+		jogoPublicar(jogo, Evento{Tipo: EventoConquista, Jogador: ev.Jogador, JogadorID: ev.JogadorID, Mensagem: c.Nome})
+	}
+}
+
+// Linhas da tela de conquistas: as desbloqueadas marcadas, com a data, e a descrição
+// de cada uma, todas da mesma largura
+func conquistasLinhas(cs *Conquistas) []string {
+	if cs == nil || len(cs.Definicoes) == 0 {
+		return []string{tr("conquistas.vazio")}
+	}
+	feitas := 0
+	var linhas []string
+	for _, c := range cs.Definicoes {
+		marca, data := "[ ]", ""
+		if quando, ok := cs.Progresso.Conquistas[c.ID]; ok {
+			marca, data = "[★]", quando.Format("2006-01-02")
+			feitas++
+		}
+		linhas = append(linhas, fmt.Sprintf("%s %-24s %10s", marca, c.Nome, data), "    "+c.Descricao)
+	}
+	linhas = append([]string{tr("conquistas.resumo", feitas, len(cs.Definicoes)), ""}, linhas...)
+	return textoAlinhar(linhas)
+}
+
+// Abre a tela com as conquistas do jogador
+func jogoAbrirConquistas(jogo *Jogo) {
+	fechar := func() { jogoFecharTela(jogo) }
+	jogoAbrirTela(jogo, &Menu{
+		Titulo:   tr("conquistas.titulo"),
+		Linhas:   conquistasLinhas(jogo.Conquistas),
+		Opcoes:   []OpcaoMenu{{Texto: tr("menu.voltar"), Executar: fechar}},
+		AoFechar: fechar,
+	})
+}
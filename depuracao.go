@@ -0,0 +1,106 @@
+// depuracao.go - Sobreposição de depuração (F3)
+// Mostra por cima do mapa o que não aparece na tela: quadros por segundo, tiques do
+// relógio, goroutines em execução, as coordenadas das entidades, o alvo do monstro e
+// o conteúdo de cada caixa. As mesmas informações, ao longo do tempo, vão para o registro.
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Depuracao guarda o estado da sobreposição e as medidas que só ela usa
+type Depuracao struct {
+	ativa  atomic.Bool
+	tiques atomic.Int64 // tiques do relógio da partida (um a cada 100ms de jogo)
+
+	// usados só pelo renderizador
+	quadros int       // desenhos desde a última medida
+	medida  time.Time // início da medida atual
+	fps     float64
+}
+
+// Liga ou desliga a sobreposição
+func (d *Depuracao) Alternar() {
+	d.ativa.Store(!d.ativa.Load())
+}
+
+func (d *Depuracao) Ativa() bool {
+	return d.ativa.Load()
+}
+
+// Conta um tique do relógio da partida
+func (d *Depuracao) Tique() {
+	d.tiques.Add(1)
+}
+
+// Conta um quadro desenhado; os quadros por segundo são medidos a cada segundo
+func (d *Depuracao) Quadro(agora time.Time) {
+	d.quadros++
+	if decorrido := agora.Sub(d.medida); decorrido >= time.Second {
+		if !d.medida.IsZero() {
+			d.fps = float64(d.quadros) / decorrido.Seconds()
+		}
+		d.quadros = 0
+		d.medida = agora
+	}
+}
+
+// Linhas da sobreposição (chamada pelo dono do estado)
+func depuracaoLinhas(jogo *Jogo) []string {
+	d := jogo.Depuracao
+	linhas := []string{
+		"DEPURAÇÃO (F3)",
+		fmt.Sprintf("fps %.1f  tiques %d  goroutines %d", d.fps, d.tiques.Load(), runtime.NumGoroutine()),
+		fmt.Sprintf("entidades %d  com IA %d", len(jogo.Ocupantes.Posicoes), len(jogo.Ocupantes.IAs)),
+	}
+	for _, j := range jogo.Jogadores {
+		linhas = append(linhas, fmt.Sprintf("jogador %d: (%d, %d) tesouros %d vida %d", j.ID+1, j.X, j.Y, j.Tesouros, j.Vida.Pontos))
+	}
+
+	if m := jogo.Monstro; jogo.MonstroAtivo && m != nil {
+		alvo := "nenhum"
+		if m.Alvo != nil {
+			alvo = fmt.Sprintf("jogador %d (%d, %d)", m.Alvo.ID+1, m.Alvo.X, m.Alvo.Y)
+		}
+		linhas = append(linhas, fmt.Sprintf("monstro: (%d, %d) vida %d alvo %s", m.X, m.Y, m.Vida.Pontos, alvo))
+	} else if len(jogo.Mapa) > 0 {
+		linhas = append(linhas, fmt.Sprintf("monstro: surge em %s", time.Until(jogo.MonstroSpawn).Round(time.Second)))
+	}
+	if npc := jogo.Guian; npc != nil {
+		linhas = append(linhas, fmt.Sprintf("Guian: (%d, %d)", npc.X, npc.Y))
+	}
+
+	for _, c := range jogo.Caixas {
+		if !c.Removida {
+			linhas = append(linhas, fmt.Sprintf("caixa (%d, %d) %s", c.X, c.Y, c.Tipo.Nome()))
+		}
+	}
+	return linhas
+}
+
+// Desenha a sobreposição no canto superior direito, cortando o que não couber na tela
+func interfaceDesenharDepuracao(jogo *Jogo) {
+	linhas := depuracaoLinhas(jogo)
+	larguraTela, alturaTela := termbox.Size()
+	largura := 0
+	for _, l := range linhas {
+		largura = max(largura, len([]rune(l)))
+	}
+	x0 := max(0, larguraTela-largura-2)
+	for i, l := range linhas {
+		if i >= alturaTela {
+			break
+		}
+		cor := CorTexto
+		if i == 0 {
+			cor = CorTitulo
+		}
+		texto := fmt.Sprintf(" %-*s ", largura, l)
+		interfaceEscrever(x0, i, texto, cor, CorFundoSelecao)
+	}
+}
@@ -0,0 +1,262 @@
+// dialogo.go - Conversas com o NPC Guian
+// As falas são lidas de um arquivo JSON (por padrão "dialogos.json"), por exemplo:
+//
+//	{
+//	  "inicio": "saudacao",
+//	  "falas": {
+//	    "saudacao": {
+//	      "texto": ["Olá, aventureiro!"],
+//	      "opcoes": [
+//	        {"texto": "Alguma dica?", "efeito": {"tipo": "dica"}, "proxima": "saudacao"},
+//	        {"texto": "Me dá a lanterna?", "requer": {"sem_item": "lanterna"},
+//	         "efeito": {"tipo": "trocar", "recebe": "lanterna"}},
+//	        {"texto": "Tchau."}
+//	      ]
+//	    }
+//	  }
+//	}
+//
+// Uma opção sem "proxima" encerra a conversa. Opções com "requer" só aparecem quando
+// o jogador cumpre as condições (tesouros encontrados, itens que carrega ou não).
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Efeitos que uma opção pode disparar
+const (
+	EfeitoDica         = "dica"          // diz se o tesouro mais próximo está perto
+	EfeitoRevelarCaixa = "revelar_caixa" // mostra o conteúdo da caixa fechada mais próxima
+	EfeitoTrocar       = "trocar"        // entrega um item ao Guian e/ou recebe outro
+)
+
+// Requisitos para uma opção aparecer
+type Requisitos struct {
+	Tesouros int    `json:"tesouros,omitempty"` // tesouros encontrados, no mínimo
+	Item     string `json:"item,omitempty"`     // item que o jogador precisa carregar
+	SemItem  string `json:"sem_item,omitempty"` // item que o jogador não pode carregar
+}
+
+// Efeito disparado ao escolher uma opção
+type Efeito struct {
+	Tipo    string `json:"tipo"`
+	Entrega string `json:"entrega,omitempty"` // item entregue ao Guian (trocar)
+	Recebe  string `json:"recebe,omitempty"`  // item recebido do Guian (trocar)
+}
+
+// OpcaoDialogo é uma resposta que o jogador pode escolher
+type OpcaoDialogo struct {
+	Texto   string      `json:"texto"`
+	Requer  *Requisitos `json:"requer,omitempty"`
+	Efeito  *Efeito     `json:"efeito,omitempty"`
+	Proxima string      `json:"proxima,omitempty"` // fala seguinte; vazio encerra a conversa
+}
+
+// Fala é um trecho da conversa com as respostas possíveis
+type Fala struct {
+	Texto  []string       `json:"texto"`
+	Opcoes []OpcaoDialogo `json:"opcoes"`
+}
+
+// Dialogos é a árvore de conversas do Guian
+type Dialogos struct {
+	Inicio string           `json:"inicio"`
+	Falas  map[string]*Fala `json:"falas"`
+}
+
+// Conversa usada quando não há arquivo de diálogos
+func dialogosPadrao() *Dialogos {
+	return &Dialogos{
+		Inicio: "inicio",
+		Falas: map[string]*Fala{
+			"inicio": {
+				Texto: []string{tr("dialogo.padrao")},
+				Opcoes: []OpcaoDialogo{
+					{Texto: tr("dialogo.padrao.perto"), Efeito: &Efeito{Tipo: EfeitoDica}, Proxima: "inicio"},
+					{Texto: tr("dialogo.padrao.tchau")},
+				},
+			},
+		},
+	}
+}
+
+// Lê as conversas do arquivo, ou usa a conversa padrão se ele não existir
+func dialogosCarregar(nome string) (*Dialogos, error) {
+	dados, err := os.ReadFile(nome)
+	if errors.Is(err, os.ErrNotExist) {
+		return dialogosPadrao(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var d Dialogos
+	if err := json.Unmarshal(dados, &d); err != nil {
+		return nil, fmt.Errorf("%s: %w", nome, err)
+	}
+	if err := d.Validar(); err != nil {
+		return nil, fmt.Errorf("%s: %w", nome, err)
+	}
+	return &d, nil
+}
+
+// Verifica se todas as falas referenciadas existem e se os efeitos são conhecidos
+func (d *Dialogos) Validar() error {
+	if d.Falas[d.Inicio] == nil {
+		return fmt.Errorf("fala inicial %q não existe", d.Inicio)
+	}
+	for nome, f := range d.Falas {
+		if f == nil || len(f.Opcoes) == 0 {
+			return fmt.Errorf("fala %q não tem opções", nome)
+		}
+		for _, o := range f.Opcoes {
+			if o.Proxima != "" && d.Falas[o.Proxima] == nil {
+				return fmt.Errorf("fala %q: opção %q leva à fala %q, que não existe", nome, o.Texto, o.Proxima)
+			}
+			if o.Efeito == nil {
+				continue
+			}
+			switch o.Efeito.Tipo {
+			case EfeitoDica, EfeitoRevelarCaixa:
+			case EfeitoTrocar:
+				if o.Efeito.Entrega == "" && o.Efeito.Recebe == "" {
+					return fmt.Errorf("fala %q: opção %q troca nada por nada", nome, o.Texto)
+				}
+			default:
+				return fmt.Errorf("fala %q: efeito desconhecido: %q", nome, o.Efeito.Tipo)
+			}
+		}
+	}
+	return nil
+}
+
+// Indica se o jogador cumpre os requisitos
+func (r *Requisitos) Cumpre(jogo *Jogo, j *Jogador) bool {
+	if r == nil {
+		return true
+	}
+	if jogadorTesourosEncontrados(jogo, j) < r.Tesouros {
+		return false
+	}
+	if r.Item != "" && !j.TemItem(r.Item) {
+		return false
+	}
+	if r.SemItem != "" && j.TemItem(r.SemItem) {
+		return false
+	}
+	return true
+}
+
+// Tesouros que contam para o jogador: os dele na disputa competitiva, os do time na cooperativa
+func jogadorTesourosEncontrados(jogo *Jogo, j *Jogador) int {
+	if jogo.Disputa == DisputaCompetitiva {
+		return j.Tesouros
+	}
+	return jogo.Tesouros
+}
+
+// Aplica o efeito e retorna a resposta do Guian
+func (e *Efeito) Aplicar(jogo *Jogo, j *Jogador) string {
+	switch e.Tipo {
+	case EfeitoDica:
+		return tr("dialogo.dica", getDica(jogo, j))
+
+	case EfeitoRevelarCaixa:
+		caixa := encontraCaixaFechadaMaisProxima(jogo, j)
+		if caixa == nil {
+			return tr("dialogo.sem_caixas")
+		}
+		caixa.Revelar()
+		return tr("dialogo.revelar", caixa.X, caixa.Y, caixa.Tipo)
+
+	case EfeitoTrocar:
+		if e.Entrega != "" && !j.RemoverItem(e.Entrega) {
+			return tr("dialogo.sem_item", trItem(e.Entrega))
+		}
+		defer jogoPublicar(jogo, Evento{Tipo: EventoItem, Jogador: j})
+		if e.Recebe == "" {
+			return tr("dialogo.entregou", trItem(e.Entrega))
+		}
+		j.Itens = append(j.Itens, e.Recebe)
+		return tr("dialogo.recebeu", trItem(e.Recebe))
+	}
+	return ""
+}
+
+// TelaDialogo é o painel da conversa entre um jogador e o Guian
+type TelaDialogo struct {
+	Dialogos *Dialogos
+	Jogador  *Jogador
+	Fala     *Fala
+	Resposta string // resultado do último efeito, exibido acima da fala
+	menu     *Menu  // a fala atual com as opções disponíveis
+}
+
+// Abre a conversa com o Guian, congelando o jogo enquanto ela durar
+func jogoConversar(jogo *Jogo, j *Jogador) {
+	t := &TelaDialogo{Dialogos: jogo.Dialogos, Jogador: j}
+	t.irPara(jogo, jogo.Dialogos.Inicio)
+	jogoPausar(jogo)
+	jogoAbrirTela(jogo, t)
+}
+
+// Monta o menu da fala indicada, mostrando só as opções disponíveis
+func (t *TelaDialogo) irPara(jogo *Jogo, nome string) {
+	t.Fala = t.Dialogos.Falas[nome]
+	t.menu = &Menu{Titulo: "Guian", AoFechar: func() { t.encerrar(jogo) }}
+
+	if t.Resposta != "" {
+		t.menu.Linhas = append(t.menu.Linhas, t.Resposta, "")
+	}
+	t.menu.Linhas = append(t.menu.Linhas, t.Fala.Texto...)
+	if len(t.Jogador.Itens) > 0 {
+		t.menu.Linhas = append(t.menu.Linhas, "", tr("dialogo.itens", trItens(t.Jogador.Itens)))
+	}
+
+	for _, o := range t.Fala.Opcoes {
+		if !o.Requer.Cumpre(jogo, t.Jogador) {
+			continue
+		}
+		o := o
+		t.menu.Opcoes = append(t.menu.Opcoes, OpcaoMenu{Texto: o.Texto, Executar: func() { t.escolher(jogo, o) }})
+	}
+	// garante uma saída mesmo quando nenhuma opção está disponível
+	if len(t.menu.Opcoes) == 0 {
+		t.menu.Opcoes = append(t.menu.Opcoes, OpcaoMenu{Texto: "...", Executar: func() { t.encerrar(jogo) }})
+	}
+}
+
+// Executa a opção escolhida e segue para a próxima fala
+func (t *TelaDialogo) escolher(jogo *Jogo, o OpcaoDialogo) {
+	t.Resposta = ""
+	if o.Efeito != nil {
+		t.Resposta = o.Efeito.Aplicar(jogo, t.Jogador)
+	}
+	if o.Proxima == "" {
+		t.encerrar(jogo)
+		if t.Resposta != "" {
+			jogo.SetMessage(tr("dialogo.guian", t.Resposta), 5*time.Second)
+		}
+		return
+	}
+	t.irPara(jogo, o.Proxima)
+}
+
+// Fecha o painel e retoma o jogo
+func (t *TelaDialogo) encerrar(jogo *Jogo) {
+	jogoFecharTela(jogo)
+	jogoRetomar(jogo)
+}
+
+func (t *TelaDialogo) Desenhar(jogo *Jogo) {
+	interfaceDesenharMenu(t.menu)
+}
+
+func (t *TelaDialogo) Tecla(jogo *Jogo, atalho AtalhoTecla) {
+	t.menu.Tecla(jogo, atalho)
+}
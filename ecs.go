@@ -0,0 +1,248 @@
+// ecs.go - Entidades que ocupam o mapa (entidade-componente-sistema)
+// O mapa (jogo.Mapa) guarda só o terreno: paredes, vegetação e espaços vazios. Jogadores,
+// Guian, monstro, caixas, portas, alavancas e portais são entidades: um número com
+// componentes (posição, aparência, bloqueio, IA, inventário, vida, interação, gatilho).
+// Os sistemas abaixo percorrem os componentes, sem saber de que tipo é cada entidade,
+// para colidir, desenhar, agir e sofrer dano; um novo tipo de entidade só precisa
+// escolher seus componentes. Como o resto do estado do mundo, os
+// componentes pertencem ao dono do estado (mundo.go).
+package main
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// IDEntidade identifica uma entidade da partida
+type IDEntidade int
+
+// Posicao é a célula ocupada pela entidade
+type Posicao struct {
+	X, Y int
+}
+
+// Desenhavel é como a entidade aparece por cima do terreno
+type Desenhavel struct {
+	Elemento Elemento
+	Camada   int      // entidades de camada maior são desenhadas por cima
+	Alvo     Entidade // como as animações (animacao.go) identificam a entidade
+}
+
+// Camadas de desenho das entidades
+const (
+	CamadaObjeto = iota // portas e alavancas
+	CamadaCaixa
+	CamadaGuian
+	CamadaJogador
+	CamadaMonstro
+)
+
+// Bloqueante marca entidades que impedem outras de ocupar a mesma célula
+type Bloqueante struct{}
+
+// IA é o comportamento de uma entidade controlada pelo jogo. A cada intervalo, uma
+// goroutine da partida chama Decidir com o retrato do mundo e a posição da entidade
+// nele, e manda a intenção retornada (se houver) ao dono do estado.
+type IA struct {
+	Nome      string // nome da goroutine no supervisor e no registro
+	Intervalo time.Duration
+	Decidir   func(r *Retrato, eu Posicao) Intencao
+	fim       chan struct{} // fechado quando a entidade sai do mapa
+}
+
+// Interativo é uma entidade com que o jogador pode interagir (interacao.go)
+type Interativo struct {
+	Interagir func(jogo *Jogo, j *Jogador)
+}
+
+// Gatilho é uma entidade que reage quando um jogador entra na sua célula
+type Gatilho struct {
+	AoEntrar func(jogo *Jogo, j *Jogador)
+}
+
+// Inventario são os tesouros e itens carregados pela entidade
+type Inventario struct {
+	Tesouros int
+	Itens    []string
+}
+
+// Indica se a entidade carrega o item
+func (inv *Inventario) TemItem(item string) bool {
+	return slices.Contains(inv.Itens, item)
+}
+
+// Remove uma unidade do item do inventário; retorna false se a entidade não o tinha
+func (inv *Inventario) RemoverItem(item string) bool {
+	if i := slices.Index(inv.Itens, item); i >= 0 {
+		inv.Itens = slices.Delete(inv.Itens, i, i+1)
+		return true
+	}
+	return false
+}
+
+// Vida são os pontos de vida da entidade; ela é derrotada quando chegam a zero
+type Vida struct {
+	Pontos, Maximo int
+}
+
+func vidaNova(pontos int) *Vida {
+	return &Vida{Pontos: pontos, Maximo: pontos}
+}
+
+// Ocupantes guarda os componentes das entidades da partida, cada tipo num mapa.
+// As posições só mudam por Colocar, Mover e Remover, que mantêm a grade (espacial.go)
+// em dia.
+type Ocupantes struct {
+	proxima     IDEntidade
+	grade       map[Posicao][]IDEntidade // entidades de cada célula ocupada
+	Donos       map[IDEntidade]any       // a estrutura de cada tipo (*Caixa, *Jogador...) por trás da entidade
+	Posicoes    map[IDEntidade]*Posicao
+	Desenhos    map[IDEntidade]*Desenhavel
+	Bloqueantes map[IDEntidade]Bloqueante
+	IAs         map[IDEntidade]*IA
+	Inventarios map[IDEntidade]*Inventario
+	Vidas       map[IDEntidade]*Vida
+	Interativos map[IDEntidade]*Interativo
+	Gatilhos    map[IDEntidade]*Gatilho
+}
+
+func ocupantesNovos() *Ocupantes {
+	o := &Ocupantes{}
+	o.Limpar()
+	return o
+}
+
+// Cria uma entidade sem componentes
+func (o *Ocupantes) Criar() IDEntidade {
+	o.proxima++
+	return o.proxima
+}
+
+// Tira a entidade do mapa, descartando seus componentes e encerrando sua IA
+func (o *Ocupantes) Remover(id IDEntidade) {
+	if ia, ok := o.IAs[id]; ok && ia.fim != nil {
+		close(ia.fim)
+	}
+	if p, ok := o.Posicoes[id]; ok {
+		o.tirarDaGrade(id, *p)
+	}
+	delete(o.Donos, id)
+	delete(o.Posicoes, id)
+	delete(o.Desenhos, id)
+	delete(o.Bloqueantes, id)
+	delete(o.IAs, id)
+	delete(o.Inventarios, id)
+	delete(o.Vidas, id)
+	delete(o.Interativos, id)
+	delete(o.Gatilhos, id)
+}
+
+// Descarta todas as entidades (ao começar outra partida)
+func (o *Ocupantes) Limpar() {
+	for id := range o.IAs {
+		o.Remover(id)
+	}
+	o.grade = make(map[Posicao][]IDEntidade)
+	o.Donos = make(map[IDEntidade]any)
+	o.Posicoes = make(map[IDEntidade]*Posicao)
+	o.Desenhos = make(map[IDEntidade]*Desenhavel)
+	o.Bloqueantes = make(map[IDEntidade]Bloqueante)
+	o.IAs = make(map[IDEntidade]*IA)
+	o.Inventarios = make(map[IDEntidade]*Inventario)
+	o.Vidas = make(map[IDEntidade]*Vida)
+	o.Interativos = make(map[IDEntidade]*Interativo)
+	o.Gatilhos = make(map[IDEntidade]*Gatilho)
+}
+
+// Entidades com posição, na ordem em que foram criadas
+func (o *Ocupantes) ids() []IDEntidade {
+	ids := make([]IDEntidade, 0, len(o.Posicoes))
+	for id := range o.Posicoes {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Sistema de colisão: indica se alguma entidade bloqueante ocupa (x, y)
+func (o *Ocupantes) Bloqueado(x, y int) bool {
+	for _, id := range o.grade[Posicao{x, y}] {
+		if _, ok := o.Bloqueantes[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Sistema de desenho: desenha as entidades visíveis por cima do terreno, por camada
+func sistemaDesenhar(jogo *Jogo) {
+	o := jogo.Ocupantes
+	var ids []IDEntidade
+	for _, id := range o.ids() {
+		if _, ok := o.Desenhos[id]; ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortStableFunc(ids, func(a, b IDEntidade) int {
+		return o.Desenhos[a].Camada - o.Desenhos[b].Camada
+	})
+	for _, id := range ids {
+		p, d := o.Posicoes[id], o.Desenhos[id]
+		jogo.Animacoes.DesenharEntidade(d.Alvo, p.X, p.Y, d.Elemento)
+	}
+}
+
+// Sistema de gatilhos: dispara os gatilhos da célula em que o jogador acabou de entrar
+func sistemaGatilhos(jogo *Jogo, j *Jogador) {
+	for _, id := range jogo.Ocupantes.Em(j.X, j.Y) {
+		if g, ok := jogo.Ocupantes.Gatilhos[id]; ok {
+			g.AoEntrar(jogo, j)
+		}
+	}
+}
+
+// Sistema de IA: inicia, sob o supervisor da partida, a goroutine da IA da entidade.
+// Ela para enquanto o jogo está pausado e termina com a partida ou quando a entidade
+// sai do mapa.
+func sistemaIAIniciar(jogo *Jogo, id IDEntidade) {
+	ia := jogo.Ocupantes.IAs[id]
+	ia.fim = make(chan struct{})
+	fim := ia.fim
+	jogo.Entidades.Iniciar(ia.Nome, func(ctx context.Context) error {
+		slog.Debug("goroutine iniciada", "goroutine", ia.Nome)
+		defer slog.Debug("goroutine encerrada", "goroutine", ia.Nome)
+		for {
+			select {
+			case <-time.After(ia.Intervalo):
+			case <-fim:
+				return nil
+			case <-ctx.Done():
+				return nil
+			}
+
+			jogo.Pausa.Esperar()
+			// até o dono publicar um retrato com a entidade, ela espera o próximo intervalo
+			r := jogo.Mundo.Retrato()
+			eu, ok := r.Posicao(id)
+			if !ok {
+				continue
+			}
+			if in := ia.Decidir(r, eu); in != nil {
+				jogo.Mundo.Enviar(in, ctx.Done())
+			}
+		}
+	})
+}
+
+// Sistema de vida: tira pontos de vida da entidade; retorna true se ela foi derrotada.
+// Entidades sem vida não sofrem dano.
+func jogoFerir(jogo *Jogo, id IDEntidade, dano int) bool {
+	v, ok := jogo.Ocupantes.Vidas[id]
+	if !ok {
+		return false
+	}
+	v.Pontos = max(v.Pontos-dano, 0)
+	return v.Pontos == 0
+}
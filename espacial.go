@@ -0,0 +1,111 @@
+// espacial.go - Índice espacial das entidades do mapa
+// A grade dos Ocupantes guarda, para cada célula ocupada, as entidades que estão nela, e
+// é atualizada a cada movimento. Assim, saber o que há numa célula, quem está num raio
+// ou qual a entidade de um tipo mais próxima não exige percorrer todas as entidades da
+// partida, mesmo em mapas com centenas de caixas e monstros.
+package main
+
+import (
+	"slices"
+
+	"jogo/util"
+)
+
+// Coloca a entidade no mapa; p passa a ser o componente de posição dela
+func (o *Ocupantes) Colocar(id IDEntidade, p *Posicao) {
+	if antiga, ok := o.Posicoes[id]; ok {
+		o.tirarDaGrade(id, *antiga)
+	}
+	o.Posicoes[id] = p
+	o.grade[*p] = append(o.grade[*p], id)
+}
+
+// Muda a entidade para (x, y), atualizando a grade
+func (o *Ocupantes) Mover(id IDEntidade, x, y int) {
+	p, ok := o.Posicoes[id]
+	if !ok {
+		return
+	}
+	o.tirarDaGrade(id, *p)
+	p.X, p.Y = x, y
+	o.grade[*p] = append(o.grade[*p], id)
+}
+
+func (o *Ocupantes) tirarDaGrade(id IDEntidade, p Posicao) {
+	ids := slices.DeleteFunc(o.grade[p], func(outro IDEntidade) bool { return outro == id })
+	if len(ids) == 0 {
+		delete(o.grade, p)
+	} else {
+		o.grade[p] = ids
+	}
+}
+
+// Entidades em (x, y), na ordem em que chegaram à célula
+func (o *Ocupantes) Em(x, y int) []IDEntidade {
+	return slices.Clone(o.grade[Posicao{x, y}])
+}
+
+// Entidades a até r células de (x, y) na horizontal e na vertical (um quadrado, como
+// a distância de interação), na ordem em que foram criadas
+func (o *Ocupantes) NoRaio(x, y, r int) []IDEntidade {
+	var ids []IDEntidade
+	if lado := 2*r + 1; lado*lado > len(o.Posicoes) {
+		// há menos entidades que células no quadrado: é mais rápido olhar cada uma
+		for id, p := range o.Posicoes {
+			if util.Abs(p.X-x) <= r && util.Abs(p.Y-y) <= r {
+				ids = append(ids, id)
+			}
+		}
+	} else {
+		for cy := y - r; cy <= y+r; cy++ {
+			for cx := x - r; cx <= x+r; cx++ {
+				ids = append(ids, o.grade[Posicao{cx, cy}]...)
+			}
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Entidade do tipo T (*Caixa, *Jogador, *Monstro...) mais próxima de (x, y) pela
+// distância de Manhattan, entre as que aceitar aprova (nil aprova todas). Procura em
+// anéis cada vez maiores em volta de (x, y); se os anéis já cobriram mais células do
+// que há entidades, termina olhando cada entidade. No empate, vence a criada primeiro.
+func ocupanteMaisProximo[T any](o *Ocupantes, x, y int, aceitar func(T) bool) (T, bool) {
+	var melhor T
+	melhorID, melhorDist := IDEntidade(0), -1
+	considerar := func(id IDEntidade, dist int) {
+		t, ok := o.Donos[id].(T)
+		if !ok || aceitar != nil && !aceitar(t) {
+			return
+		}
+		if melhorDist < 0 || dist < melhorDist || dist == melhorDist && id < melhorID {
+			melhor, melhorID, melhorDist = t, id, dist
+		}
+	}
+
+	visitadas := 0
+	for d := 0; visitadas <= len(o.Posicoes); d++ {
+		for dx := -d; dx <= d; dx++ {
+			dy := d - util.Abs(dx)
+			for _, id := range o.grade[Posicao{x + dx, y + dy}] {
+				considerar(id, d)
+			}
+			visitadas++
+			if dy != 0 {
+				for _, id := range o.grade[Posicao{x + dx, y - dy}] {
+					considerar(id, d)
+				}
+				visitadas++
+			}
+		}
+		if melhorDist >= 0 {
+			return melhor, true
+		}
+	}
+
+	for id, p := range o.Posicoes {
+		considerar(id, calculaDistancia(p.X, p.Y, x, y))
+	}
+	return melhor, melhorDist >= 0
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"jogo/util"
+)
+
+const (
+	larguraTeste = 80
+	alturaTeste  = 30
+	raioTeste    = 2
+)
+
+// Ocupantes com n caixas espalhadas ao acaso, sempre nas mesmas células
+func ocupantesTeste(n int) (*Ocupantes, *rand.Rand) {
+	r := rand.New(rand.NewSource(1))
+	o := ocupantesNovos()
+	for range n {
+		id := o.Criar()
+		o.Donos[id] = &Caixa{}
+		o.Colocar(id, &Posicao{r.Intn(larguraTeste), r.Intn(alturaTeste)})
+	}
+	return o, r
+}
+
+// As mesmas consultas da grade, percorrendo todas as entidades (ordenadas pela criação)
+func emLinear(o *Ocupantes, x, y int) []IDEntidade {
+	var ids []IDEntidade
+	for id, p := range o.Posicoes {
+		if p.X == x && p.Y == y {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func noRaioLinear(o *Ocupantes, x, y, r int) []IDEntidade {
+	var ids []IDEntidade
+	for id, p := range o.Posicoes {
+		if util.Abs(p.X-x) <= r && util.Abs(p.Y-y) <= r {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func maisProximoLinear(o *Ocupantes, x, y int) (IDEntidade, bool) {
+	melhor, melhorDist := IDEntidade(0), -1
+	for id, p := range o.Posicoes {
+		dist := calculaDistancia(p.X, p.Y, x, y)
+		if melhorDist < 0 || dist < melhorDist || dist == melhorDist && id < melhor {
+			melhor, melhorDist = id, dist
+		}
+	}
+	return melhor, melhorDist >= 0
+}
+
+// Confere a grade contra as posições: cada entidade está na sua célula e só nela
+func conferirGrade(t *testing.T, o *Ocupantes) {
+	t.Helper()
+	total := 0
+	for p, ids := range o.grade {
+		if len(ids) == 0 {
+			t.Errorf("célula (%d, %d) vazia na grade", p.X, p.Y)
+		}
+		for _, id := range ids {
+			if pos, ok := o.Posicoes[id]; !ok || *pos != p {
+				t.Errorf("entidade %d na célula (%d, %d) da grade, mas sua posição é %v", id, p.X, p.Y, pos)
+			}
+		}
+		total += len(ids)
+	}
+	if total != len(o.Posicoes) {
+		t.Errorf("%d entidades na grade, %d com posição", total, len(o.Posicoes))
+	}
+	for x := range larguraTeste {
+		for y := range alturaTeste {
+			// Em devolve a ordem de chegada à célula; a busca linear, a de criação
+			em := o.Em(x, y)
+			slices.Sort(em)
+			if esperado := emLinear(o, x, y); !slices.Equal(em, esperado) {
+				t.Fatalf("Em(%d, %d) = %v, esperado %v", x, y, em, esperado)
+			}
+		}
+	}
+}
+
+func TestGradeColocarMoverRemover(t *testing.T) {
+	o, r := ocupantesTeste(300)
+	conferirGrade(t, o)
+
+	ids := o.ids()
+	for range 1000 {
+		id := ids[r.Intn(len(ids))]
+		switch r.Intn(4) {
+		case 0:
+			// colocar de novo uma entidade que já estava no mapa a tira da célula antiga
+			o.Colocar(id, &Posicao{r.Intn(larguraTeste), r.Intn(alturaTeste)})
+		case 1:
+			o.Remover(id)
+			novo := o.Criar()
+			o.Donos[novo] = &Caixa{}
+			o.Colocar(novo, &Posicao{r.Intn(larguraTeste), r.Intn(alturaTeste)})
+			ids = o.ids()
+		default:
+			o.Mover(id, r.Intn(larguraTeste), r.Intn(alturaTeste))
+		}
+	}
+	conferirGrade(t, o)
+
+	// mover uma entidade sem posição não a coloca na grade
+	o.Mover(o.Criar(), 0, 0)
+	conferirGrade(t, o)
+
+	for _, id := range o.ids() {
+		o.Remover(id)
+	}
+	if len(o.grade) != 0 {
+		t.Errorf("grade com %d células depois de remover todas as entidades", len(o.grade))
+	}
+}
+
+func TestGradeConsultas(t *testing.T) {
+	o, r := ocupantesTeste(300)
+	for range 200 {
+		x, y, raio := r.Intn(larguraTeste), r.Intn(alturaTeste), r.Intn(12)
+		if ids, esperado := o.NoRaio(x, y, raio), noRaioLinear(o, x, y, raio); !slices.Equal(ids, esperado) {
+			t.Fatalf("NoRaio(%d, %d, %d) = %v, esperado %v", x, y, raio, ids, esperado)
+		}
+		caixa, ok := ocupanteMaisProximo[*Caixa](o, x, y, nil)
+		id, esperado := maisProximoLinear(o, x, y)
+		if ok != esperado || caixa != o.Donos[id] {
+			t.Fatalf("ocupanteMaisProximo(%d, %d) diferente da busca linear (entidade %d)", x, y, id)
+		}
+	}
+
+	// longe do mapa, a busca em anéis termina olhando cada entidade
+	if _, ok := ocupanteMaisProximo[*Caixa](o, 1000, 1000, nil); !ok {
+		t.Error("nenhuma caixa encontrada longe do mapa")
+	}
+	if _, ok := ocupanteMaisProximo[*Monstro](o, 0, 0, nil); ok {
+		t.Error("monstro encontrado num mapa só de caixas")
+	}
+}
+
+func BenchmarkEm(b *testing.B) {
+	o, r := ocupantesTeste(500)
+	for b.Loop() {
+		o.Em(r.Intn(larguraTeste), r.Intn(alturaTeste))
+	}
+}
+
+func BenchmarkEmLinear(b *testing.B) {
+	o, r := ocupantesTeste(500)
+	for b.Loop() {
+		emLinear(o, r.Intn(larguraTeste), r.Intn(alturaTeste))
+	}
+}
+
+func BenchmarkNoRaio(b *testing.B) {
+	o, r := ocupantesTeste(500)
+	for b.Loop() {
+		o.NoRaio(r.Intn(larguraTeste), r.Intn(alturaTeste), raioTeste)
+	}
+}
+
+func BenchmarkNoRaioLinear(b *testing.B) {
+	o, r := ocupantesTeste(500)
+	for b.Loop() {
+		noRaioLinear(o, r.Intn(larguraTeste), r.Intn(alturaTeste), raioTeste)
+	}
+}
+
+func BenchmarkMaisProximo(b *testing.B) {
+	o, r := ocupantesTeste(500)
+	for b.Loop() {
+		ocupanteMaisProximo[*Caixa](o, r.Intn(larguraTeste), r.Intn(alturaTeste), nil)
+	}
+}
+
+func BenchmarkMaisProximoLinear(b *testing.B) {
+	o, r := ocupantesTeste(500)
+	for b.Loop() {
+		maisProximoLinear(o, r.Intn(larguraTeste), r.Intn(alturaTeste))
+	}
+}
@@ -0,0 +1,216 @@
+// eventos.go - Barramento de eventos da partida
+// As entidades (caixas, monstro, jogadores) publicam o que aconteceu e seguem em frente;
+// quem se interessa (missões, barra de status, estatísticas...) assina os tipos de
+// evento que quer receber e os lê de um canal, na ordem em que foram publicados.
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TipoEvento identifica o que aconteceu na partida
+type TipoEvento int
+
+const (
+	EventoTesouroEncontrado TipoEvento = iota // um jogador abriu uma caixa com tesouro
+	EventoCaixaVazia                          // um jogador abriu uma caixa vazia
+	EventoArmadilha                           // um jogador abriu uma caixa com armadilha
+	EventoMonstroApareceu                     // o monstro surgiu no mapa
+	EventoTesouroRoubado                      // o monstro roubou um tesouro de um jogador
+	EventoMonstroDerrotado                    // um jogador derrotou o monstro
+	EventoJogadorMoveu                        // um jogador andou
+	EventoConversa                            // um jogador conversou com o Guian
+	EventoItem                                // um jogador recebeu ou entregou um item
+	EventoObjetivoConcluido                   // um objetivo da partida foi concluído
+	EventoTempo                               // o relógio da partida avançou
+	EventoFimDeJogo                           // a partida terminou, com vitória ou derrota
+	EventoConquista                           // um jogador desbloqueou uma conquista
+	EventoJogadorAlcancado                    // o monstro alcançou um jogador sem tesouros para roubar
+	EventoOnda                                // começou uma nova onda de monstros (sobrevivência)
+)
+
+// Nomes dos tipos de evento, usados no registro (log.go)
+var nomesEventos = map[TipoEvento]string{
+	EventoTesouroEncontrado: "tesouro_encontrado",
+	EventoCaixaVazia:        "caixa_vazia",
+	EventoArmadilha:         "armadilha",
+	EventoMonstroApareceu:   "monstro_apareceu",
+	EventoTesouroRoubado:    "tesouro_roubado",
+	EventoMonstroDerrotado:  "monstro_derrotado",
+	EventoJogadorMoveu:      "jogador_moveu",
+	EventoConversa:          "conversa",
+	EventoItem:              "item",
+	EventoObjetivoConcluido: "objetivo_concluido",
+	EventoTempo:             "tempo",
+	EventoFimDeJogo:         "fim_de_jogo",
+	EventoConquista:         "conquista",
+	EventoJogadorAlcancado:  "jogador_alcancado",
+	EventoOnda:              "onda",
+}
+
+func (t TipoEvento) String() string {
+	return nomesEventos[t]
+}
+
+// Evento é algo que aconteceu na partida
+type Evento struct {
+	Tipo       TipoEvento
+	Jogador    *Jogador      // quem causou o evento (nil quando não há um jogador envolvido)
+	JogadorID  int           // número do jogador ao publicar; fora do dono do estado, use este
+	X, Y       int           // onde aconteceu
+	Quantidade int           // tesouros restantes ou recuperados, ou o número da onda, conforme o evento
+	Tempo      time.Duration // quanto o relógio avançou (EventoTempo)
+	Vitoria    bool          // resultado da partida (EventoFimDeJogo)
+	Mensagem   string        // texto para o jogador (EventoObjetivoConcluido, EventoFimDeJogo, EventoConquista)
+}
+
+// Barramento entrega cada evento publicado a todas as assinaturas interessadas
+type Barramento struct {
+	mu          sync.Mutex
+	assinaturas []*Assinatura
+}
+
+// Assinatura recebe os eventos de alguns tipos pelo canal C
+type Assinatura struct {
+	C <-chan Evento
+
+	b     *Barramento
+	tipos map[TipoEvento]bool // vazio: todos os tipos
+	saida chan Evento
+	mu    sync.Mutex
+	fila  []Evento      // eventos publicados e ainda não entregues
+	aviso chan struct{} // sinaliza que a fila recebeu eventos
+	fim   chan struct{}
+	uma   sync.Once
+}
+
+func barramentoNovo() *Barramento {
+	return &Barramento{}
+}
+
+// Cria uma assinatura para os tipos de evento indicados (nenhum tipo: todos)
+func (b *Barramento) Assinar(tipos ...TipoEvento) *Assinatura {
+	a := &Assinatura{
+		b:     b,
+		tipos: make(map[TipoEvento]bool),
+		saida: make(chan Evento),
+		aviso: make(chan struct{}, 1),
+		fim:   make(chan struct{}),
+	}
+	a.C = a.saida
+	for _, t := range tipos {
+		a.tipos[t] = true
+	}
+
+	b.mu.Lock()
+	b.assinaturas = append(b.assinaturas, a)
+	b.mu.Unlock()
+
+	go a.entregar()
+	return a
+}
+
+// Publica um evento. Nunca bloqueia: cada assinatura guarda os eventos numa fila
+// própria, então o dono do estado pode publicar sem esperar quem assina.
+func (b *Barramento) Publicar(ev Evento) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, a := range b.assinaturas {
+		if len(a.tipos) > 0 && !a.tipos[ev.Tipo] {
+			continue
+		}
+		a.mu.Lock()
+		a.fila = append(a.fila, ev)
+		a.mu.Unlock()
+		select {
+		case a.aviso <- struct{}{}:
+		default: // já havia um aviso pendente
+		}
+	}
+}
+
+// Encerra a assinatura; os eventos ainda não entregues são descartados e o canal é fechado
+func (a *Assinatura) Cancelar() {
+	a.uma.Do(func() {
+		a.b.mu.Lock()
+		for i, outra := range a.b.assinaturas {
+			if outra == a {
+				a.b.assinaturas = append(a.b.assinaturas[:i], a.b.assinaturas[i+1:]...)
+				break
+			}
+		}
+		a.b.mu.Unlock()
+		close(a.fim)
+	})
+}
+
+// Goroutine que repassa a fila para o canal, na ordem de publicação
+func (a *Assinatura) entregar() {
+	defer close(a.saida)
+	for {
+		select {
+		case <-a.aviso:
+		case <-a.fim:
+			return
+		}
+
+		a.mu.Lock()
+		fila := a.fila
+		a.fila = nil
+		a.mu.Unlock()
+
+		for _, ev := range fila {
+			select {
+			case a.saida <- ev:
+			case <-a.fim:
+				return
+			}
+		}
+	}
+}
+
+// Publica um evento da partida
+func jogoPublicar(jogo *Jogo, ev Evento) {
+	// o Jogador pertence ao dono do estado e muda depois; o número é copiado agora
+	if ev.Jogador != nil {
+		ev.JogadorID = ev.Jogador.ID
+	}
+	jogo.Eventos.Publicar(ev)
+}
+
+// Trata os eventos dos tipos indicados numa goroutine até o canal parar ser fechado
+func jogoAssinar(jogo *Jogo, parar <-chan struct{}, tratar func(Evento), tipos ...TipoEvento) {
+	a := jogo.Eventos.Assinar(tipos...)
+	go a.consumir(parar, tratar)
+}
+
+// Como jogoAssinar, mas a assinatura pertence à partida atual: roda sob o supervisor
+// das entidades e termina com ela. Cada evento é tratado pelo dono do estado (mundo.go),
+// então tratar pode mexer no mundo.
+func jogoAssinarPartida(jogo *Jogo, nome string, tratar func(Evento), tipos ...TipoEvento) {
+	a := jogo.Eventos.Assinar(tipos...)
+	jogo.Entidades.Iniciar(nome, func(ctx context.Context) error {
+		a.consumir(ctx.Done(), func(ev Evento) {
+			jogo.Mundo.Enviar(IntencaoFunc(func(*Jogo) { tratar(ev) }), ctx.Done())
+		})
+		return nil
+	})
+}
+
+// Trata os eventos da assinatura até o canal parar ser fechado, e então a cancela
+func (a *Assinatura) consumir(parar <-chan struct{}, tratar func(Evento)) {
+	defer a.Cancelar()
+	for {
+		select {
+		case ev, ok := <-a.C:
+			if !ok {
+				return
+			}
+			tratar(ev)
+		case <-parar:
+			return
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// gerador.go - Geração de mapas aleatórios
+// Gera labirintos no mesmo formato dos arquivos de mapa (mapa.txt): paredes '▤',
+// vegetação '♣' e a posição inicial do personagem '☺'.
+package main
+
+import (
+	"math/rand"
+)
+
+// Menor largura e altura de um mapa gerado
+const mapaGeradoMinimo = 7
+
+// Gera um labirinto com um caminho entre quaisquer dois pontos. Depois de cavar o
+// labirinto, abre algumas paredes a mais para haver rotas alternativas (o monstro
+// persegue o jogador e as caixas mudam de lugar) e espalha vegetação pelos corredores.
+// Largura e altura pares são reduzidas em um, para o labirinto ter bordas fechadas.
+func mapaGerar(largura, altura int, vegetacao float64, r *rand.Rand) []string {
+	if largura%2 == 0 {
+		largura--
+	}
+	if altura%2 == 0 {
+		altura--
+	}
+	grade := make([][]rune, altura)
+	for y := range grade {
+		grade[y] = make([]rune, largura)
+		for x := range grade[y] {
+			grade[y][x] = Parede.simbolo
+		}
+	}
+
+	// busca em profundidade pelas células de coordenadas ímpares
+	type celula struct{ x, y int }
+	direcoes := []celula{{0, -2}, {0, 2}, {-2, 0}, {2, 0}}
+	pilha := []celula{{1, 1}}
+	grade[1][1] = Vazio.simbolo
+	for len(pilha) > 0 {
+		atual := pilha[len(pilha)-1]
+		var vizinhas []celula
+		for _, d := range direcoes {
+			v := celula{atual.x + d.x, atual.y + d.y}
+			if v.x > 0 && v.x < largura-1 && v.y > 0 && v.y < altura-1 && grade[v.y][v.x] == Parede.simbolo {
+				vizinhas = append(vizinhas, v)
+			}
+		}
+		if len(vizinhas) == 0 {
+			pilha = pilha[:len(pilha)-1]
+			continue
+		}
+		v := vizinhas[r.Intn(len(vizinhas))]
+		grade[(atual.y+v.y)/2][(atual.x+v.x)/2] = Vazio.simbolo
+		grade[v.y][v.x] = Vazio.simbolo
+		pilha = append(pilha, v)
+	}
+
+	// abre paredes internas entre dois corredores
+	for i := 0; i < largura*altura/20; i++ {
+		x, y := 1+r.Intn(largura-2), 1+r.Intn(altura-2)
+		if grade[y][x] != Parede.simbolo {
+			continue
+		}
+		horizontal := grade[y][x-1] != Parede.simbolo && grade[y][x+1] != Parede.simbolo
+		vertical := grade[y-1][x] != Parede.simbolo && grade[y+1][x] != Parede.simbolo
+		if horizontal != vertical {
+			grade[y][x] = Vazio.simbolo
+		}
+	}
+
+	for y := range grade {
+		for x := range grade[y] {
+			if grade[y][x] == Vazio.simbolo && r.Float64() < vegetacao {
+				grade[y][x] = Vegetacao.simbolo
+			}
+		}
+	}
+	grade[1][1] = Personagem.simbolo
+
+	linhas := make([]string, altura)
+	for y, l := range grade {
+		linhas[y] = string(l)
+	}
+	return linhas
+}
@@ -0,0 +1,52 @@
+// hud.go - Mensagens da barra de status em resposta aos eventos da partida
+package main
+
+import "time"
+
+// Assina os eventos da partida e mostra na barra de status o que aconteceu.
+// Vale para todas as partidas da sessão, até o canal parar ser fechado.
+func hudIniciar(jogo *Jogo, parar <-chan struct{}) {
+	jogoAssinar(jogo, parar, func(ev Evento) {
+		switch ev.Tipo {
+		case EventoCaixaVazia:
+			jogo.SetMessage(tr("hud.caixa_vazia"), 3*time.Second)
+		case EventoTesouroEncontrado:
+			jogo.SetMessage(tr("hud.tesouro"), 3*time.Second)
+		case EventoArmadilha:
+			jogo.Notificar(tr("hud.armadilha"), PrioridadeAviso, 3*time.Second)
+		case EventoMonstroApareceu:
+			jogo.Notificar(tr("hud.monstro_apareceu"), PrioridadeAviso, 5*time.Second)
+		case EventoTesouroRoubado:
+			if jogo.Mundo.Retrato().Disputa == DisputaCompetitiva {
+				jogo.Notificar(trN("hud.roubo_jogador", ev.Quantidade, ev.JogadorID+1, ev.Quantidade), PrioridadeAviso, 10*time.Second)
+			} else {
+				jogo.Notificar(trN("hud.roubo", ev.Quantidade, ev.Quantidade), PrioridadeAviso, 10*time.Second)
+			}
+		case EventoMonstroDerrotado:
+			if ev.Quantidade > 0 {
+				jogo.SetMessage(trN("hud.recuperou", ev.Quantidade, ev.Quantidade), 3*time.Second)
+			} else {
+				jogo.SetMessage(tr("hud.derrotou"), 3*time.Second)
+			}
+		case EventoObjetivoConcluido:
+			jogo.SetMessage(ev.Mensagem, 4*time.Second)
+		case EventoFimDeJogo:
+			jogo.Notificar(ev.Mensagem, PrioridadeCritica, 1*time.Minute)
+		case EventoOnda:
+			jogo.Notificar(tr("hud.onda", ev.Quantidade), PrioridadeAviso, 5*time.Second)
+		case EventoConquista:
+			// a conquista aparece num quadro no canto da tela, não na barra de status
+			jogo.Mensagens.Adicionar(Mensagem{
+				Texto:      tr("conquistas.desbloqueada") + "\n" + ev.Mensagem,
+				Prioridade: PrioridadeAviso,
+				Chave:      "conquista:" + ev.Mensagem,
+				Destaque:   true,
+				Expira:     time.Now().Add(5 * time.Second),
+			})
+		}
+	},
+		EventoCaixaVazia, EventoTesouroEncontrado, EventoArmadilha, EventoMonstroApareceu,
+		EventoTesouroRoubado, EventoMonstroDerrotado, EventoObjetivoConcluido, EventoFimDeJogo,
+		EventoConquista, EventoOnda,
+	)
+}
@@ -0,0 +1,179 @@
+// i18n.go - Tradução dos textos exibidos ao jogador
+// Os textos ficam em catálogos (um por idioma) indexados por identificadores, como
+// "hud.tesouro". Um texto pode ter argumentos no estilo do fmt e uma forma singular,
+// guardada no mesmo identificador com o sufixo ".um" (usada por trN quando n == 1).
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Catalogo associa identificadores aos textos de um idioma
+type Catalogo map[string]string
+
+// Idioma usado quando nenhum é escolhido; é também o catálogo de referência
+const IdiomaPadrao = "pt"
+
+// Catálogos disponíveis
+var catalogos = map[string]Catalogo{
+	"pt": catalogoPt,
+	"en": catalogoEn,
+	"es": catalogoEs,
+}
+
+// Idioma atual, definido na inicialização
+var idiomaAtual = IdiomaPadrao
+
+// Escolhe o idioma dos textos
+func i18nDefinirIdioma(codigo string) error {
+	if _, ok := catalogos[codigo]; !ok {
+		return fmt.Errorf("idioma não suportado: %q (disponíveis: %s)", codigo, strings.Join(i18nIdiomas(), ", "))
+	}
+	idiomaAtual = codigo
+	return nil
+}
+
+// Nome do idioma, escrito nele mesmo (ex: "English")
+func i18nNome(codigo string) string {
+	return catalogos[codigo]["idioma.nome"]
+}
+
+// Idiomas disponíveis, em ordem alfabética
+func i18nIdiomas() []string {
+	var codigos []string
+	for c := range catalogos {
+		codigos = append(codigos, c)
+	}
+	sort.Strings(codigos)
+	return codigos
+}
+
+// Idioma indicado pela variável de ambiente LANG (ex: "en_US.UTF-8" -> "en"),
+// ou o idioma padrão se ela não indicar um idioma disponível
+func i18nIdiomaDoAmbiente() string {
+	lang := strings.ToLower(os.Getenv("LANG"))
+	if i := strings.IndexAny(lang, "_.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogos[lang]; ok {
+		return lang
+	}
+	return IdiomaPadrao
+}
+
+// Procura o texto no idioma atual e, se faltar, no idioma padrão
+func i18nTexto(id string) (string, bool) {
+	if t, ok := catalogos[idiomaAtual][id]; ok {
+		return t, true
+	}
+	t, ok := catalogos[IdiomaPadrao][id]
+	return t, ok
+}
+
+// Traduz o texto identificado por id, formatando os argumentos
+func tr(id string, args ...any) string {
+	t, ok := i18nTexto(id)
+	if !ok {
+		return id
+	}
+	if len(args) == 0 {
+		return t
+	}
+	return fmt.Sprintf(t, args...)
+}
+
+// Traduz um texto que varia com a quantidade n. A forma singular ("id.um") pode
+// omitir o número; nesse caso ela é usada como está, sem os argumentos.
+func trN(id string, n int, args ...any) string {
+	if n == 1 {
+		if t, ok := i18nTexto(id + ".um"); ok {
+			if !strings.Contains(t, "%") {
+				return t
+			}
+			return fmt.Sprintf(t, args...)
+		}
+	}
+	return tr(id, args...)
+}
+
+// Nome exibido de um item; os itens são identificados pelo nome em português
+// nos arquivos de dados, e o catálogo pode traduzi-lo como "objeto.<nome>"
+func trItem(nome string) string {
+	if t, ok := i18nTexto("objeto." + nome); ok {
+		return t
+	}
+	return nome
+}
+
+// Nomes exibidos de uma lista de itens, separados por vírgula
+func trItens(nomes []string) string {
+	textos := make([]string, len(nomes))
+	for i, n := range nomes {
+		textos[i] = trItem(n)
+	}
+	return strings.Join(textos, ", ")
+}
+
+// Arquivo de dados traduzido, se existir (ex: "dialogos.json" -> "dialogos.en.json")
+func i18nArquivo(nome string) string {
+	if idiomaAtual == IdiomaPadrao {
+		return nome
+	}
+	ext := filepath.Ext(nome)
+	traduzido := strings.TrimSuffix(nome, ext) + "." + idiomaAtual + ext
+	if _, err := os.Stat(traduzido); err == nil {
+		return traduzido
+	}
+	return nome
+}
+
+// Quantos argumentos de formatação o texto usa
+func i18nContarVerbos(t string) int {
+	n := 0
+	for i := 0; i < len(t); i++ {
+		if t[i] != '%' {
+			continue
+		}
+		if i+1 < len(t) && t[i+1] == '%' {
+			i++ // "%%" é um sinal de porcentagem
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+// Compara todos os catálogos com o catálogo padrão: cada um deve ter os mesmos
+// identificadores, com a mesma quantidade de argumentos. Retorna os problemas encontrados.
+func i18nVerificar() []string {
+	var problemas []string
+	referencia := catalogos[IdiomaPadrao]
+	for _, codigo := range i18nIdiomas() {
+		cat := catalogos[codigo]
+		for id, t := range referencia {
+			traducao, ok := cat[id]
+			if !ok {
+				problemas = append(problemas, fmt.Sprintf("%s: falta %q", codigo, id))
+				continue
+			}
+			// a forma singular pode omitir o número
+			if strings.HasSuffix(id, ".um") && i18nContarVerbos(traducao) == 0 {
+				continue
+			}
+			if i18nContarVerbos(traducao) != i18nContarVerbos(t) {
+				problemas = append(problemas, fmt.Sprintf("%s: %q usa %d argumentos, mas %s usa %d", codigo, id, i18nContarVerbos(traducao), IdiomaPadrao, i18nContarVerbos(t)))
+			}
+		}
+		for id := range cat {
+			if _, ok := referencia[id]; !ok {
+				problemas = append(problemas, fmt.Sprintf("%s: %q não existe em %s", codigo, id, IdiomaPadrao))
+			}
+		}
+	}
+	sort.Strings(problemas)
+	return problemas
+}
@@ -0,0 +1,232 @@
+// i18n_en.go - Textos em inglês
+package main
+
+var catalogoEn = Catalogo{
+	// nome do idioma, escrito nele mesmo
+	"idioma.nome": "English",
+
+	// barra de status
+	"hud.caixa_vazia":      "...EMPTY BOX!",
+	"hud.tesouro":          "TREASURE FOUND!",
+	"hud.armadilha":        "You fell into a trap!",
+	"hud.monstro_apareceu": "A monster appeared on the map!\nHurry and grab every treasure before it steals them...",
+	"hud.roubo_jogador":    "The monster stole a treasure from player %d! (%d left)",
+	"hud.roubo_jogador.um": "The monster stole a treasure from player %d! (%d left)",
+	"hud.roubo":            "The monster stole a treasure! (%d left)",
+	"hud.roubo.um":         "The monster stole a treasure! (%d left)",
+	"hud.recuperou":        "You recovered %d treasures!",
+	"hud.recuperou.um":     "You recovered a treasure!",
+	"hud.derrotou":         "You defeated the monster!",
+	"hud.onda":             "WAVE %d! The monster is faster and stronger.",
+
+	// placar
+	"placar.meta":           "Find the %d treasures hidden on the map!",
+	"placar.meta.um":        "Find the treasure hidden on the map!",
+	"placar.encontrados":    "TREASURES FOUND: %d/%d",
+	"placar.competitivo":    "The first to find %d treasures wins!",
+	"placar.competitivo.um": "The first to find a treasure wins!",
+	"placar.objetivo":       "Objective: %s (M: quests)",
+	"placar.pontos":         "SCORE: %d",
+	"placar.tempo":          "TIME: %s",
+	"placar.onda":           "WAVE %d - TIME ALIVE: %s",
+
+	// ações do jogador
+	"personagem.bloqueio":    "A box blocks the way!",
+	"personagem.interagindo": "Interacting at (%d, %d)",
+	"personagem.nada_atacar": "There is nothing to attack here.",
+	"personagem.acertou":     "You hit the monster!",
+	"jogo.interagiu":         "You interacted with the box!",
+	"porta.aberta":           "The door opened.",
+	"porta.fechada":          "The door closed.",
+	"porta.bloqueada":        "Something is in the doorway.",
+	"alavanca.puxada":        "You pulled the lever: %d door(s) moved.",
+	"portal.fechado":         "The portal is closed: complete the level objectives.",
+	"portal.aberto":          "A portal opened! Step into it to finish the level.",
+	"item.nenhum":            "You have no items to use.",
+	"item.lista":             "Items: %s (trade them with Guian)",
+	"salvar.ok":              "Game saved to %s",
+	"salvar.erro":            "Could not save: %v",
+	"desfazer.vazio":         "Nothing to undo.",
+	"desfazer.impossivel":    "That action cannot be undone.",
+
+	// fim de jogo
+	"fim.armadilha":       "GAME OVER!",
+	"fim.roubo":           "GAME OVER!\nThe monster stole ALL the treasures!",
+	"fim.vitoria":         "Congratulations! You completed every objective!",
+	"fim.vitoria_jogador": "Player %d won with %d treasures!",
+	"fim.erro_interno":    "GAME OVER!\nPart of the game failed (see the log).",
+	"fim.tempo":           "GAME OVER!\nTime is up!",
+	"fim.alcancado":       "GAME OVER!\nThe monster caught you after %s, in wave %d.",
+	"supervisor.falha":    "Internal error in %s; the game was stopped.",
+
+	// Guian
+	"dica.nenhum":          "COLD (no treasure found)",
+	"dica.muito_quente":    "HOT! (TREASURE VERY CLOSE!)",
+	"dica.quente":          "HOT",
+	"dica.morno":           "WARM",
+	"dica.frio":            "COLD",
+	"dialogo.guian":        "Guian: %s",
+	"dialogo.dica":         "You are %s",
+	"dialogo.sem_caixas":   "I can't sense any closed box anymore.",
+	"dialogo.revelar":      "The box at (%d, %d) holds: %s.",
+	"dialogo.sem_item":     "You don't have %s.",
+	"dialogo.entregou":     "You handed over %s.",
+	"dialogo.recebeu":      "You received %s.",
+	"dialogo.itens":        "Items: %s",
+	"dialogo.padrao":       "Hello! I can sense the treasures hidden in the boxes.",
+	"dialogo.padrao.perto": "Am I close to one?",
+	"dialogo.padrao.tchau": "Goodbye.",
+	"caixa.tesouro":        "a TREASURE",
+	"caixa.armadilha":      "a TRAP",
+	"caixa.vazia":          "nothing",
+
+	// itens
+	"objeto.lanterna": "lantern",
+	"objeto.amuleto":  "amulet",
+
+	// missões
+	"missao.tesouros":                "Find %d treasures",
+	"missao.tesouros.um":             "Find a treasure",
+	"missao.tesouros_competitivo":    "Be the first to find %d treasures",
+	"missao.tesouros_competitivo.um": "Be the first to find a treasure",
+	"missao.item":                    "Get %d× %s",
+	"missao.item.um":                 "Get %[2]s",
+	"missao.alcancar":                "Reach position (%d, %d)",
+	"missao.derrotar":                "Defeat the monster %d times",
+	"missao.derrotar.um":             "Defeat the monster",
+	"missao.sobreviver":              "Survive for %d seconds",
+	"missao.sobreviver.um":           "Survive for one second",
+	"missao.conversar":               "Talk to Guian",
+	"missao.concluido":               "Objective complete: %s",
+	"missao.recompensa":              "%s (received %s)",
+	"missao.titulo":                  "QUESTS",
+
+	// histórico de mensagens
+	"historico.titulo": "MESSAGES (↑/↓ scroll)",
+	"historico.vazio":  "No messages yet.",
+
+	// menus
+	"menu.voltar":               "Back",
+	"menu.pausado":              "PAUSED",
+	"menu.continuar":            "Resume",
+	"menu.salvar":               "Save",
+	"menu.carregar":             "Load",
+	"menu.missoes":              "Quests",
+	"menu.mensagens":            "Messages",
+	"menu.configuracoes":        "Settings",
+	"menu.controles":            "Controls",
+	"menu.sair":                 "Quit",
+	"menu.ligado":               "on",
+	"menu.desligado":            "off",
+	"menu.configuracoes_titulo": "SETTINGS",
+	"menu.quebra_cabeca":        "Puzzle mode: %s",
+	"menu.dificuldade":          "Difficulty: %s",
+	"menu.dificuldade_proxima":  "The new difficulty applies from the next game.",
+	"dificuldade.facil":         "easy",
+	"dificuldade.normal":        "normal",
+	"dificuldade.dificil":       "hard",
+	"menu.fim":                  "GAME OVER",
+	"menu.vitoria":              "VICTORY!",
+	"menu.tesouros":             "Treasures: %d/%d",
+	"menu.jogar_novamente":      "Play again",
+	"menu.novo_jogo":            "New game",
+	"menu.titulo":               "CONCURRENT GAME",
+	"menu.subtitulo":            "Find the treasures hidden in the boxes %c",
+	"menu.erro_iniciar":         "Could not start: %v",
+	"menu.erro_reiniciar":       "Could not restart: %v",
+	"menu.erro_carregar":        "Could not load: %v",
+	"menu.pontos":               "Score: %d (total: %d)",
+	"menu.proximo_nivel":        "Next level",
+	"menu.recomecar_campanha":   "Restart the campaign",
+	"menu.recordes":             "High scores",
+	"menu.conquistas":           "Achievements",
+	"menu.tema":                 "Theme: %s",
+	"menu.idioma":               "Language: %s",
+	"menu.erro_tema":            "Could not change the theme: %v",
+	"menu.erro_idioma":          "Could not change the language: %v",
+	"menu.perfil":               "Profile: %s",
+	"menu.modo":                 "Mode: %s",
+
+	// modos de jogo
+	"modo.classico":       "classic",
+	"modo.contra-relogio": "time attack",
+	"modo.sobrevivencia":  "survival",
+	"modo.tempo_extra":    "+%ds on the clock!",
+
+	// campanha
+	"campanha.nivel":           "Level %d/%d: %s",
+	"campanha.nivel_concluido": "LEVEL COMPLETE!",
+	"campanha.concluida":       "Campaign complete: %s!",
+	"campanha.sem_proximo":     "there is no next level in the campaign",
+
+	// recordes
+	"recordes.titulo":  "HIGH SCORES: %s",
+	"recordes.colunas": "#|result|score|time|tr.|map|diff.|date",
+	"recordes.vitoria": "victory",
+	"recordes.derrota": "defeat",
+	"recordes.vazio":   "No games recorded yet.",
+	"recordes.novo":    "New high score! Number %d on the table.",
+	"recordes.erro":    "Could not record the score: %v",
+
+	// conquistas
+	"conquistas.titulo":       "ACHIEVEMENTS",
+	"conquistas.resumo":       "%d of %d achievements unlocked",
+	"conquistas.vazio":        "No achievements defined.",
+	"conquistas.desbloqueada": "★ Achievement unlocked!",
+
+	// perfis
+	"perfil.titulo":         "PROFILES",
+	"perfil.ativo":          "Current profile: %s",
+	"perfil.novo":           "New profile",
+	"perfil.excluir":        "Delete a profile",
+	"perfil.trocado":        "Current profile: %s.",
+	"perfil.erro":           "Profile problem: %v",
+	"perfil.existe":         "a profile named %q already exists",
+	"perfil.nome_vazio":     "the profile name cannot be empty",
+	"perfil.nome_longo":     "the profile name can have up to %d characters",
+	"perfil.nome_invalido":  "invalid profile name: %q (use letters, digits, - and _)",
+	"perfil.novo_titulo":    "NEW PROFILE",
+	"perfil.nome":           "Name: %s",
+	"perfil.nome_ajuda":     "Enter creates the profile, Esc cancels",
+	"perfil.excluir_titulo": "DELETE PROFILE",
+	"perfil.excluir_ajuda":  "The current profile cannot be deleted.",
+	"perfil.nenhum_outro":   "There are no other profiles.",
+	"perfil.confirmar":      "DELETE %s?",
+	"perfil.aviso_excluir":  "Its settings, high scores and achievements will be erased.",
+	"perfil.sim":            "Yes, delete",
+	"perfil.nao":            "No",
+	"perfil.excluido":       "Profile %s deleted.",
+
+	// configuração de teclas
+	"teclas.titulo":       "CONFIGURE KEYS",
+	"teclas.aguardando":   "press the new key...",
+	"teclas.ajuda":        "↑/↓ choose  Enter change  Esc save and exit",
+	"teclas.salvas":       "Keys saved to %s",
+	"teclas.erro":         "Could not save the keys: %v",
+	"teclas.invalida":     "Key not bound: %v",
+	"acao.mover_cima":     "Move up",
+	"acao.mover_esquerda": "Move left",
+	"acao.mover_baixo":    "Move down",
+	"acao.mover_direita":  "Move right",
+	"acao.interagir":      "Interact",
+	"acao.atacar":         "Attack",
+	"acao.usar_item":      "Use item",
+	"acao.pausar":         "Pause",
+	"acao.salvar":         "Save",
+	"acao.desfazer":       "Undo (puzzle)",
+	"acao.teclas":         "Configure keys",
+	"acao.missoes":        "Quest log",
+	"acao.historico":      "Message history",
+	"acao.depuracao":      "Debug overlay",
+	"acao.sair":           "Quit",
+
+	// rede
+	"rede.aguardando":    "Waiting for the second player on %s",
+	"rede.entrou":        "Player %d joined the game!",
+	"rede.desconectou":   "Player %d disconnected.",
+	"rede.perdida":       "Lost connection to the host!",
+	"rede.encerrada":     "Game ended: %s",
+	"rede.versao":        "incompatible protocol version (host %d, client %d)",
+	"rede.cheia":         "the game is already full",
+	"rede.fim_anfitriao": "the host ended the game",
+}
@@ -0,0 +1,232 @@
+// i18n_es.go - Textos em espanhol
+package main
+
+var catalogoEs = Catalogo{
+	// nome do idioma, escrito nele mesmo
+	"idioma.nome": "Español",
+
+	// barra de status
+	"hud.caixa_vazia":      "...¡CAJA VACÍA!",
+	"hud.tesouro":          "¡TESORO ENCONTRADO!",
+	"hud.armadilha":        "¡Caíste en una trampa!",
+	"hud.monstro_apareceu": "¡Un monstruo apareció en el mapa!\nCorre a tomar todos los tesoros antes de que los robe...",
+	"hud.roubo_jogador":    "¡El monstruo robó un tesoro del jugador %d! (quedan %d)",
+	"hud.roubo_jogador.um": "¡El monstruo robó un tesoro del jugador %d! (queda %d)",
+	"hud.roubo":            "¡El monstruo robó un tesoro! (quedan %d)",
+	"hud.roubo.um":         "¡El monstruo robó un tesoro! (queda %d)",
+	"hud.recuperou":        "¡Recuperaste %d tesoros!",
+	"hud.recuperou.um":     "¡Recuperaste un tesoro!",
+	"hud.derrotou":         "¡Derrotaste al monstruo!",
+	"hud.onda":             "¡OLEADA %d! El monstruo es más rápido y más fuerte.",
+
+	// placar
+	"placar.meta":           "¡Encuentra los %d tesoros escondidos en el mapa!",
+	"placar.meta.um":        "¡Encuentra el tesoro escondido en el mapa!",
+	"placar.encontrados":    "TESOROS ENCONTRADOS: %d/%d",
+	"placar.competitivo":    "¡El primero en encontrar %d tesoros gana!",
+	"placar.competitivo.um": "¡El primero en encontrar un tesoro gana!",
+	"placar.objetivo":       "Objetivo: %s (M: misiones)",
+	"placar.pontos":         "PUNTOS: %d",
+	"placar.tempo":          "TIEMPO: %s",
+	"placar.onda":           "OLEADA %d - TIEMPO VIVO: %s",
+
+	// ações do jogador
+	"personagem.bloqueio":    "¡Una caja bloquea el camino!",
+	"personagem.interagindo": "Interactuando en (%d, %d)",
+	"personagem.nada_atacar": "No hay nada que atacar aquí.",
+	"personagem.acertou":     "¡Golpeaste al monstruo!",
+	"jogo.interagiu":         "¡Interactuaste con la caja!",
+	"porta.aberta":           "La puerta se abrió.",
+	"porta.fechada":          "La puerta se cerró.",
+	"porta.bloqueada":        "Algo está en el vano de la puerta.",
+	"alavanca.puxada":        "Tiraste de la palanca: %d puerta(s) se movieron.",
+	"portal.fechado":         "El portal está cerrado: completa los objetivos del nivel.",
+	"portal.aberto":          "¡Se abrió un portal! Entra en él para terminar el nivel.",
+	"item.nenhum":            "No tienes objetos para usar.",
+	"item.lista":             "Objetos: %s (cámbialos con Guian)",
+	"salvar.ok":              "Partida guardada en %s",
+	"salvar.erro":            "No se pudo guardar: %v",
+	"desfazer.vazio":         "Nada que deshacer.",
+	"desfazer.impossivel":    "Esa acción no se puede deshacer.",
+
+	// fim de jogo
+	"fim.armadilha":       "GAME OVER!",
+	"fim.roubo":           "GAME OVER!\n¡El monstruo robó TODOS los tesoros!",
+	"fim.vitoria":         "¡Felicidades! ¡Completaste todos los objetivos!",
+	"fim.vitoria_jogador": "¡El jugador %d ganó con %d tesoros!",
+	"fim.erro_interno":    "GAME OVER!\nUna parte del juego falló (ver el registro).",
+	"fim.tempo":           "GAME OVER!\n¡Se acabó el tiempo!",
+	"fim.alcancado":       "GAME OVER!\nEl monstruo te alcanzó después de %s, en la oleada %d.",
+	"supervisor.falha":    "Error interno en %s; la partida se detuvo.",
+
+	// Guian
+	"dica.nenhum":          "FRÍO (ningún tesoro encontrado)",
+	"dica.muito_quente":    "¡CALIENTE! (¡TESORO MUY CERCA!)",
+	"dica.quente":          "CALIENTE",
+	"dica.morno":           "TIBIO",
+	"dica.frio":            "FRÍO",
+	"dialogo.guian":        "Guian: %s",
+	"dialogo.dica":         "Estás %s",
+	"dialogo.sem_caixas":   "Ya no siento ninguna caja cerrada.",
+	"dialogo.revelar":      "La caja en (%d, %d) guarda: %s.",
+	"dialogo.sem_item":     "No tienes %s.",
+	"dialogo.entregou":     "Entregaste %s.",
+	"dialogo.recebeu":      "Recibiste %s.",
+	"dialogo.itens":        "Objetos: %s",
+	"dialogo.padrao":       "¡Hola! Puedo sentir los tesoros escondidos en las cajas.",
+	"dialogo.padrao.perto": "¿Estoy cerca de alguno?",
+	"dialogo.padrao.tchau": "Hasta luego.",
+	"caixa.tesouro":        "un TESORO",
+	"caixa.armadilha":      "una TRAMPA",
+	"caixa.vazia":          "nada",
+
+	// itens
+	"objeto.lanterna": "linterna",
+	"objeto.amuleto":  "amuleto",
+
+	// missões
+	"missao.tesouros":                "Encuentra %d tesoros",
+	"missao.tesouros.um":             "Encuentra un tesoro",
+	"missao.tesouros_competitivo":    "Sé el primero en encontrar %d tesoros",
+	"missao.tesouros_competitivo.um": "Sé el primero en encontrar un tesoro",
+	"missao.item":                    "Consigue %d× %s",
+	"missao.item.um":                 "Consigue %[2]s",
+	"missao.alcancar":                "Llega a la posición (%d, %d)",
+	"missao.derrotar":                "Derrota al monstruo %d veces",
+	"missao.derrotar.um":             "Derrota al monstruo",
+	"missao.sobreviver":              "Sobrevive %d segundos",
+	"missao.sobreviver.um":           "Sobrevive un segundo",
+	"missao.conversar":               "Habla con Guian",
+	"missao.concluido":               "Objetivo cumplido: %s",
+	"missao.recompensa":              "%s (recibiste %s)",
+	"missao.titulo":                  "MISIONES",
+
+	// histórico de mensagens
+	"historico.titulo": "MENSAJES (↑/↓ desplaza)",
+	"historico.vazio":  "Todavía no hay mensajes.",
+
+	// menus
+	"menu.voltar":               "Volver",
+	"menu.pausado":              "PAUSA",
+	"menu.continuar":            "Continuar",
+	"menu.salvar":               "Guardar",
+	"menu.carregar":             "Cargar",
+	"menu.missoes":              "Misiones",
+	"menu.mensagens":            "Mensajes",
+	"menu.configuracoes":        "Opciones",
+	"menu.controles":            "Controles",
+	"menu.sair":                 "Salir",
+	"menu.ligado":               "activado",
+	"menu.desligado":            "desactivado",
+	"menu.configuracoes_titulo": "OPCIONES",
+	"menu.quebra_cabeca":        "Modo rompecabezas: %s",
+	"menu.dificuldade":          "Dificultad: %s",
+	"menu.dificuldade_proxima":  "La nueva dificultad se aplica desde la próxima partida.",
+	"dificuldade.facil":         "fácil",
+	"dificuldade.normal":        "normal",
+	"dificuldade.dificil":       "difícil",
+	"menu.fim":                  "FIN DEL JUEGO",
+	"menu.vitoria":              "¡VICTORIA!",
+	"menu.tesouros":             "Tesoros: %d/%d",
+	"menu.jogar_novamente":      "Jugar de nuevo",
+	"menu.novo_jogo":            "Nueva partida",
+	"menu.titulo":               "JUEGO CONCURRENTE",
+	"menu.subtitulo":            "Encuentra los tesoros escondidos en las cajas %c",
+	"menu.erro_iniciar":         "No se pudo iniciar: %v",
+	"menu.erro_reiniciar":       "No se pudo reiniciar: %v",
+	"menu.erro_carregar":        "No se pudo cargar: %v",
+	"menu.pontos":               "Puntos: %d (total: %d)",
+	"menu.proximo_nivel":        "Siguiente nivel",
+	"menu.recomecar_campanha":   "Reiniciar la campaña",
+	"menu.recordes":             "Récords",
+	"menu.conquistas":           "Logros",
+	"menu.tema":                 "Tema: %s",
+	"menu.idioma":               "Idioma: %s",
+	"menu.erro_tema":            "No se pudo cambiar el tema: %v",
+	"menu.erro_idioma":          "No se pudo cambiar el idioma: %v",
+	"menu.perfil":               "Perfil: %s",
+	"menu.modo":                 "Modo: %s",
+
+	// modos de jogo
+	"modo.classico":       "clásico",
+	"modo.contra-relogio": "contrarreloj",
+	"modo.sobrevivencia":  "supervivencia",
+	"modo.tempo_extra":    "¡+%ds en el reloj!",
+
+	// campanha
+	"campanha.nivel":           "Nivel %d/%d: %s",
+	"campanha.nivel_concluido": "¡NIVEL COMPLETADO!",
+	"campanha.concluida":       "¡Campaña completada: %s!",
+	"campanha.sem_proximo":     "no hay siguiente nivel en la campaña",
+
+	// recordes
+	"recordes.titulo":  "RÉCORDS: %s",
+	"recordes.colunas": "#|resultado|puntos|tiempo|tes.|mapa|dific.|fecha",
+	"recordes.vitoria": "victoria",
+	"recordes.derrota": "derrota",
+	"recordes.vazio":   "Todavía no hay partidas registradas.",
+	"recordes.novo":    "¡Nuevo récord! Puesto %d en la tabla.",
+	"recordes.erro":    "No se pudo guardar el récord: %v",
+
+	// conquistas
+	"conquistas.titulo":       "LOGROS",
+	"conquistas.resumo":       "%d de %d logros desbloqueados",
+	"conquistas.vazio":        "No hay logros definidos.",
+	"conquistas.desbloqueada": "★ ¡Logro desbloqueado!",
+
+	// perfis
+	"perfil.titulo":         "PERFILES",
+	"perfil.ativo":          "Perfil en uso: %s",
+	"perfil.novo":           "Nuevo perfil",
+	"perfil.excluir":        "Eliminar un perfil",
+	"perfil.trocado":        "Perfil en uso: %s.",
+	"perfil.erro":           "Problema con el perfil: %v",
+	"perfil.existe":         "ya existe un perfil llamado %q",
+	"perfil.nome_vazio":     "el nombre del perfil no puede estar vacío",
+	"perfil.nome_longo":     "el nombre del perfil puede tener hasta %d caracteres",
+	"perfil.nome_invalido":  "nombre de perfil no válido: %q (usa letras, números, - y _)",
+	"perfil.novo_titulo":    "NUEVO PERFIL",
+	"perfil.nome":           "Nombre: %s",
+	"perfil.nome_ajuda":     "Enter crea el perfil, Esc cancela",
+	"perfil.excluir_titulo": "ELIMINAR PERFIL",
+	"perfil.excluir_ajuda":  "El perfil en uso no se puede eliminar.",
+	"perfil.nenhum_outro":   "No hay otros perfiles.",
+	"perfil.confirmar":      "¿ELIMINAR %s?",
+	"perfil.aviso_excluir":  "Se borrarán su configuración, sus récords y sus logros.",
+	"perfil.sim":            "Sí, eliminar",
+	"perfil.nao":            "No",
+	"perfil.excluido":       "Perfil %s eliminado.",
+
+	// configuração de teclas
+	"teclas.titulo":       "CONFIGURAR TECLAS",
+	"teclas.aguardando":   "pulsa la nueva tecla...",
+	"teclas.ajuda":        "↑/↓ elegir  Enter cambiar  Esc guardar y salir",
+	"teclas.salvas":       "Teclas guardadas en %s",
+	"teclas.erro":         "No se pudieron guardar las teclas: %v",
+	"teclas.invalida":     "Tecla no asignada: %v",
+	"acao.mover_cima":     "Mover arriba",
+	"acao.mover_esquerda": "Mover a la izquierda",
+	"acao.mover_baixo":    "Mover abajo",
+	"acao.mover_direita":  "Mover a la derecha",
+	"acao.interagir":      "Interactuar",
+	"acao.atacar":         "Atacar",
+	"acao.usar_item":      "Usar objeto",
+	"acao.pausar":         "Pausa",
+	"acao.salvar":         "Guardar",
+	"acao.desfazer":       "Deshacer (rompecabezas)",
+	"acao.teclas":         "Configurar teclas",
+	"acao.missoes":        "Registro de misiones",
+	"acao.historico":      "Historial de mensajes",
+	"acao.depuracao":      "Depuración",
+	"acao.sair":           "Salir",
+
+	// rede
+	"rede.aguardando":    "Esperando al segundo jugador en %s",
+	"rede.entrou":        "¡El jugador %d se unió a la partida!",
+	"rede.desconectou":   "El jugador %d se desconectó.",
+	"rede.perdida":       "¡Se perdió la conexión con el anfitrión!",
+	"rede.encerrada":     "Partida terminada: %s",
+	"rede.versao":        "versión de protocolo incompatible (anfitrión %d, cliente %d)",
+	"rede.cheia":         "la partida ya está llena",
+	"rede.fim_anfitriao": "el anfitrión terminó la partida",
+}
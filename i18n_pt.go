@@ -0,0 +1,232 @@
+// i18n_pt.go - Textos em português (catálogo de referência)
+package main
+
+var catalogoPt = Catalogo{
+	// nome do idioma, escrito nele mesmo
+	"idioma.nome": "Português",
+
+	// barra de status
+	"hud.caixa_vazia":      "...CAIXA VAZIA!",
+	"hud.tesouro":          "TESOURO ENCONTRADO!",
+	"hud.armadilha":        "Você caiu numa armadilha!",
+	"hud.monstro_apareceu": "Um monstro apareceu no mapa!\nCorra para pegar todos os tesouros antes que ele roube...",
+	"hud.roubo_jogador":    "O monstro roubou um tesouro do jogador %d! (%d restantes)",
+	"hud.roubo_jogador.um": "O monstro roubou um tesouro do jogador %d! (%d restante)",
+	"hud.roubo":            "O monstro roubou um tesouro! (%d restantes)",
+	"hud.roubo.um":         "O monstro roubou um tesouro! (%d restante)",
+	"hud.recuperou":        "Você recuperou %d tesouros!",
+	"hud.recuperou.um":     "Você recuperou um tesouro!",
+	"hud.derrotou":         "Você derrotou o monstro!",
+	"hud.onda":             "ONDA %d! O monstro está mais rápido e mais forte.",
+
+	// placar
+	"placar.meta":           "Encontre os %d tesouros escondidos no mapa!",
+	"placar.meta.um":        "Encontre o tesouro escondido no mapa!",
+	"placar.encontrados":    "TESOUROS ENCONTRADOS: %d/%d",
+	"placar.competitivo":    "O primeiro a encontrar %d tesouros vence!",
+	"placar.competitivo.um": "O primeiro a encontrar um tesouro vence!",
+	"placar.objetivo":       "Objetivo: %s (M: missões)",
+	"placar.pontos":         "PONTOS: %d",
+	"placar.tempo":          "TEMPO: %s",
+	"placar.onda":           "ONDA %d - TEMPO VIVO: %s",
+
+	// ações do jogador
+	"personagem.bloqueio":    "Uma caixa bloqueia o caminho!",
+	"personagem.interagindo": "Interagindo em (%d, %d)",
+	"personagem.nada_atacar": "Não há nada para atacar aqui.",
+	"personagem.acertou":     "Você acertou o monstro!",
+	"jogo.interagiu":         "Você interagiu com a caixa!",
+	"porta.aberta":           "A porta se abriu.",
+	"porta.fechada":          "A porta se fechou.",
+	"porta.bloqueada":        "Algo está no vão da porta.",
+	"alavanca.puxada":        "Você puxou a alavanca: %d porta(s) se moveram.",
+	"portal.fechado":         "O portal está fechado: conclua os objetivos do nível.",
+	"portal.aberto":          "Um portal se abriu! Entre nele para concluir o nível.",
+	"item.nenhum":            "Você não tem itens para usar.",
+	"item.lista":             "Itens: %s (troque-os com o Guian)",
+	"salvar.ok":              "Jogo salvo em %s",
+	"salvar.erro":            "Não foi possível salvar: %v",
+	"desfazer.vazio":         "Nada para desfazer.",
+	"desfazer.impossivel":    "Essa ação não pode ser desfeita.",
+
+	// fim de jogo
+	"fim.armadilha":       "GAME OVER!",
+	"fim.roubo":           "GAME OVER!\nO monstro roubou TODOS os tesouros!",
+	"fim.vitoria":         "Parabéns! Você concluiu todos os objetivos!",
+	"fim.vitoria_jogador": "Jogador %d venceu com %d tesouros!",
+	"fim.erro_interno":    "GAME OVER!\nUma parte do jogo falhou (veja o registro).",
+	"fim.tempo":           "GAME OVER!\nO tempo acabou!",
+	"fim.alcancado":       "GAME OVER!\nO monstro te alcançou depois de %s, na onda %d.",
+	"supervisor.falha":    "Erro interno em %s; a partida foi interrompida.",
+
+	// Guian
+	"dica.nenhum":          "FRIO (nenhum tesouro encontrado)",
+	"dica.muito_quente":    "QUENTE! (TESOURO BEM PERTO!)",
+	"dica.quente":          "QUENTE",
+	"dica.morno":           "MORNO",
+	"dica.frio":            "FRIO",
+	"dialogo.guian":        "Guian: %s",
+	"dialogo.dica":         "Você está %s",
+	"dialogo.sem_caixas":   "Não sinto mais nenhuma caixa fechada.",
+	"dialogo.revelar":      "A caixa em (%d, %d) guarda: %s.",
+	"dialogo.sem_item":     "Você não tem %s.",
+	"dialogo.entregou":     "Você entregou %s.",
+	"dialogo.recebeu":      "Você recebeu %s.",
+	"dialogo.itens":        "Itens: %s",
+	"dialogo.padrao":       "Olá! Posso sentir os tesouros escondidos nas caixas.",
+	"dialogo.padrao.perto": "Estou perto de algum?",
+	"dialogo.padrao.tchau": "Até logo.",
+	"caixa.tesouro":        "um TESOURO",
+	"caixa.armadilha":      "uma ARMADILHA",
+	"caixa.vazia":          "nada",
+
+	// itens
+	"objeto.lanterna": "lanterna",
+	"objeto.amuleto":  "amuleto",
+
+	// missões
+	"missao.tesouros":                "Encontre %d tesouros",
+	"missao.tesouros.um":             "Encontre um tesouro",
+	"missao.tesouros_competitivo":    "Seja o primeiro a encontrar %d tesouros",
+	"missao.tesouros_competitivo.um": "Seja o primeiro a encontrar um tesouro",
+	"missao.item":                    "Consiga %d× %s",
+	"missao.item.um":                 "Consiga %[2]s",
+	"missao.alcancar":                "Chegue à posição (%d, %d)",
+	"missao.derrotar":                "Derrote o monstro %d vezes",
+	"missao.derrotar.um":             "Derrote o monstro",
+	"missao.sobreviver":              "Sobreviva por %d segundos",
+	"missao.sobreviver.um":           "Sobreviva por um segundo",
+	"missao.conversar":               "Converse com o Guian",
+	"missao.concluido":               "Objetivo concluído: %s",
+	"missao.recompensa":              "%s (recebeu %s)",
+	"missao.titulo":                  "MISSÕES",
+
+	// histórico de mensagens
+	"historico.titulo": "MENSAGENS (↑/↓ rola)",
+	"historico.vazio":  "Nenhuma mensagem ainda.",
+
+	// menus
+	"menu.voltar":               "Voltar",
+	"menu.pausado":              "PAUSADO",
+	"menu.continuar":            "Continuar",
+	"menu.salvar":               "Salvar",
+	"menu.carregar":             "Carregar",
+	"menu.missoes":              "Missões",
+	"menu.mensagens":            "Mensagens",
+	"menu.configuracoes":        "Configurações",
+	"menu.controles":            "Controles",
+	"menu.sair":                 "Sair",
+	"menu.ligado":               "ligado",
+	"menu.desligado":            "desligado",
+	"menu.configuracoes_titulo": "CONFIGURAÇÕES",
+	"menu.quebra_cabeca":        "Modo quebra-cabeça: %s",
+	"menu.dificuldade":          "Dificuldade: %s",
+	"menu.dificuldade_proxima":  "A nova dificuldade vale a partir da próxima partida.",
+	"dificuldade.facil":         "fácil",
+	"dificuldade.normal":        "normal",
+	"dificuldade.dificil":       "difícil",
+	"menu.fim":                  "FIM DE JOGO",
+	"menu.vitoria":              "VITÓRIA!",
+	"menu.tesouros":             "Tesouros: %d/%d",
+	"menu.jogar_novamente":      "Jogar novamente",
+	"menu.novo_jogo":            "Novo jogo",
+	"menu.titulo":               "JOGO CONCORRENTE",
+	"menu.subtitulo":            "Encontre os tesouros escondidos nas caixas %c",
+	"menu.erro_iniciar":         "Não foi possível iniciar: %v",
+	"menu.erro_reiniciar":       "Não foi possível reiniciar: %v",
+	"menu.erro_carregar":        "Não foi possível carregar: %v",
+	"menu.pontos":               "Pontos: %d (total: %d)",
+	"menu.proximo_nivel":        "Próximo nível",
+	"menu.recomecar_campanha":   "Recomeçar a campanha",
+	"menu.recordes":             "Recordes",
+	"menu.conquistas":           "Conquistas",
+	"menu.tema":                 "Tema: %s",
+	"menu.idioma":               "Idioma: %s",
+	"menu.erro_tema":            "Não foi possível trocar o tema: %v",
+	"menu.erro_idioma":          "Não foi possível trocar o idioma: %v",
+	"menu.perfil":               "Perfil: %s",
+	"menu.modo":                 "Modo: %s",
+
+	// modos de jogo
+	"modo.classico":       "clássico",
+	"modo.contra-relogio": "contra o relógio",
+	"modo.sobrevivencia":  "sobrevivência",
+	"modo.tempo_extra":    "+%ds no relógio!",
+
+	// campanha
+	"campanha.nivel":           "Nível %d/%d: %s",
+	"campanha.nivel_concluido": "NÍVEL CONCLUÍDO!",
+	"campanha.concluida":       "Campanha concluída: %s!",
+	"campanha.sem_proximo":     "não há próximo nível na campanha",
+
+	// recordes
+	"recordes.titulo":  "RECORDES: %s",
+	"recordes.colunas": "#|resultado|pontos|tempo|tes.|mapa|dific.|data",
+	"recordes.vitoria": "vitória",
+	"recordes.derrota": "derrota",
+	"recordes.vazio":   "Nenhuma partida registrada ainda.",
+	"recordes.novo":    "Novo recorde! %dº lugar na tabela.",
+	"recordes.erro":    "Não foi possível gravar o recorde: %v",
+
+	// conquistas
+	"conquistas.titulo":       "CONQUISTAS",
+	"conquistas.resumo":       "%d de %d conquistas desbloqueadas",
+	"conquistas.vazio":        "Nenhuma conquista definida.",
+	"conquistas.desbloqueada": "★ Conquista desbloqueada!",
+
+	// perfis
+	"perfil.titulo":         "PERFIS",
+	"perfil.ativo":          "Perfil em uso: %s",
+	"perfil.novo":           "Novo perfil",
+	"perfil.excluir":        "Excluir um perfil",
+	"perfil.trocado":        "Perfil em uso: %s.",
+	"perfil.erro":           "Problema com o perfil: %v",
+	"perfil.existe":         "já existe um perfil chamado %q",
+	"perfil.nome_vazio":     "o nome do perfil não pode ficar vazio",
+	"perfil.nome_longo":     "o nome do perfil pode ter até %d caracteres",
+	"perfil.nome_invalido":  "nome de perfil inválido: %q (use letras, números, - e _)",
+	"perfil.novo_titulo":    "NOVO PERFIL",
+	"perfil.nome":           "Nome: %s",
+	"perfil.nome_ajuda":     "Enter cria o perfil, Esc cancela",
+	"perfil.excluir_titulo": "EXCLUIR PERFIL",
+	"perfil.excluir_ajuda":  "O perfil em uso não pode ser excluído.",
+	"perfil.nenhum_outro":   "Não há outros perfis.",
+	"perfil.confirmar":      "EXCLUIR %s?",
+	"perfil.aviso_excluir":  "As configurações, os recordes e as conquistas dele serão apagados.",
+	"perfil.sim":            "Sim, excluir",
+	"perfil.nao":            "Não",
+	"perfil.excluido":       "Perfil %s excluído.",
+
+	// configuração de teclas
+	"teclas.titulo":       "CONFIGURAR TECLAS",
+	"teclas.aguardando":   "pressione a nova tecla...",
+	"teclas.ajuda":        "↑/↓ escolher  Enter trocar  Esc salvar e sair",
+	"teclas.salvas":       "Teclas salvas em %s",
+	"teclas.erro":         "Não foi possível salvar as teclas: %v",
+	"teclas.invalida":     "Tecla não ligada: %v",
+	"acao.mover_cima":     "Mover para cima",
+	"acao.mover_esquerda": "Mover para a esquerda",
+	"acao.mover_baixo":    "Mover para baixo",
+	"acao.mover_direita":  "Mover para a direita",
+	"acao.interagir":      "Interagir",
+	"acao.atacar":         "Atacar",
+	"acao.usar_item":      "Usar item",
+	"acao.pausar":         "Pausar",
+	"acao.salvar":         "Salvar",
+	"acao.desfazer":       "Desfazer (quebra-cabeça)",
+	"acao.teclas":         "Configurar teclas",
+	"acao.missoes":        "Registro de missões",
+	"acao.historico":      "Histórico de mensagens",
+	"acao.depuracao":      "Depuração",
+	"acao.sair":           "Sair",
+
+	// rede
+	"rede.aguardando":    "Aguardando o segundo jogador em %s",
+	"rede.entrou":        "Jogador %d entrou na partida!",
+	"rede.desconectou":   "Jogador %d desconectou.",
+	"rede.perdida":       "Conexão com o anfitrião perdida!",
+	"rede.encerrada":     "Partida encerrada: %s",
+	"rede.versao":        "versão do protocolo incompatível (anfitrião %d, cliente %d)",
+	"rede.cheia":         "a partida já está cheia",
+	"rede.fim_anfitriao": "o anfitrião encerrou a partida",
+}
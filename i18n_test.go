@@ -0,0 +1,54 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Todos os catálogos têm os identificadores do catálogo padrão, com os mesmos argumentos
+func TestCatalogos(t *testing.T) {
+	for _, problema := range i18nVerificar() {
+		t.Error(problema)
+	}
+}
+
+// A verificação encontra identificadores que faltam, que sobram e com argumentos diferentes
+func TestI18nVerificarProblemas(t *testing.T) {
+	originais := catalogos
+	t.Cleanup(func() { catalogos = originais })
+	catalogos = map[string]Catalogo{
+		IdiomaPadrao: {
+			"a":      "%d tesouros em %s",
+			"b":      "100%% aberto",
+			"c":      "sem argumentos",
+			"d.um":   "um tesouro",
+			"d":      "%d tesouros",
+			"e.um":   "%d tesouro",
+			"e":      "%d tesouros",
+			"faltou": "texto",
+		},
+		"xx": {
+			"a":     "%d treasures", // um argumento a menos
+			"b":     "100%% open",   // "%%" não é argumento
+			"c":     "with %s",      // um argumento a mais
+			"d.um":  "one treasure", // o singular pode omitir o número
+			"d":     "%d treasures",
+			"e.um":  "%d treasure %s", // mas não pode ter argumentos a mais
+			"e":     "%d treasures",
+			"sobra": "text",
+		},
+	}
+
+	problemas := i18nVerificar()
+	esperados := []string{`xx: "a" usa 1 argumentos`, `xx: "c" usa 1 argumentos`, `xx: "e.um" usa 2 argumentos`,
+		`xx: falta "faltou"`, `xx: "sobra" não existe`}
+	for _, e := range esperados {
+		if !slices.ContainsFunc(problemas, func(p string) bool { return strings.HasPrefix(p, e) }) {
+			t.Errorf("problema %q não encontrado em %q", e, problemas)
+		}
+	}
+	if len(problemas) != len(esperados) {
+		t.Errorf("%d problemas encontrados, esperados %d: %q", len(problemas), len(esperados), problemas)
+	}
+}
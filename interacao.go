@@ -0,0 +1,74 @@
+// interacao.go - Com qual entidade o jogador interage
+// A tecla de interação age sobre uma entidade Interativa (caixa, Guian, porta, alavanca)
+// ao redor do jogador: primeiro a da célula para onde ele está virado, depois a mais
+// próxima. O renderizador destaca essa entidade para o jogador saber o que vai acontecer.
+package main
+
+import (
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Entidade com que o jogador interage agora, se houver alguma ao alcance
+func jogoAlvoInteracao(jogo *Jogo, j *Jogador) (IDEntidade, bool) {
+	o := jogo.Ocupantes
+	if dx, dy := j.Direcao.Delta(); dx != 0 || dy != 0 {
+		for _, id := range o.Em(j.X+dx, j.Y+dy) {
+			if _, ok := o.Interativos[id]; ok {
+				return id, true
+			}
+		}
+	}
+
+	// no empate vence a entidade criada primeiro (NoRaio já vem nessa ordem)
+	alvo, menor := IDEntidade(0), -1
+	for _, id := range o.NoRaio(j.X, j.Y, 1) {
+		if _, ok := o.Interativos[id]; !ok {
+			continue
+		}
+		p := o.Posicoes[id]
+		if d := calculaDistancia(p.X, p.Y, j.X, j.Y); menor < 0 || d < menor {
+			alvo, menor = id, d
+		}
+	}
+	return alvo, menor >= 0
+}
+
+// Interage com o alvo do jogador; retorna false se não havia nada ao alcance
+func jogoInteragir(jogo *Jogo, j *Jogador) bool {
+	id, ok := jogoAlvoInteracao(jogo, j)
+	if !ok {
+		return false
+	}
+	jogo.Ocupantes.Interativos[id].Interagir(jogo, j)
+	return true
+}
+
+// Interação com uma caixa: abre a caixa
+func (c *Caixa) interagir(jogo *Jogo, j *Jogador) {
+	jogo.SetMessage(tr("jogo.interagiu"), 2*time.Second)
+	c.efeito(jogo, j) // abre a caixa e encerra a IA dela
+}
+
+// Destaca o alvo da interação de cada jogador local
+func interfaceDestacarAlvos(jogo *Jogo) {
+	if jogo.FimDeJogo {
+		return
+	}
+	for _, j := range jogo.Jogadores {
+		if j.Remoto {
+			continue
+		}
+		id, ok := jogoAlvoInteracao(jogo, j)
+		if !ok {
+			continue
+		}
+		p, d := jogo.Ocupantes.Posicoes[id], jogo.Ocupantes.Desenhos[id]
+		if d == nil {
+			continue
+		}
+		e := temaElemento(d.Elemento)
+		termbox.SetCell(p.X, p.Y, e.simbolo, e.cor, CorFundoSelecao)
+	}
+}
@@ -6,8 +6,11 @@
 package main
 
 import (
-	"github.com/nsf/termbox-go"
 	"fmt"
+	"github.com/nsf/termbox-go"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // Define um tipo Cor para encapsuladar as cores do termbox
@@ -21,22 +24,54 @@ const (
 	CorVerde           = termbox.ColorGreen
 	CorParede          = termbox.ColorBlack | termbox.AttrBold | termbox.AttrDim
 	CorFundoParede     = termbox.ColorDarkGray
-	CorTexto           = termbox.ColorDarkGray
 	CorAmarela         = termbox.ColorYellow
 	CorRoxa            = termbox.ColorMagenta
 )
 
-// EventoTeclado representa uma ação detectada do teclado (como mover, sair ou interagir)
-type EventoTeclado struct {
-	Tipo  string // "sair", "interagir", "mover"
-	Tecla rune   // Tecla pressionada, usada no caso de movimento
+// Cores da interface (textos, títulos e opção selecionada dos menus); o tema pode trocá-las
+var (
+	CorTexto        = CorCinzaEscuro
+	CorTitulo       = CorAmarela
+	CorSelecao      = CorPadrao
+	CorFundoSelecao = CorCinzaEscuro
+)
+
+// Cores padrão da interface, usadas pelo tema nos papéis que ele não muda
+var coresInterfacePadrao = map[*Cor]Cor{
+	&CorTexto:        CorTexto,
+	&CorTitulo:       CorTitulo,
+	&CorSelecao:      CorSelecao,
+	&CorFundoSelecao: CorFundoSelecao,
 }
 
 // Inicializa a interface gráfica usando termbox
-func interfaceIniciar() {
+func interfaceIniciar() error {
 	if err := termbox.Init(); err != nil {
-		panic(err)
+		return err
 	}
+	// Esc chega como uma tecla própria. O termbox não consegue ler Esc e Alt ao mesmo
+	// tempo (com os dois modos, desliga o Alt), então não há atalhos com Alt.
+	termbox.SetInputMode(termbox.InputEsc)
+	return nil
+}
+
+// Pede ao termbox o modo de cores e retorna o que ele de fato vai usar
+// (no Windows, por exemplo, só há as cores básicas)
+func interfaceModoCores(modo ModoCores) ModoCores {
+	pedido := termbox.OutputNormal
+	switch modo {
+	case Cores256:
+		pedido = termbox.Output256
+	case CoresTrueColor:
+		pedido = termbox.OutputRGB
+	}
+	switch termbox.SetOutputMode(pedido) {
+	case termbox.Output256:
+		return Cores256
+	case termbox.OutputRGB:
+		return CoresTrueColor
+	}
+	return Cores8
 }
 
 // Encerra o uso da interface termbox
@@ -44,19 +79,26 @@ func interfaceFinalizar() {
 	termbox.Close()
 }
 
-// Lê um evento do teclado e o traduz para um EventoTeclado
-func interfaceLerEventoTeclado() EventoTeclado {
+// Lê um evento do teclado e o traduz para uma ação usando as ligações de teclas
+func interfaceLerAcao(teclas *MapaTeclas) Acao {
+	atalho, ok := interfaceLerTecla()
+	if !ok {
+		return Acao{}
+	}
+	return teclas.Traduzir(atalho)
+}
+
+// Lê a próxima tecla pressionada, sem traduzi-la para uma ação
+func interfaceLerTecla() (AtalhoTecla, bool) {
 	ev := termbox.PollEvent()
 	if ev.Type != termbox.EventKey {
-		return EventoTeclado{}
-	}
-	if ev.Key == termbox.KeyEsc {
-		return EventoTeclado{Tipo: "sair"}
+		return AtalhoTecla{}, false
 	}
-	if ev.Ch == 'e' {
-		return EventoTeclado{Tipo: "interagir"}
+	atalho := AtalhoTecla{Ch: ev.Ch}
+	if ev.Ch == 0 {
+		atalho.Tecla = ev.Key
 	}
-	return EventoTeclado{Tipo: "mover", Tecla: ev.Ch}
+	return atalho, true
 }
 
 // Renderiza todo o estado atual do jogo na tela
@@ -70,21 +112,28 @@ func interfaceDesenharJogo(jogo *Jogo) {
 		}
 	}
 
-	// Desenha o NPC antes do personagem
-    if jogo.Guian != nil {
-        npcDesenhar(jogo, jogo.Guian)
-    }
+	// Avança as animações e desenha as que estão presas às células
+	jogo.Animacoes.Avancar(time.Now())
+	jogo.Animacoes.DesenharCelulas()
 
-    // Desenha o personagem
-    interfaceDesenharElemento(jogo.PosX, jogo.PosY, Personagem)
+	// Desenha as entidades (caixas, Guian, personagens, monstro) por cima do terreno
+	sistemaDesenhar(jogo)
+	interfaceDestacarAlvos(jogo)
 
-    // Desenha o monstro se estiver ativo
-    if jogo.MonstroAtivo {
-        interfaceDesenharElemento(jogo.Monstro.X, jogo.Monstro.Y, MonstroElemento)
-    }
-
-	// Desenha a barra de status
+	// Desenha a barra de status e, no canto, as mensagens em destaque (conquistas)
 	interfaceDesenharBarraDeStatus(jogo)
+	interfaceDesenharDestaques(jogo)
+
+	// Com F3, as informações de depuração ficam por cima do mapa
+	jogo.Depuracao.Quadro(time.Now())
+	if jogo.Depuracao.Ativa() {
+		interfaceDesenharDepuracao(jogo)
+	}
+
+	// Desenha as telas abertas (menus) por cima de tudo, da mais antiga para a mais nova
+	for _, t := range jogo.Telas {
+		t.Desenhar(jogo)
+	}
 
 	// Força a atualização do terminal
 	interfaceAtualizarTela()
@@ -100,22 +149,27 @@ func interfaceAtualizarTela() {
 	termbox.Flush()
 }
 
-// Desenha um elemento na posição (x, y)
+// Desenha um elemento na posição (x, y), com a aparência do tema atual
 func interfaceDesenharElemento(x, y int, elem Elemento) {
+	elem = temaElemento(elem)
 	termbox.SetCell(x, y, elem.simbolo, elem.cor, elem.corFundo)
 }
 
-// Exibe uma barra de status com informações úteis ao jogador
-func interfaceDesenharBarraDeStatus(jogo *Jogo) {
-	// Linha de status dinâmica
-	for i, c := range jogo.StatusMsg {
-		termbox.SetCell(i, len(jogo.Mapa)+1, c, CorTexto, CorPadrao)
+// Desenha um elemento como ele aparece num quadro de animação
+func interfaceDesenharQuadro(x, y int, elem Elemento, q Quadro) {
+	if q.Oculto {
+		return
 	}
+	elem = q.aplicar(elem)
+	termbox.SetCell(x, y, elem.simbolo, elem.cor, elem.corFundo)
+}
 
-	// Instruções fixas
-	msg := "."
-	for i, c := range msg {
-		termbox.SetCell(i, len(jogo.Mapa)+3, c, CorTexto, CorPadrao)
+// Exibe uma barra de status com informações úteis ao jogador
+func interfaceDesenharBarraDeStatus(jogo *Jogo) {
+	// Mensagens ativas, das mais importantes para as menos importantes
+	linhas, cores := mensagensLinhasStatus(jogo, LinhasStatus)
+	for i, l := range linhas {
+		interfaceEscrever(0, len(jogo.Mapa)+1+i, l, cores[i], CorPadrao)
 	}
 
 	// Exibe a mensagem de tesouros encontrados abaixo das instruções
@@ -123,13 +177,41 @@ func interfaceDesenharBarraDeStatus(jogo *Jogo) {
 }
 
 func exibirMensagemTesouros(jogo *Jogo) {
+	// antes da partida começar (na tela de título) não há o que mostrar
+	if len(jogo.Mapa) == 0 {
+		return
+	}
 	larguraTotal, alturaTotal := termbox.Size()
 
 	linhas := []string{
 		"****************************************",
-		"Encontre os 4 tesouros escondidos no mapa!",
-		fmt.Sprintf("TESOUROS ENCONTRADOS: %d/4", jogo.Tesouros),
+		trN("placar.meta", jogo.MetaTesouros, jogo.MetaTesouros),
+		tr("placar.encontrados", jogo.Tesouros, jogo.MetaTesouros),
 		"****************************************",
+		tr("placar.pontos", jogoPontosTotal(jogo)),
+	}
+	if jogo.Disputa == DisputaCompetitiva {
+		linhas[1] = trN("placar.competitivo", jogo.MetaTesouros, jogo.MetaTesouros)
+		linhas[2] = ""
+	}
+	// com objetivos próprios do mapa, mostra o próximo a cumprir
+	if jogo.Missoes != nil {
+		if o := jogo.Missoes.Pendente(); o != nil && o.Tipo != ObjetivoTesouros {
+			linhas[1] = tr("placar.objetivo", o.Texto(jogo))
+		}
+	}
+
+	// com mais de um jogador o placar é dividido, um bloco por jogador
+	if len(jogo.Jogadores) > 1 {
+		if jogo.Disputa == DisputaCompetitiva {
+			linhas[2] = interfacePlacarDividido(jogo)
+		} else {
+			linhas = append(linhas[:3], interfacePlacarDividido(jogo), linhas[3], linhas[4])
+		}
+	}
+	// o relógio do contra o relógio ou a onda da sobrevivência (modo.go)
+	if placar := jogo.Modo.Placar(jogo); placar != "" {
+		linhas = append(linhas, placar)
 	}
 
 	linhaInicial := len(jogo.Mapa) + 5
@@ -149,3 +231,138 @@ func exibirMensagemTesouros(jogo *Jogo) {
 		}
 	}
 }
+
+// Monta uma linha com os tesouros de cada jogador lado a lado
+func interfacePlacarDividido(jogo *Jogo) string {
+	placar := ""
+	for i, j := range jogo.Jogadores {
+		if i > 0 {
+			placar += "  |  "
+		}
+		placar += fmt.Sprintf("J%d %c %d/%d", j.ID+1, j.Elemento.simbolo, j.Tesouros, jogo.MetaTesouros)
+	}
+	return placar
+}
+
+// Escreve um texto a partir da posição (x, y)
+func interfaceEscrever(x, y int, texto string, cor, fundo Cor) {
+	for _, c := range texto {
+		termbox.SetCell(x, y, c, cor, fundo)
+		x++
+	}
+}
+
+// Desenha as mensagens em destaque em quadros empilhados no canto superior direito
+func interfaceDesenharDestaques(jogo *Jogo) {
+	larguraTela, _ := termbox.Size()
+	y0 := 0
+	for _, m := range mensagensDestaques(jogo) {
+		linhas := m.Linhas()
+		largura := 0
+		for _, l := range linhas {
+			largura = max(largura, utf8.RuneCountInString(l))
+		}
+		largura += 4
+		altura := len(linhas) + 2
+		x0 := max(larguraTela-largura-1, 0)
+		cor := coresPrioridade[m.Prioridade]
+
+		// fundo e borda do quadro
+		for y := y0; y < y0+altura; y++ {
+			for x := x0; x < x0+largura; x++ {
+				c := ' '
+				if y == y0 || y == y0+altura-1 {
+					c = '─'
+				}
+				termbox.SetCell(x, y, c, cor, CorPadrao)
+			}
+		}
+		for i, l := range linhas {
+			interfaceEscrever(x0+2, y0+1+i, l, cor, CorPadrao)
+		}
+		y0 += altura
+	}
+}
+
+// Desenha o menu de configuração de teclas em uma caixa no canto da tela
+func interfaceDesenharMenuTeclas(menu *MenuTeclas) {
+	itens := menu.Itens()
+	largura, altura := 50, len(itens)+5
+	x0, y0 := 2, 1
+
+	// fundo da caixa
+	for y := y0; y < y0+altura; y++ {
+		for x := x0; x < x0+largura; x++ {
+			termbox.SetCell(x, y, ' ', CorPadrao, CorPadrao)
+		}
+	}
+
+	interfaceEscrever(x0+1, y0, tr("teclas.titulo"), CorTitulo, CorPadrao)
+	for i, item := range itens {
+		cor, fundo := CorTexto, CorPadrao
+		if i == menu.Selecionada {
+			cor, fundo = CorSelecao, CorFundoSelecao
+		}
+		teclas := strings.Join(menu.Teclas.TeclasDe(item.Acao, item.Jogador), ", ")
+		if i == menu.Selecionada && menu.Aguardando {
+			teclas = tr("teclas.aguardando")
+		}
+		linha := fmt.Sprintf("J%d %-22s %s", item.Jogador, item.Descricao, teclas)
+		interfaceEscrever(x0+1, y0+2+i, linha, cor, fundo)
+	}
+	interfaceEscrever(x0+1, y0+altura-2, tr("teclas.ajuda"), CorTexto, CorPadrao)
+}
+
+// Desenha um menu em uma caixa centralizada na tela
+func interfaceDesenharMenu(menu *Menu) {
+	linhas := []string{menu.Titulo, ""}
+	linhas = append(linhas, menu.Linhas...)
+	if len(menu.Linhas) > 0 {
+		linhas = append(linhas, "")
+	}
+	primeiraOpcao := len(linhas)
+	for _, o := range menu.Opcoes {
+		linhas = append(linhas, "  "+o.String()+"  ")
+	}
+
+	largura := 0
+	for _, l := range linhas {
+		if n := utf8.RuneCountInString(l); n > largura {
+			largura = n
+		}
+	}
+	largura += 4
+	altura := len(linhas) + 2
+
+	larguraTela, alturaTela := termbox.Size()
+	x0, y0 := (larguraTela-largura)/2, (alturaTela-altura)/2
+	if x0 < 0 {
+		x0 = 0
+	}
+	if y0 < 0 {
+		y0 = 0
+	}
+
+	// fundo e borda da caixa
+	for y := y0; y < y0+altura; y++ {
+		for x := x0; x < x0+largura; x++ {
+			c := ' '
+			if y == y0 || y == y0+altura-1 {
+				c = '─'
+			}
+			termbox.SetCell(x, y, c, CorTexto, CorPadrao)
+		}
+	}
+
+	for i, l := range linhas {
+		cor, fundo := CorTexto, CorPadrao
+		switch {
+		case i == 0:
+			cor = CorTitulo
+		case i-primeiraOpcao == menu.Selecionada:
+			cor, fundo = CorSelecao, CorFundoSelecao
+		}
+		x := x0 + (largura-utf8.RuneCountInString(l))/2
+		interfaceEscrever(x, y0+1+i, l, cor, fundo)
+	}
+}
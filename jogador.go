@@ -0,0 +1,93 @@
+// jogador.go - Estado dos jogadores presentes na partida (local ou pela rede)
+package main
+
+// Jogador representa um personagem controlado por uma pessoa
+type Jogador struct {
+	ID          int        // índice do jogador na partida (0 = anfitrião)
+	*Posicao               // posição atual do personagem
+	*Inventario            // tesouros encontrados e itens carregados (recebidos do Guian)
+	Ocupante    IDEntidade // entidade do personagem no mapa (ecs.go)
+	Vida        *Vida      // sem pontos de vida, o jogador perde a partida
+	Direcao     Direcao    // para onde o personagem está virado (o último passo)
+	Elemento    Elemento   // como o personagem aparece no mapa
+	Remoto      bool       // indica se o jogador está conectado pela rede
+}
+
+// Pontos de vida de cada jogador no começo da partida
+const JogadorVida = 1
+
+// Aparência de cada jogador, na ordem em que entram na partida
+var elementosJogadores = []Elemento{Personagem, Personagem2}
+
+// Cria um jogador na posição (x, y) usando a aparência correspondente ao seu índice,
+// registrando o personagem como entidade do mapa
+func jogadorNovo(jogo *Jogo, id, x, y int) *Jogador {
+	j := &Jogador{
+		ID:         id,
+		Posicao:    &Posicao{x, y},
+		Inventario: &Inventario{},
+		Vida:       vidaNova(JogadorVida),
+		Direcao:    DirecaoBaixo,
+		Elemento:   elementosJogadores[id%len(elementosJogadores)],
+	}
+	o := jogo.Ocupantes
+	j.Ocupante = o.Criar()
+	o.Colocar(j.Ocupante, j.Posicao)
+	o.Donos[j.Ocupante] = j
+	o.Desenhos[j.Ocupante] = &Desenhavel{j.Elemento, CamadaJogador, Entidade{"jogador", id}}
+	o.Bloqueantes[j.Ocupante] = Bloqueante{}
+	o.Inventarios[j.Ocupante] = j.Inventario
+	o.Vidas[j.Ocupante] = j.Vida
+	return j
+}
+
+// Adiciona um novo jogador em uma posição livre próxima ao primeiro jogador
+func jogoAdicionarJogador(jogo *Jogo) *Jogador {
+	x, y := 0, 0
+	if len(jogo.Jogadores) > 0 {
+		x, y = jogo.Jogadores[0].X, jogo.Jogadores[0].Y
+	}
+	if nx, ny, ok := jogoPosicaoLivreProxima(jogo, x, y); ok {
+		x, y = nx, ny
+	}
+
+	j := jogadorNovo(jogo, len(jogo.Jogadores), x, y)
+	jogo.Jogadores = append(jogo.Jogadores, j)
+	return j
+}
+
+// Remove o jogador e seu personagem da partida
+func jogoRemoverJogador(jogo *Jogo, j *Jogador) {
+	i := jogoJogadorIndice(jogo, j)
+	if i < 0 {
+		return
+	}
+	jogo.Ocupantes.Remover(j.Ocupante)
+	jogo.Jogadores = append(jogo.Jogadores[:i], jogo.Jogadores[i+1:]...)
+}
+
+// Retorna a posição do jogador na lista de jogadores, ou -1 se ele não está na partida
+func jogoJogadorIndice(jogo *Jogo, j *Jogador) int {
+	for i, outro := range jogo.Jogadores {
+		if outro == j {
+			return i
+		}
+	}
+	return -1
+}
+
+// Retorna o jogador mais próximo da posição (x, y), ou nil se não houver jogadores
+func jogoJogadorMaisProximo(jogo *Jogo, x, y int) *Jogador {
+	j, _ := ocupanteMaisProximo[*Jogador](jogo.Ocupantes, x, y, nil)
+	return j
+}
+
+// Recoloca na partida um jogador que já existia (por exemplo, um jogador remoto depois de reiniciar)
+func jogoRecolocarJogador(jogo *Jogo, j *Jogador) {
+	novo := jogoAdicionarJogador(jogo)
+	novo.Remoto = j.Remoto
+	// quem guardou j (a conexão do jogador remoto) continua com ele: j toma o lugar de novo
+	*j = *novo
+	jogo.Jogadores[len(jogo.Jogadores)-1] = j
+	jogo.Ocupantes.Donos[j.Ocupante] = j
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+// O jogador recolocado é o dono da sua entidade, e não a cópia usada para criá-la
+func TestRecolocarJogador(t *testing.T) {
+	jogo := jogoNovo()
+	if err := jogoCarregarMapa("mapa.txt", &jogo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogoEncerrarEntidades(&jogo) })
+
+	remoto := jogoAdicionarJogador(&jogo)
+	remoto.Remoto = true
+	jogoRemoverJogador(&jogo, remoto)
+	jogoRecolocarJogador(&jogo, remoto)
+
+	if i := jogoJogadorIndice(&jogo, remoto); i < 0 {
+		t.Fatal("jogador recolocado fora da lista de jogadores")
+	}
+	if dono := jogo.Ocupantes.Donos[remoto.Ocupante]; dono != remoto {
+		t.Errorf("dono da entidade %d é %p, esperado %p", remoto.Ocupante, dono, remoto)
+	}
+	if !remoto.Remoto {
+		t.Error("o jogador recolocado deixou de ser remoto")
+	}
+	if j := jogoJogadorMaisProximo(&jogo, remoto.X, remoto.Y); j != remoto {
+		t.Errorf("jogador mais próximo de (%d, %d) não é o recolocado", remoto.X, remoto.Y)
+	}
+}
@@ -3,50 +3,91 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"fmt"
 	"math/rand"
-	"jogo/util"
-	"sync"
 	"os"
+	"strings"
 	"time"
 )
 
 // Elemento representa qualquer objeto do mapa (parede, personagem, vegetação, etc)
 type Elemento struct {
-	simbolo  rune   // símbolo que vai aparecer no mapa
-	cor      Cor    // cor do símbolo
-	corFundo Cor    // cor do fundo
-	tangivel bool   // se for true, não dá pra passar por cima
+	simbolo  rune // símbolo que vai aparecer no mapa
+	cor      Cor  // cor do símbolo
+	corFundo Cor  // cor do fundo
+	tangivel bool // se for true, não dá pra passar por cima
 }
 
 // Jogo contém o estado atual do jogo
 type Jogo struct {
-	Mapa           [][]Elemento // grade 2D representando o mapa
-	PosX, PosY     int          // posição atual do personagem
-	UltimoVisitado Elemento     // elemento que estava na posição do personagem antes de mover
-	StatusMsg      string       // mensagem para a barra de status
-    MsgExpira      time.Time     // quando a mensagem expira
-    MsgMutex       sync.Mutex    // protege o acesso às mensagens
-	Guian          *NPCGuian    // referência ao NPC guia
-	Monstro        *Monstro     // referência ao monstro
-    MonstroAtivo   bool        // indica se o monstro está ativo 
-	MonstroSpawn   time.Time    // quando o monstro vai aparecer
-	FimDeJogo      bool         // indica se o jogador finalizou o jogo
-	Tesouros       int          //quantidade de tesouros coletados
-	Caixas         []*Caixa     // lista de caixas no mapa
-	MutexMapa      *sync.Mutex  // mutex para proteger o acesso ao mapa
+	Mapa         [][]Elemento       // grade 2D representando o mapa
+	Jogadores    []*Jogador         // jogadores na partida (o primeiro é o jogador local)
+	Mensagens    *RegistroMensagens // mensagens da barra de status e o histórico
+	Animacoes    *Animacoes         // animações desenhadas pelo renderizador
+	Guian        *NPCGuian          // referência ao NPC guia
+	Monstro      *Monstro           // referência ao monstro
+	MonstroAtivo bool               // indica se o monstro está ativo
+	MonstroSpawn time.Time          // quando o monstro vai aparecer
+	FimDeJogo    bool               // indica se o jogador finalizou o jogo
+	Tesouros     int                //quantidade de tesouros coletados
+	MetaTesouros int                // tesouros necessários para vencer
+	Pontos       int                // pontos feitos no nível atual (campanha.go)
+	Campanha     *ProgressoCampanha // campanha em andamento (nil: um mapa só)
+	Estatisticas *Estatisticas      // o que aconteceu na partida atual (recordes.go)
+	Recordes     string             // arquivo da tabela de recordes ("": a partida não entra nela)
+	Conquistas   *Conquistas        // conquistas e progresso do jogador (nil: não são conferidas)
+	Disputa      Disputa            // se os jogadores cooperam ou competem
+	Modo         ModoJogo           // como a partida é vencida e perdida (modo.go)
+	Caixas       []*Caixa           // lista de caixas no mapa
+	Ocupantes    *Ocupantes         // componentes das entidades que ocupam o mapa (ecs.go)
+	Mundo        *Mundo             // intenções e retratos trocados com o dono do estado (mundo.go)
+	Teclas       *MapaTeclas        // ligações entre teclas e ações
+	Dialogos     *Dialogos          // conversas com o Guian
+	SemConversas bool               // reproduções e partidas sem tela: o Guian só dá a dica
+	Missoes      *Missoes           // objetivos da partida atual
+	Eventos      *Barramento        // eventos publicados pelas entidades
+	MensagemFim  string             // motivo do fim da partida, exibido na tela final
+	Pausa        *Pausa             // congela as entidades enquanto o jogo está pausado
+	Entidades    *Supervisor        // goroutines das entidades da partida (supervisor.go)
+	Telas        []Tela             // telas sobrepostas ao jogo (menus), a última fica por cima
+	Vitoria      bool               // se o jogo terminou com a vitória dos jogadores
+	QuebraCabeca bool               // modo quebra-cabeça: permite desfazer movimentos
+	Historico    []Comando          // comandos executados, para desfazer no modo quebra-cabeça
+	Gravador     *GravadorComandos  // grava as ações dos jogadores, se ativado
+	Configuracao *Configuracao      // tempos e quantidades da dificuldade escolhida
+	Semente      int64              // semente do sorteio de cada partida (0: uma nova a cada vez)
+	Depuracao    *Depuracao         // sobreposição de depuração (F3)
+}
+
+// Disputa define como os jogadores de uma mesma partida se relacionam
+type Disputa int
+
+const (
+	DisputaCooperativa Disputa = iota // tesouros compartilhados, todos vencem juntos
+	DisputaCompetitiva                // vence o primeiro a alcançar a meta de tesouros
+)
+
+// Nome da disputa na linha de comando (-disputa)
+func (d Disputa) Nome() string {
+	if d == DisputaCompetitiva {
+		return "competitivo"
+	}
+	return "coop"
 }
 
 // Elementos visuais do jogo
 var (
-	Personagem           = Elemento{'☺', CorCinzaEscuro, CorPadrao, true}
-	MonstroElemento      = Elemento{'¥', CorVermelho, CorPadrao, true}
-	Parede               = Elemento{'▤', CorParede, CorFundoParede, true}
-	Vegetacao            = Elemento{'♣', CorVerde, CorPadrao, false}
-	Vazio                = Elemento{' ', CorPadrao, CorPadrao, false}
+	Personagem      = Elemento{'☺', CorCinzaEscuro, CorPadrao, true}
+	Personagem2     = Elemento{'☻', CorAmarela, CorPadrao, true}
+	MonstroElemento = Elemento{'¥', CorVermelho, CorPadrao, true}
+	Parede          = Elemento{'▤', CorParede, CorFundoParede, true}
+	Vegetacao       = Elemento{'♣', CorVerde, CorPadrao, false}
+	Vazio           = Elemento{' ', CorPadrao, CorPadrao, false}
 
-	CaixaElemento        = Elemento{'■', CorAmarela, CorPadrao, true} // caixa fechada
+	CaixaElemento = Elemento{'■', CorAmarela, CorPadrao, true} // caixa fechada
 
-	// para animar as caixas coloridinhas após abrir
+	// caixas abertas, mostradas pela animação antes de sumirem
 	CaixaTesouroAberta   = Elemento{'■', CorVerde, CorPadrao, false}
 	CaixaArmadilhaAberta = Elemento{'■', CorVermelho, CorPadrao, false}
 	CaixaVaziaAberta     = Elemento{'■', CorCinzaEscuro, CorPadrao, false}
@@ -54,29 +95,80 @@ var (
 
 // Cria e retorna uma nova instância do jogo
 func jogoNovo() Jogo {
-	// O ultimo elemento visitado é inicializado como vazio
-	// pois o jogo começa com o personagem em uma posição vazia
-	return Jogo{
-		UltimoVisitado: Vazio,
-		MutexMapa:      &sync.Mutex{},
-        MonstroSpawn:   time.Now().Add(30 * time.Second), // monstro aparece após 30 segundos
+	config := configuracaoPadrao()
+	jogo := Jogo{
+		Mundo:        mundoNovo(),
+		Ocupantes:    ocupantesNovos(),
+		Pausa:        pausaNova(),
+		Eventos:      barramentoNovo(),
+		Mensagens:    registroMensagensNovo(),
+		Animacoes:    animacoesNovas(),
+		Estatisticas: &Estatisticas{},
+		Depuracao:    &Depuracao{},
+		Configuracao: config,
+		MonstroSpawn: time.Now().Add(time.Duration(config.MonstroSurge)), // monstro aparece depois de um tempo
+		MetaTesouros: config.Meta,
+		Modo:         &ModoClassico{},
+	}
+	jogoNovasEntidades(&jogo)
+	return jogo
+}
+
+// Cria o supervisor das goroutines da próxima partida. Se uma delas falhar, as outras
+// param e o jogador é avisado; o loop principal então encerra a partida.
+func jogoNovasEntidades(jogo *Jogo) {
+	jogo.Entidades = supervisorNovo(context.Background())
+	jogo.Entidades.AoFalhar = func(nome string, err error) {
+		jogo.Notificar(tr("supervisor.falha", nome), PrioridadeCritica, 10*time.Second)
 	}
 }
 
+// Cancela as goroutines da partida atual e espera que terminem. Deve ser chamada antes
+// de jogoLimpar, para nenhuma intenção delas alcançar o mapa da partida seguinte.
+func jogoEncerrarEntidades(jogo *Jogo) error {
+	jogo.Entidades.cancelar()
+	jogo.Pausa.Retomar() // libera quem estava esperando a pausa para que veja o cancelamento
+	return jogo.Entidades.Encerrar()
+}
+
 func atualizarJogo(jogo *Jogo) {
-    // Spawn do monstro
-    if !jogo.MonstroAtivo && time.Now().After(jogo.MonstroSpawn) {
-        jogo.Monstro = monstroNovo()
-        jogo.Monstro.Iniciar(jogo)
-        jogo.MonstroAtivo = true
-        jogo.SetMessage("Um monstro apareceu no mapa!\nCorra para pegar todos os tesouros antes que ele roube...", 5*time.Second)
-    }
-    
-    // Atualizar monstro se estiver ativo
-    if jogo.MonstroAtivo {
-        jogo.Monstro.Atualizar(jogo)
-    }
+	// Spawn do monstro
+	if !jogo.MonstroAtivo && time.Now().After(jogo.MonstroSpawn) {
+		jogo.Monstro = jogo.Modo.Monstro(jogo)
+		jogo.Monstro.Iniciar(jogo)
+		jogo.MonstroAtivo = true
+		jogoPublicar(jogo, Evento{Tipo: EventoMonstroApareceu, X: jogo.Monstro.X, Y: jogo.Monstro.Y})
+	}
+
+	// Atualizar monstro se estiver ativo
+	if jogo.MonstroAtivo {
+		jogo.Monstro.Atualizar(jogo)
+	}
+}
+
+// O elemento que um caractere do arquivo de mapa representa; '☺' é a posição
+// inicial de um jogador, portas, alavancas e portais viram entidades e caracteres
+// desconhecidos são espaço vazio
+func elementoDoMapa(ch rune) Elemento {
+	switch ch {
+	case Parede.simbolo:
+		return Parede
+	case Vegetacao.simbolo:
+		return Vegetacao
+	case Personagem.simbolo:
+		return Personagem
+	case CaixaElemento.simbolo:
+		return CaixaElemento
+	case PortaFechada.simbolo:
+		return PortaFechada
+	case AlavancaDesligada.simbolo:
+		return AlavancaDesligada
+	case PortalFechado.simbolo:
+		return PortalFechado
+	}
+	return Vazio
 }
+
 // Lê um arquivo texto linha por linha e constrói o mapa do jogo
 func jogoCarregarMapa(nome string, jogo *Jogo) error {
 	arq, err := os.Open(nome)
@@ -90,17 +182,22 @@ func jogoCarregarMapa(nome string, jogo *Jogo) error {
 	for scanner.Scan() {
 		linha := scanner.Text()
 		var linhaElems []Elemento
-		for x, ch := range linha {
-			e := Vazio
-			switch ch {
-			case Parede.simbolo:
-				e = Parede
-			case Vegetacao.simbolo:
-				e = Vegetacao
-			case Personagem.simbolo:
-				jogo.PosX, jogo.PosY = x, y // registra a posição inicial do personagem
-			case CaixaElemento.simbolo:
-				e = CaixaElemento
+		for x, ch := range []rune(linha) { // x conta caracteres, não bytes
+			e := elementoDoMapa(ch)
+			switch e {
+			case Personagem:
+				// registra a posição inicial do personagem
+				jogo.Jogadores = append(jogo.Jogadores, jogadorNovo(jogo, len(jogo.Jogadores), x, y))
+				e = Vazio
+			case PortaFechada:
+				jogoAdicionarPorta(jogo, x, y)
+				e = Vazio
+			case AlavancaDesligada:
+				jogoAdicionarAlavanca(jogo, x, y)
+				e = Vazio
+			case PortalFechado:
+				jogoAdicionarPortal(jogo, x, y)
+				e = Vazio
 			}
 			linhaElems = append(linhaElems, e)
 		}
@@ -111,36 +208,74 @@ func jogoCarregarMapa(nome string, jogo *Jogo) error {
 		return err
 	}
 
-	// coloca o seed pra gerar números aleatórios diferentes toda vez que o jogo é iniciado
-	rand.Seed(time.Now().UnixNano())
-	numCaixas := 10 // número de caixas pra espalhar no mapa
+	// mapas sem '☺' começam com o personagem no primeiro espaço livre
+	if len(jogo.Jogadores) == 0 {
+		if _, _, ok := jogoPosicaoLivreProxima(jogo, 0, 0); !ok {
+			return fmt.Errorf("%s: nenhuma posição livre para o personagem", nome)
+		}
+		jogoAdicionarJogador(jogo)
+	}
+
+	// objetivos declarados para este mapa
+	missoes, err := missoesCarregar(nome, jogo)
+	if err != nil {
+		return err
+	}
+	jogo.Missoes = missoes
+	jogoIniciarRegras(jogo)
+
+	// coloca o seed pra gerar números aleatórios diferentes toda vez que o jogo é iniciado,
+	// a não ser que uma semente tenha sido escolhida (para repetir a mesma partida)
+	semente := jogo.Semente
+	if semente == 0 {
+		semente = time.Now().UnixNano()
+	}
+	rand.Seed(semente)
+	jogo.Estatisticas.Mapa = nome
+	jogo.Estatisticas.Semente = semente
+	numCaixas := jogo.Configuracao.Caixas // número de caixas pra espalhar no mapa
 	tipos := []TipoCaixa{VAZIA, TESOURO, ARMADILHA}
 
-	// agora espalha as caixas em lugares aleatórios que estão vazios
+	// as caixas só cabem nas posições livres; com mais caixas que isso o sorteio abaixo não terminaria
+	livres := 0
+	for y, linha := range jogo.Mapa {
+		for x := range min(len(linha), len(jogo.Mapa[0])) {
+			if linha[x] == Vazio && len(jogo.Ocupantes.Em(x, y)) == 0 {
+				livres++
+			}
+		}
+	}
+	if numCaixas > livres {
+		return fmt.Errorf("%s: %d caixas não cabem nas %d posições livres do mapa", nome, numCaixas, livres)
+	}
+
+	// agora espalha as caixas em lugares aleatórios que estão vazios (nem com terreno nem com outra entidade)
 	for colocadas := 0; colocadas < numCaixas; {
 		x := rand.Intn(len(jogo.Mapa[0])) // pega coluna aleatória
 		y := rand.Intn(len(jogo.Mapa))    // pega linha aleatória
 
-		if jogo.Mapa[y][x] == Vazio {
-			jogo.Mapa[y][x] = CaixaElemento
+		if x < len(jogo.Mapa[y]) && jogo.Mapa[y][x] == Vazio && len(jogo.Ocupantes.Em(x, y)) == 0 {
 			tipo := tipos[rand.Intn(len(tipos))] // escolhe um tipo de caixa (aleatoriamente)
-			caixa := &Caixa{
-				X:          x,
-				Y:          y,
-				Tipo:       tipo,
-				Mapa:       &jogo.Mapa,
-				Mutex:      jogo.MutexMapa,
-				Interacao:  make(chan bool),
-			}
-
-			caixa.Iniciar(jogo) // inicia a caixa
-			jogo.Caixas = append(jogo.Caixas, caixa) // adiciona na lista de caixas
+			jogoAdicionarCaixa(jogo, x, y, tipo)
 			colocadas++ // marca que colocou uma
 		}
 	}
 	return nil
 }
 
+// Coloca uma caixa do tipo informado em (x, y) e inicia sua goroutine
+func jogoAdicionarCaixa(jogo *Jogo, x, y int, tipo TipoCaixa) *Caixa {
+	caixa := &Caixa{
+		Posicao: &Posicao{x, y},
+		Tipo:    tipo,
+		desenho: &Desenhavel{Elemento: CaixaElemento, Camada: CamadaCaixa},
+	}
+
+	caixa.Iniciar(jogo)                      // registra a caixa como entidade e inicia sua IA
+	jogo.Caixas = append(jogo.Caixas, caixa) // adiciona na lista de caixas
+	return caixa
+}
+
 // Verifica se o personagem pode se mover para a posição (x, y)
 func jogoPodeMoverPara(jogo *Jogo, x, y int) bool {
 	// Verifica se a coordenada Y está dentro dos limites verticais do mapa
@@ -158,25 +293,42 @@ func jogoPodeMoverPara(jogo *Jogo, x, y int) bool {
 		return false
 	}
 
-	// Verifica se o elemento de destino é uma caixa (não pode passar por cima)
-	if jogo.MonstroAtivo && jogo.Monstro.X == x && jogo.Monstro.Y == y {
-        return false
-    }
+	// Caixas, jogadores e o monstro não dividem a célula com ninguém
+	if jogo.Ocupantes.Bloqueado(x, y) {
+		return false
+	}
 
 	// liberado pra andar
 	return true
 }
 
-// Move um elemento para a nova posição
-func jogoMoverElemento(jogo *Jogo, x, y, dx, dy int) {
-	nx, ny := x+dx, y+dy
-
-	// Obtem elemento atual na posição
-	elemento := jogo.Mapa[y][x] // guarda o conteúdo atual da posição
+// Procura a posição livre mais próxima de (x, y), testando anéis cada vez maiores
+func jogoPosicaoLivreProxima(jogo *Jogo, x, y int) (int, int, bool) {
+	// Direções possíveis para verificar (acima, direita, abaixo, esquerda)
+	direcoes := [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+	for _, dir := range direcoes {
+		nx, ny := x+dir[0], y+dir[1]
+		if jogoPodeMoverPara(jogo, nx, ny) {
+			return nx, ny, true
+		}
+	}
 
-	jogo.Mapa[y][x] = jogo.UltimoVisitado   // restaura o conteúdo anterior
-	jogo.UltimoVisitado = jogo.Mapa[ny][nx] // guarda o conteúdo atual da nova posição
-	jogo.Mapa[ny][nx] = elemento            // move o elemento
+	// Se nenhuma posição adjacente for válida, tenta em um raio maior
+	raioMaximo := len(jogo.Mapa)
+	if len(jogo.Mapa) > 0 && len(jogo.Mapa[0]) > raioMaximo {
+		raioMaximo = len(jogo.Mapa[0])
+	}
+	for r := 1; r <= raioMaximo; r++ {
+		for dx := -r; dx <= r; dx++ {
+			for dy := -r; dy <= r; dy++ {
+				nx, ny := x+dx, y+dy
+				if jogoPodeMoverPara(jogo, nx, ny) {
+					return nx, ny, true
+				}
+			}
+		}
+	}
+	return 0, 0, false
 }
 
 // versão especial pra impedir andar por cima das caixas
@@ -189,11 +341,8 @@ func jogoMoverComCaixa(jogo *Jogo, x, y int) bool {
 	}
 
 	// checa se tem alguma caixa bloqueando a passagem
-	for _, caixa := range jogo.Caixas {
-		if caixa.X == x && caixa.Y == y && !caixa.Removida {
-			jogo.StatusMsg = "Uma caixa bloqueia o caminho!"
-			return false
-		}
+	if jogoCaixaEm(jogo, x, y) != nil {
+		return false
 	}
 
 	if jogo.Mapa[y][x].tangivel {
@@ -202,36 +351,92 @@ func jogoMoverComCaixa(jogo *Jogo, x, y int) bool {
 	return true
 }
 
-// permite o jogador interagir com caixas que estão até 1 célula de distância
-func interagir(jogo *Jogo) {
-	jogo.MutexMapa.Lock() // trava o mapa pra ninguém mexer enquanto interage
-	defer jogo.MutexMapa.Unlock()
-
-	for _, caixa := range jogo.Caixas {
-		// checa se a caixa está próxima e não foi removida
-		if !caixa.Removida && util.Abs(caixa.X-jogo.PosX) <= 1 && util.Abs(caixa.Y-jogo.PosY) <= 1 {
-			caixa.Interacao <- true // manda o sinal pra caixa abrir
-			jogo.StatusMsg = "Você interagiu com a caixa!"
-			caixa.Removida = true // marca que a caixa foi removida
-			break
+// Retorna a caixa fechada na posição (x, y), ou nil se não houver
+func jogoCaixaEm(jogo *Jogo, x, y int) *Caixa {
+	for _, id := range jogo.Ocupantes.Em(x, y) {
+		if caixa, ok := jogo.Ocupantes.Donos[id].(*Caixa); ok {
+			return caixa
 		}
 	}
+	return nil
+}
+
+// Encerra a partida com vitória ou derrota
+func jogoEncerrar(jogo *Jogo, vitoria bool, mensagem string) {
+	if jogo.FimDeJogo {
+		return
+	}
+	jogo.FimDeJogo = true
+	jogo.Vitoria = vitoria
+	if vitoria {
+		jogo.Pontos += PontosNivel
+	}
+	jogo.MensagemFim = mensagem
+	jogoPublicar(jogo, Evento{Tipo: EventoFimDeJogo, Vitoria: vitoria, Mensagem: mensagem})
+}
+
+// Assina os eventos da partida atual para contar os pontos, as estatísticas e as
+// conquistas e aplicar as regras do modo de jogo (modo.go), que decide a vitória e a
+// derrota. A assinatura roda sob o supervisor e termina junto com a partida.
+func jogoIniciarRegras(jogo *Jogo) {
+	jogoAssinarPartida(jogo, "regras", func(ev Evento) {
+		jogo.Pontos += pontosDoEvento(ev)
+		estatisticasContar(jogo.Estatisticas, ev)
+		conquistasProcessar(jogo, ev)
+		jogo.Modo.Tratar(jogo, ev)
+		if ev.Tipo == EventoFimDeJogo {
+			// os eventos chegam em ordem: a partida já foi toda contada
+			jogoRegistrarRecorde(jogo)
+		}
+	})
 }
 
+// Registra uma mensagem comum na barra de status
 func (j *Jogo) SetMessage(msg string, duration time.Duration) {
-    j.MsgMutex.Lock()
-    defer j.MsgMutex.Unlock()
-    
-    j.StatusMsg = msg
-    j.MsgExpira = time.Now().Add(duration)
+	j.Notificar(msg, PrioridadeInfo, duration)
 }
 
+// Retorna as mensagens visíveis na barra de status, uma por linha ("" se não há nenhuma)
 func (j *Jogo) GetMessage() string {
-    j.MsgMutex.Lock()
-    defer j.MsgMutex.Unlock()
-    
-    if time.Now().After(j.MsgExpira) {
-        return ""
-    }
-    return j.StatusMsg
-}
\ No newline at end of file
+	var textos []string
+	for _, m := range j.Mensagens.Ativas(time.Now()) {
+		textos = append(textos, m.Texto)
+	}
+	return strings.Join(textos, "\n")
+}
+
+// Limpa o estado para uma nova partida; as goroutines da partida atual já devem ter
+// sido encerradas (jogoEncerrarEntidades). Os jogadores remotos são mantidos para
+// serem recolocados no novo mapa.
+func jogoLimpar(jogo *Jogo) []*Jogador {
+
+	var remotos []*Jogador
+	for _, j := range jogo.Jogadores {
+		if j.Remoto {
+			remotos = append(remotos, j)
+		}
+	}
+
+	jogo.Mapa = nil
+	jogo.Jogadores = nil
+	jogo.Caixas = nil
+	jogo.Ocupantes.Limpar()
+	jogo.Guian = nil
+	jogo.Monstro = nil
+	jogo.MonstroAtivo = false
+	jogo.MonstroSpawn = time.Now().Add(time.Duration(jogo.Configuracao.MonstroSurge))
+	jogo.Tesouros = 0
+	jogo.Pontos = 0
+	jogo.Estatisticas = &Estatisticas{}
+	jogo.FimDeJogo = false
+	jogo.Vitoria = false
+	jogo.MensagemFim = ""
+	jogo.Historico = nil
+	jogo.Missoes = nil
+	jogo.Telas = nil
+	jogoNovasEntidades(jogo)
+	jogoApagarRetrato(jogo)
+	jogo.Mensagens.Limpar()
+	jogo.Animacoes.Limpar()
+	return remotos
+}
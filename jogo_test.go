@@ -0,0 +1,19 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Mais caixas do que posições livres é um erro, não um sorteio que nunca termina
+func TestCarregarMapaCaixasDemais(t *testing.T) {
+	jogo := jogoNovo()
+	config := *jogo.Configuracao
+	config.Caixas = 5000
+	jogo.Configuracao = &config
+	err := jogoCarregarMapa("mapa.txt", &jogo)
+	jogoEncerrarEntidades(&jogo)
+	if err == nil || !strings.Contains(err.Error(), "não cabem") {
+		t.Fatalf("erro esperado para 5000 caixas, veio %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+// log.go - Registro estruturado (log/slog) num arquivo
+// O termbox ocupa a tela inteira, então o registro vai para um arquivo: início e fim das
+// goroutines das entidades, o surgimento do monstro, interações e mudanças de estado.
+// Sem arquivo (ou fora do jogo, como em "jogo validar"), o registro é descartado.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Níveis aceitos em -log-nivel
+var niveisLog = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"aviso": slog.LevelWarn,
+	"erro":  slog.LevelError,
+}
+
+func init() {
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+}
+
+// Lê o nível do registro pelo nome
+func nivelLogLer(nome string) (slog.Level, error) {
+	nivel, ok := niveisLog[strings.ToLower(nome)]
+	if !ok {
+		return nivel, fmt.Errorf("nível de registro desconhecido: %q (use debug, info, aviso ou erro)", nome)
+	}
+	return nivel, nil
+}
+
+// Passa a registrar em JSON, uma linha por registro, no fim do arquivo.
+// Retorna o arquivo, que deve ser fechado ao sair; sem arquivo, não registra nada.
+func logIniciar(arquivo string, nivel slog.Level) (io.Closer, error) {
+	if arquivo == "" {
+		return io.NopCloser(nil), nil
+	}
+	arq, err := os.OpenFile(arquivo, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(slog.New(slog.NewJSONHandler(arq, &slog.HandlerOptions{Level: nivel})))
+	return arq, nil
+}
+
+// Registra os eventos da partida: movimentos no nível debug, os demais no info.
+// O relógio (EventoTempo) não é registrado. Vale até o canal parar ser fechado.
+func logAssinar(jogo *Jogo, parar <-chan struct{}) {
+	var tipos []TipoEvento
+	for t := range nomesEventos {
+		if t != EventoTempo {
+			tipos = append(tipos, t)
+		}
+	}
+	jogoAssinar(jogo, parar, func(ev Evento) {
+		nivel := slog.LevelInfo
+		if ev.Tipo == EventoJogadorMoveu {
+			nivel = slog.LevelDebug
+		}
+		atributos := []any{"tipo", ev.Tipo.String(), "x", ev.X, "y", ev.Y}
+		if ev.Jogador != nil {
+			atributos = append(atributos, "jogador", ev.JogadorID)
+		}
+		if ev.Quantidade != 0 {
+			atributos = append(atributos, "quantidade", ev.Quantidade)
+		}
+		if ev.Tipo == EventoFimDeJogo {
+			atributos = append(atributos, "vitoria", ev.Vitoria)
+		}
+		slog.Log(context.Background(), nivel, "evento", atributos...)
+	}, tipos...)
+}
@@ -1,78 +1,773 @@
 package main
 
 import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
 	"time"
 )
 
-// Função para iniciar a renderização periódica do jogo
-func iniciarRenderizador(jogo *Jogo, parar <-chan struct{}) {
-	go func() {
-		ticker := time.NewTicker(100 * time.Millisecond) // redesenha a cada 100ms
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				interfaceDesenharJogo(jogo)
-			case <-parar:
-				return
+// Sessao guarda o que é preciso para começar, carregar e reiniciar partidas
+type Sessao struct {
+	Jogo          *Jogo
+	Mapa          string        // arquivo do mapa usado nas novas partidas
+	Configuracao  *Configuracao // dificuldade das novas partidas (tempos, caixas e meta)
+	Disputa       Disputa       // cooperativa ou competitiva
+	Modo          string        // modo de jogo das novas partidas (modo.go)
+	Local         bool          // se há um segundo jogador no mesmo teclado
+	ArquivoTeclas string        // onde as ligações de teclas são salvas
+	Recordes      string        // arquivo da tabela de recordes mostrada pelos menus
+	Tema          string        // tema em uso (um nome da pasta de temas ou um arquivo)
+	Perfil        *Perfil       // perfil do jogador (nil: sem perfil, os arquivos ficam na pasta atual)
+	Opcoes        OpcoesJogo    // opções da linha de comando, antes de o perfil completá-las
+	Andamento     string        // onde o andamento da campanha é guardado (perfil.go)
+	Gravar        string        // arquivo onde cada nova partida é gravada ("": nenhum)
+	Sair          bool          // marcado pelos menus para encerrar o programa
+}
+
+// Começa uma nova partida no mapa da sessão (numa campanha, no nível atual dela),
+// encerrando a anterior
+func (s *Sessao) NovaPartida() error {
+	jogo := s.Jogo
+	// as goroutines da partida anterior terminam antes de o mapa ser trocado
+	if err := jogoEncerrarEntidades(jogo); err != nil {
+		slog.Warn("partida anterior terminou com erro", "erro", err)
+	}
+
+	mapa := s.Mapa
+	if c := jogo.Campanha; c != nil {
+		mapa = c.Atual().Mapa
+	}
+	modo, err := modoNovo(s.Modo)
+	if err != nil {
+		return err
+	}
+	jogo.Configuracao = s.Configuracao
+	jogo.Modo = modo
+	remotos := jogoLimpar(jogo)
+	if err := jogoCarregarMapa(mapa, jogo); err != nil {
+		return err
+	}
+	jogo.MetaTesouros = s.Configuracao.Meta
+	jogo.Disputa = s.Disputa
+	jogo.Modo.Iniciar(jogo)
+	slog.Info("partida iniciada", "mapa", mapa, "semente", jogo.Semente, "dificuldade", s.Configuracao.Dificuldade,
+		"modo", modo.Nome(), "meta", jogo.MetaTesouros, "caixas", len(jogo.Caixas))
+
+	// Segundo jogador no mesmo teclado
+	if s.Local {
+		jogoAdicionarJogador(jogo)
+	}
+	// Jogadores conectados pela rede continuam na nova partida
+	for _, j := range remotos {
+		jogoRecolocarJogador(jogo, j)
+	}
+	// Numa campanha, os jogadores começam o nível com os itens que trouxeram do anterior
+	if c := jogo.Campanha; c != nil {
+		c.RestaurarItens(jogo)
+		jogo.SetMessage(c.Texto(), 5*time.Second)
+	}
+
+	// Inicializa o NPC
+	jogo.Guian = npcIniciar(jogo)
+	if s.Gravar != "" {
+		return s.gravarPartida(mapa)
+	}
+	return nil
+}
+
+// Recomeça a gravação com a partida que acabou de começar: o arquivo fica com a
+// última partida, com as escolhas feitas na tela de título
+func (s *Sessao) gravarPartida(mapa string) error {
+	jogo := s.Jogo
+	if jogo.Gravador != nil {
+		jogo.Gravador.Fechar()
+		jogo.Gravador = nil
+	}
+	cabecalho := CabecalhoGravacao{
+		Semente:      jogo.Semente,
+		Mapa:         mapa,
+		Dificuldade:  s.Configuracao.Dificuldade,
+		Meta:         jogo.MetaTesouros,
+		Modo:         jogo.Modo.Nome(),
+		Disputa:      jogo.Disputa.Nome(),
+		Local:        s.Local,
+		QuebraCabeca: jogo.QuebraCabeca,
+	}
+	// numa campanha o mapa vem do nível gravado
+	if c := jogo.Campanha; c != nil {
+		cabecalho.Mapa = ""
+		cabecalho.Campanha = &CampanhaSalva{c.Arquivo, c.Nivel, c.Pontos, c.Itens}
+	}
+	gravador, err := gravadorNovo(s.Gravar, cabecalho)
+	if err != nil {
+		return err
+	}
+	jogo.Gravador = gravador
+	return nil
+}
+
+// Passa ao próximo nível da campanha, levando os pontos e os itens do nível vencido
+func (s *Sessao) ProximoNivel() error {
+	c := s.Jogo.Campanha
+	if c == nil {
+		return errors.New(tr("campanha.sem_proximo"))
+	}
+	anterior := c.Atual().Nome
+	if err := c.Avancar(s.Jogo); err != nil {
+		return err
+	}
+	slog.Info("nível concluído", "nivel", anterior, "proximo", c.Atual().Nome, "pontos", c.Pontos)
+	s.gravarCampanha()
+	return s.NovaPartida()
+}
+
+// Recomeça a campanha do primeiro nível, sem pontos nem itens
+func (s *Sessao) RecomecarCampanha() error {
+	if c := s.Jogo.Campanha; c != nil {
+		s.Jogo.Campanha = progressoNovo(c.Arquivo, c.Campanha)
+		s.gravarCampanha()
+	}
+	return s.NovaPartida()
+}
+
+// Guarda no perfil em que nível a campanha está
+func (s *Sessao) gravarCampanha() {
+	c := s.Jogo.Campanha
+	if c == nil || s.Andamento == "" || s.Opcoes.Roteiro != nil {
+		return
+	}
+	if err := c.Gravar(s.Andamento); err != nil {
+		slog.Error("falha ao gravar o andamento da campanha", "arquivo", s.Andamento, "erro", err)
+	}
+}
+
+// Passa a usar as opções e os recursos carregados a partir delas: no começo do
+// programa e a cada troca de perfil
+func (s *Sessao) Usar(o *OpcoesJogo, r *Recursos) {
+	jogo := s.Jogo
+	s.Configuracao = r.Configuracao
+	s.ArquivoTeclas = o.Teclas
+	s.Recordes = o.Recordes
+	s.Andamento = o.Andamento
+	s.Tema = o.Tema
+	s.Modo = o.Modo
+	jogo.Teclas = r.Teclas
+	jogo.Dialogos = r.Dialogos
+	jogo.Configuracao = r.Configuracao
+	// uma reprodução repete uma partida que já está na tabela de recordes
+	// e não conta para as conquistas
+	if o.Roteiro == nil {
+		jogo.Recordes = o.Recordes
+		jogo.Conquistas = r.Conquistas
+	}
+	jogo.Campanha = nil
+	if r.Campanha != nil {
+		jogo.Campanha = progressoNovo(o.Campanha, r.Campanha)
+		// a campanha continua do nível em que o perfil parou
+		if o.Roteiro == nil && o.Andamento != "" {
+			if err := jogo.Campanha.Restaurar(o.Andamento); err != nil {
+				slog.Warn("andamento da campanha ignorado", "arquivo", o.Andamento, "erro", err)
 			}
 		}
-	}()
+		// a reprodução começa do nível em que a gravação começou
+		if g := o.Reproduzida; g != nil && g.Nivel >= 0 && g.Nivel < len(r.Campanha.Niveis) {
+			c := jogo.Campanha
+			c.Nivel, c.Pontos, c.Itens = g.Nivel, g.Pontos, g.Itens
+		}
+	}
+}
+
+// Passa a usar outro perfil, com as configurações, as teclas, os recordes, as
+// conquistas e a campanha dele. Só é chamada na tela de título, antes da partida.
+func (s *Sessao) TrocarPerfil(nome string) error {
+	p, err := perfilAbrir(nome)
+	if err != nil {
+		return err
+	}
+	o := s.Opcoes
+	p.Aplicar(&o)
+	idioma := idiomaAtual
+	r, err := o.Preparar()
+	if err != nil {
+		i18nDefinirIdioma(idioma)
+		return err
+	}
+	temaAplicar(r.Tema, interfaceModoCores(r.ModoCores), o.Acessivel)
+	s.Perfil = p
+	s.Usar(&o, r)
+	if err := perfilLembrar(nome); err != nil {
+		slog.Warn("falha ao lembrar o perfil escolhido", "erro", err)
+	}
+	slog.Info("perfil escolhido", "perfil", nome)
+	return nil
+}
+
+// Grava as configurações do perfil depois de uma mudança feita nos menus
+func (s *Sessao) SalvarPerfil() {
+	if s.Perfil == nil {
+		return
+	}
+	if err := s.Perfil.Salvar(); err != nil {
+		s.Jogo.SetMessage(tr("perfil.erro", err), 5*time.Second)
+	}
+}
+
+// Troca o tema na hora e o guarda no perfil
+func (s *Sessao) TrocarTema(nome string) error {
+	t, err := temaCarregar(nome)
+	if err != nil {
+		return err
+	}
+	modo, err := modoCoresLer(s.Opcoes.Cores)
+	if err != nil {
+		return err
+	}
+	temaAplicar(t, interfaceModoCores(modo), s.Opcoes.Acessivel)
+	s.Tema = nome
+	if s.Perfil != nil {
+		s.Perfil.Configuracao.Tema = nome
+		s.SalvarPerfil()
+	}
+	return nil
+}
+
+// Troca o idioma na hora, com as versões traduzidas das conversas e das conquistas,
+// e o guarda no perfil. As missões do mapa atual mudam de idioma na próxima partida.
+func (s *Sessao) TrocarIdioma(codigo string) error {
+	jogo := s.Jogo
+	anterior := idiomaAtual
+	if err := i18nDefinirIdioma(codigo); err != nil {
+		return err
+	}
+	dialogos, err := dialogosCarregar(i18nArquivo(s.Opcoes.Dialogos))
+	var conquistas []Conquista
+	if err == nil && jogo.Conquistas != nil {
+		conquistas, err = conquistasCarregar(i18nArquivo(s.Opcoes.Conquistas))
+	}
+	if err != nil {
+		i18nDefinirIdioma(anterior)
+		return err
+	}
+	jogo.Dialogos = dialogos
+	if jogo.Conquistas != nil {
+		jogo.Conquistas.Definicoes = conquistas
+	}
+	if s.Perfil != nil {
+		s.Perfil.Configuracao.Idioma = codigo
+		s.SalvarPerfil()
+	}
+	return nil
+}
+
+// Continua uma partida salva, encerrando a atual. Um arquivo inválido não encerra nada.
+func (s *Sessao) Carregar(arquivo string) error {
+	jogo := s.Jogo
+	salvo, err := salvamentoLer(arquivo)
+	if err != nil {
+		return err
+	}
+	// as goroutines da partida anterior terminam antes de o mapa ser trocado
+	jogoEncerrarEntidades(jogo)
+
+	jogo.Configuracao = s.Configuracao
+	remotos := jogoLimpar(jogo)
+	// a partida salva já traz os jogadores, a meta, o tipo de disputa e o modo de jogo
+	jogoRestaurarSalvo(jogo, salvo)
+	slog.Info("partida carregada", "arquivo", arquivo)
+	for _, j := range remotos {
+		jogoRecolocarJogador(jogo, j)
+	}
+	jogo.Guian = npcIniciar(jogo)
+	return nil
 }
 
 func main() {
-	// Inicializa a interface (termbox)
-	interfaceIniciar()
-	defer interfaceFinalizar()
+	os.Exit(cliExecutar(os.Args[1:]))
+}
+
+// OpcoesJogo são as opções de linha de comando usadas para jogar (e reproduzir) uma partida
+type OpcoesJogo struct {
+	Mapa         string
+	Semente      int64
+	Dificuldade  string
+	Configuracao string
+	Meta         int
+	Teclas       string
+	Dialogos     string
+	Idioma       string
+	Tema         string
+	Cores        string
+	Acessivel    bool
+	Renderizador string // "terminal" ou "nenhum" (sem tela, para servidores e reproduções)
+	Log          string // arquivo do registro (log.go)
+	NivelLog     string
+	Host         string
+	Conectar     string
+	Local        bool
+	Disputa      string
+	Modo         string // modo de jogo (modo.go); vazio: o do perfil, senão o clássico
+	QuebraCabeca bool
+	Gravar       string
+	Carregar     string
+	Campanha     string          // arquivo da campanha (campanha.go); vazio: joga só o mapa
+	Recordes     string          // arquivo da tabela de recordes (recordes.go)
+	Conquistas   string          // arquivo das definições das conquistas (conquistas.go)
+	Progresso    string          // arquivo do progresso do jogador nas conquistas
+	Perfil       string          // perfil do jogador (perfil.go); vazio: o último escolhido
+	Andamento    string          // arquivo do andamento da campanha, no perfil
+	Escolhidas   map[string]bool // opções dadas na linha de comando, que valem mais que as do perfil
+	Roteiro      <-chan Acao     // ações de uma gravação, executadas junto com as do teclado
+	Reproduzida  *CampanhaSalva  // nível, pontos e itens da campanha no começo da gravação
+}
+
+// Registra as opções comuns a jogar e reproduzir
+func opcoesRegistrar(fs *flag.FlagSet) *OpcoesJogo {
+	o := &OpcoesJogo{}
+	fs.StringVar(&o.Mapa, "mapa", "mapa.txt", "arquivo do mapa")
+	fs.Int64Var(&o.Semente, "semente", 0, "semente do sorteio das caixas e dos movimentos (0: uma nova a cada partida)")
+	fs.StringVar(&o.Dificuldade, "dificuldade", "", "dificuldade: "+strings.Join(ordemDificuldades, ", ")+" (padrão: a do arquivo de configuração, senão normal)")
+	fs.StringVar(&o.Configuracao, "configuracao", "", "arquivo JSON com a dificuldade e os valores que mudam nela (ex: configuracao.json)")
+	fs.IntVar(&o.Meta, "meta", 0, "quantidade de tesouros necessária para vencer (padrão: a da dificuldade)")
+	fs.StringVar(&o.Teclas, "teclas", "teclas.json", "arquivo com as ligações de teclas (com um perfil, o dele)")
+	fs.StringVar(&o.Dialogos, "dialogos", "dialogos.json", "arquivo com as conversas do Guian")
+	fs.StringVar(&o.Idioma, "lang", "", "idioma dos textos: "+strings.Join(i18nIdiomas(), ", ")+" (padrão: o da variável LANG)")
+	fs.StringVar(&o.Tema, "tema", "", "tema de cores: um nome da pasta "+PastaTemas+" (ex: alto-contraste, daltonico) ou um arquivo .json")
+	fs.StringVar(&o.Cores, "cores", "auto", "cores do terminal: auto, 8, 256 ou truecolor")
+	fs.BoolVar(&o.Acessivel, "acessivel", false, "modo de acessibilidade: símbolos próprios para o conteúdo das caixas")
+	fs.StringVar(&o.Renderizador, "renderizador", "terminal", "\"terminal\" ou \"nenhum\" (sem tela: servidor dedicado ou reprodução)")
+	fs.StringVar(&o.Log, "log", "jogo.log", "arquivo do registro (vazio: não registra)")
+	fs.StringVar(&o.NivelLog, "log-nivel", "info", "nível do registro: debug, info, aviso ou erro")
+	fs.StringVar(&o.Disputa, "disputa", "coop", "com dois jogadores: \"coop\" (tesouros compartilhados) ou \"competitivo\"")
+	fs.StringVar(&o.Modo, "modo", "", "modo de jogo: "+strings.Join(ordemModos, ", ")+" (padrão: o do perfil, senão "+ModoPadrao+")")
+	fs.StringVar(&o.Perfil, "perfil", "", "perfil do jogador, com suas configurações e seu progresso (padrão: o último escolhido)")
+	for apelido, nome := range apelidosOpcoes {
+		f := fs.Lookup(nome)
+		fs.Var(f.Value, apelido, "o mesmo que -"+nome)
+	}
+	return o
+}
+
+// Nomes em inglês das opções de partida, como os dos subcomandos
+var apelidosOpcoes = map[string]string{
+	"map":        "mapa",
+	"seed":       "semente",
+	"difficulty": "dificuldade",
+	"renderer":   "renderizador",
+	"profile":    "perfil",
+}
+
+// Opções escolhidas na linha de comando, pelo nome em português
+func opcoesEscolhidas(fs *flag.FlagSet) map[string]bool {
+	escolhidas := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { escolhidas[cmp.Or(apelidosOpcoes[f.Name], f.Name)] = true })
+	return escolhidas
+}
+
+// Recursos carregados a partir das opções, antes de abrir a interface
+type Recursos struct {
+	Teclas       *MapaTeclas
+	Dialogos     *Dialogos
+	Tema         *Tema
+	ModoCores    ModoCores
+	Configuracao *Configuracao
+	Campanha     *Campanha   // nil sem -campanha
+	Conquistas   *Conquistas // nil sem -conquistas
+	Disputa      Disputa
+	NivelLog     slog.Level
+}
+
+// Confere as opções e carrega os arquivos que elas indicam. Opções inválidas
+// são erros de uso; arquivos que faltam ou não podem ser lidos, não.
+func (o *OpcoesJogo) Preparar() (*Recursos, error) {
+	if o.Renderizador != "terminal" && o.Renderizador != "nenhum" {
+		return nil, erroUso("renderizador desconhecido: %q (use terminal ou nenhum)", o.Renderizador)
+	}
+	if o.Renderizador == "nenhum" && o.Conectar != "" {
+		return nil, erroUso("-conectar precisa do renderizador terminal")
+	}
+
+	// Escolhe o idioma antes de carregar os arquivos, que podem ter versões traduzidas
+	if o.Idioma == "" {
+		o.Idioma = i18nIdiomaDoAmbiente()
+	}
+	if err := i18nDefinirIdioma(o.Idioma); err != nil {
+		return nil, ErroUso{err}
+	}
+
+	r := &Recursos{}
+	var err error
+	if r.NivelLog, err = nivelLogLer(o.NivelLog); err != nil {
+		return nil, ErroUso{err}
+	}
+	switch o.Disputa {
+	case "coop":
+		r.Disputa = DisputaCooperativa
+	case "competitivo":
+		r.Disputa = DisputaCompetitiva
+	default:
+		return nil, erroUso("disputa desconhecida: %q", o.Disputa)
+	}
+	o.Modo = cmp.Or(o.Modo, ModoPadrao)
+	if _, err := modoNovo(o.Modo); err != nil {
+		return nil, ErroUso{err}
+	}
+	if r.ModoCores, err = modoCoresLer(o.Cores); err != nil {
+		return nil, ErroUso{err}
+	}
+	if r.Configuracao, err = configuracaoCarregar(o.Configuracao, o.Dificuldade); err != nil {
+		return nil, ErroUso{err}
+	}
+	// -meta vale mais que a dificuldade
+	if o.Meta != 0 {
+		r.Configuracao.Meta = o.Meta
+		if err := r.Configuracao.Validar(); err != nil {
+			return nil, ErroUso{err}
+		}
+	}
+	if r.Teclas, err = teclasCarregar(o.Teclas); err != nil {
+		return nil, err
+	}
+	if r.Dialogos, err = dialogosCarregar(i18nArquivo(o.Dialogos)); err != nil {
+		return nil, err
+	}
+	if r.Tema, err = temaCarregar(o.Tema); err != nil {
+		return nil, err
+	}
+	if o.Campanha != "" {
+		if r.Campanha, err = campanhaCarregar(o.Campanha); err != nil {
+			return nil, err
+		}
+	}
+	if o.Conquistas != "" {
+		r.Conquistas = &Conquistas{}
+		if r.Conquistas.Definicoes, err = conquistasCarregar(i18nArquivo(o.Conquistas)); err != nil {
+			return nil, err
+		}
+		if r.Conquistas.Progresso, err = progressoCarregar(o.Progresso); err != nil {
+			return nil, err
+		}
+		r.Conquistas.Arquivo = o.Progresso
+	}
+	return r, nil
+}
+
+// Joga uma partida com as opções já lidas; retorna o código de saída do programa
+func jogar(o *OpcoesJogo) int {
+	// O que a linha de comando deixou em aberto vem do perfil. Sem um perfil escolhido
+	// e sem onde guardá-lo, os arquivos ficam na pasta atual, como antes dos perfis.
+	linhaDeComando := *o
+	if o.Perfil != "" {
+		if err := perfilValidarNome(o.Perfil); err != nil {
+			return cliErro(ErroUso{err})
+		}
+	}
+	perfil, errPerfil := perfilAbrir(cmp.Or(o.Perfil, perfilAtual()))
+	switch {
+	case errPerfil == nil:
+		perfil.Aplicar(o)
+	case o.Perfil != "":
+		return cliErro(errPerfil)
+	}
+
+	r, err := o.Preparar()
+	if err != nil {
+		return cliErro(err)
+	}
+	semTela := o.Renderizador == "nenhum"
 
-	// Usa "mapa.txt" como arquivo padrão ou lê o primeiro argumento
-	mapaFile := "mapa.txt"
-	if len(os.Args) > 1 {
-		mapaFile = os.Args[1]
+	registro, err := logIniciar(o.Log, r.NivelLog)
+	if err != nil {
+		return cliErro(err)
+	}
+	defer registro.Close()
+	slog.Info("jogo iniciado", "versao", Versao, "mapa", o.Mapa, "renderizador", o.Renderizador,
+		"host", o.Host, "conectar", o.Conectar, "reproducao", o.Roteiro != nil)
+	defer slog.Info("jogo encerrado")
+	if errPerfil != nil {
+		slog.Warn("jogando sem perfil", "erro", errPerfil)
+	} else {
+		slog.Info("perfil", "perfil", perfil.Nome)
+		if o.Perfil != "" {
+			if err := perfilLembrar(perfil.Nome); err != nil {
+				slog.Warn("falha ao lembrar o perfil escolhido", "erro", err)
+			}
+		}
+	}
+
+	sessao := &Sessao{
+		Mapa:    o.Mapa,
+		Disputa: r.Disputa,
+		Local:   o.Local,
+		Perfil:  perfil,
+		Opcoes:  linhaDeComando,
+	}
+
+	// Inicializa a interface (termbox) e ajusta o tema às cores que o terminal suporta
+	if semTela {
+		temaAplicar(r.Tema, r.ModoCores, o.Acessivel)
+	} else {
+		if err := interfaceIniciar(); err != nil {
+			return cliErro(err)
+		}
+		temaAplicar(r.Tema, interfaceModoCores(r.ModoCores), o.Acessivel)
+	}
+
+	// Como cliente, toda a simulação acontece no anfitrião
+	if o.Conectar != "" {
+		err := redeConectar(o.Conectar, r.Teclas)
+		interfaceFinalizar()
+		if err != nil {
+			return cliErro(err)
+		}
+		return 0
+	}
+	if !semTela {
+		defer interfaceFinalizar()
 	}
 
 	// Inicializa o jogo
 	jogo := jogoNovo()
-	if err := jogoCarregarMapa(mapaFile, &jogo); err != nil {
-		panic(err)
+	jogo.QuebraCabeca = o.QuebraCabeca
+	jogo.Semente = o.Semente
+	// numa reprodução as respostas ao Guian não estão na gravação, e sem tela não há quem responda
+	jogo.SemConversas = o.Roteiro != nil || semTela
+	sessao.Jogo = &jogo
+	sessao.Usar(o, r)
+	// ao sair, espera as goroutines da partida terminarem
+	defer jogoEncerrarEntidades(&jogo)
+
+	// a gravação recomeça a cada nova partida (Sessao.NovaPartida)
+	sessao.Gravar = o.Gravar
+	defer func() {
+		if jogo.Gravador != nil {
+			jogo.Gravador.Fechar()
+		}
+	}()
+
+	// Com -carregar, uma gravação ou sem tela a partida começa direto; senão, pela tela de título
+	switch {
+	case o.Carregar != "":
+		if err := sessao.Carregar(o.Carregar); err != nil {
+			return cliErro(err)
+		}
+	case o.Roteiro != nil || semTela:
+		if err := sessao.NovaPartida(); err != nil {
+			return cliErro(err)
+		}
+	default:
+		jogoAbrirTela(&jogo, menuTitulo(sessao))
 	}
 
-	// Cria canal para parar as goroutines (NPC e renderizador)
+	// Cria canal para parar as goroutines (assinaturas, teclado e rede)
 	parar := make(chan struct{})
+	defer close(parar)
 
-	// Inicializa o NPC
-	jogo.Guian = npcIniciar(&jogo)
+	// Mensagens da barra de status para os eventos da partida
+	hudIniciar(&jogo, parar)
+	animacoesAssinar(&jogo, parar)
+	logAssinar(&jogo, parar)
 
-	// Inicia a renderização contínua do jogo
-	iniciarRenderizador(&jogo, parar)
+	// Eventos dos jogadores conectados pela rede
+	remotos := make(chan EventoRemoto)
+	if o.Host != "" {
+		servidor, err := redeServir(&jogo, o.Host, remotos, parar)
+		if err != nil {
+			return cliErro(err)
+		}
+		jogo.SetMessage(tr("rede.aguardando", servidor.Endereco()), 10*time.Second)
+		if semTela {
+			fmt.Println(tr("rede.aguardando", servidor.Endereco()))
+		}
+	}
 
-	// Loop principal de entrada
-	for !jogo.FimDeJogo {
-		// Atualiza o estado do jogo (monstro, npc, etc)
-		atualizarJogo(&jogo)
+	// Sem tela, o teclado não é lido, nada é desenhado e a partida termina com Ctrl+C
+	teclado := make(chan AtalhoTecla)
+	interromper := make(chan os.Signal, 1)
+	var desenhar <-chan time.Time
+	if semTela {
+		signal.Notify(interromper, os.Interrupt)
+		defer signal.Stop(interromper)
+	} else {
+		// Redesenha a tela a cada 100ms
+		renderizador := time.NewTicker(100 * time.Millisecond)
+		defer renderizador.Stop()
+		desenhar = renderizador.C
 
-		// Processa entrada do usuário
-		evento := interfaceLerEventoTeclado()
-		if evento.Tipo == "sair" {
-			break
-		}
+		// Lê o teclado em segundo plano para também atender os jogadores remotos
+		go func() {
+			for {
+				if atalho, ok := interfaceLerTecla(); ok {
+					teclado <- atalho
+				}
+			}
+		}()
+	}
+	roteiro := o.Roteiro
 
-		if continuar := personagemExecutarAcao(evento, &jogo); !continuar {
-			break
-		}
+	verificarFim := time.NewTicker(100 * time.Millisecond)
+	defer verificarFim.Stop()
+	ultimoTique := time.Now()
+
+	// Loop principal: é a goroutine dona do estado do mundo (mundo.go). Lê o teclado, a
+	// rede e as intenções das entidades, aplica uma coisa de cada vez e desenha a tela;
+	// ao fim de cada volta publica o retrato lido pelas outras goroutines.
+	for !sessao.Sair {
+		jogoRetratar(&jogo)
+		select {
+		case in := <-jogo.Mundo.Intencoes():
+			in.Aplicar(&jogo)
+
+		case <-desenhar:
+			interfaceDesenharJogo(&jogo)
+
+		case atalho := <-teclado:
+			// Com alguma tela aberta (menus), as teclas vão para ela
+			if tela := jogoTelaAtual(&jogo); tela != nil {
+				tela.Tecla(&jogo, atalho)
+				continue
+			}
+
+			// Teclas sem ligação são ignoradas
+			acao := jogo.Teclas.Traduzir(atalho)
+			switch acao.Tipo {
+			case AcaoNenhuma:
+				continue
+			case AcaoMenuTeclas:
+				jogoAbrirTela(&jogo, &MenuTeclas{Teclas: jogo.Teclas, Arquivo: sessao.ArquivoTeclas})
+				continue
+			case AcaoMissoes:
+				jogoAbrirMissoes(&jogo)
+				continue
+			case AcaoHistorico:
+				jogoAbrirHistorico(&jogo)
+				continue
+			case AcaoDepuracao:
+				jogo.Depuracao.Alternar()
+				continue
+			case AcaoPausar:
+				jogoPausar(&jogo)
+				jogoAbrirTela(&jogo, menuPausa(sessao))
+				continue
+			}
+			acao.Jogador = jogadorLocal(&jogo, acao)
+
+			// Atualiza o estado do jogo (monstro, npc, etc)
+			atualizarJogo(&jogo)
+
+			// Processa entrada do usuário
+			if continuar := jogoExecutarAcao(&jogo, acao); !continuar {
+				sessao.Sair = true
+			}
+
+		case acao, ok := <-roteiro:
+			// Ao fim da gravação a partida continua na tela; sem tela, o programa termina
+			if !ok {
+				roteiro = nil
+				sessao.Sair = semTela
+				continue
+			}
+			if acao.Jogador >= len(jogo.Jogadores) || jogo.FimDeJogo {
+				continue
+			}
+			atualizarJogo(&jogo)
+			if continuar := jogoExecutarAcao(&jogo, acao); !continuar {
+				sessao.Sair = true
+			}
+
+		case remoto := <-remotos:
+			if !jogo.Pausa.Ativa() && len(jogo.Mapa) > 0 {
+				atualizarJogo(&jogo)
+			}
+			processarEventoRemoto(&jogo, remoto)
+
+		case <-interromper:
+			sessao.Sair = true
 
-		// Pequena pausa para evitar uso excessivo da CPU
-		time.Sleep(50 * time.Millisecond)
+		case agora := <-verificarFim.C:
+			// O relógio da partida só anda com o jogo rodando
+			if !jogo.Pausa.Ativa() && len(jogo.Mapa) > 0 {
+				jogoPublicar(&jogo, Evento{Tipo: EventoTempo, Tempo: agora.Sub(ultimoTique)})
+				jogo.Depuracao.Tique()
+			}
+			ultimoTique = agora
+
+			// Uma goroutine da partida que falhou encerra a partida com derrota
+			if err := jogo.Entidades.Erro(); err != nil && !jogo.FimDeJogo {
+				jogoEncerrar(&jogo, false, tr("fim.erro_interno"))
+			}
+
+			// Quando a partida termina, mostra o resumo do nível; sem tela, a campanha
+			// segue sozinha para o próximo nível e o programa termina no fim dela
+			if jogo.FimDeJogo && jogoTelaAtual(&jogo) == nil {
+				if semTela {
+					if c := jogo.Campanha; jogo.Vitoria && c != nil && !c.Ultimo() {
+						fmt.Println(jogoResumo(&jogo))
+						if err := sessao.ProximoNivel(); err != nil {
+							return cliErro(err)
+						}
+						continue
+					}
+					sessao.Sair = true
+					continue
+				}
+				jogoAbrirTela(&jogo, menuFimDeJogo(sessao))
+			}
+		}
 	}
 
-	// Fecha o canal para parar as goroutines
-	close(parar)
+	// Sem tela, o resultado vai para a saída padrão
+	if semTela {
+		fmt.Println(jogoResumo(&jogo))
+	}
+	return 0
+}
 
-	// Mostra mensagem final por mais tempo se o jogo terminou
+// Uma linha com o resultado da partida, para quem joga sem tela
+func jogoResumo(jogo *Jogo) string {
+	resumo := tr("menu.tesouros", jogo.Tesouros, jogo.MetaTesouros) + " " + tr("menu.pontos", jogo.Pontos, jogoPontosTotal(jogo))
 	if jogo.FimDeJogo {
-		interfaceDesenharJogo(&jogo)
-		time.Sleep(5 * time.Second)
+		resumo = strings.ReplaceAll(jogo.MensagemFim, "\n", " ") + " " + resumo
+	}
+	if c := jogo.Campanha; c != nil {
+		resumo = c.Texto() + ": " + resumo
+	}
+	return resumo
+}
+
+// Escolhe qual jogador local é controlado pela ação do teclado.
+// Sem um segundo jogador local, as setas também movem o primeiro jogador.
+func jogadorLocal(jogo *Jogo, acao Acao) int {
+	if acao.Jogador < len(jogo.Jogadores) && !jogo.Jogadores[acao.Jogador].Remoto {
+		return acao.Jogador
+	}
+	return 0
+}
+
+// Executa a tecla de um jogador remoto, ou o remove da partida se ele desconectou
+func processarEventoRemoto(jogo *Jogo, remoto EventoRemoto) {
+	if jogoJogadorIndice(jogo, remoto.Jogador) < 0 {
+		return // jogador já saiu da partida
 	}
-}
\ No newline at end of file
+
+	if remoto.Desconectou {
+		slog.Info("jogador remoto saiu", "jogador", remoto.Jogador.ID)
+		jogoRemoverJogador(jogo, remoto.Jogador)
+		jogo.SetMessage(tr("rede.desconectou", remoto.Jogador.ID+1), 5*time.Second)
+		return
+	}
+
+	// um jogador remoto só age sobre o próprio personagem: não pode
+	// encerrar, pausar, salvar ou desfazer a partida do anfitrião
+	acao := remoto.Acao
+	if acoesDaPartida[acao.Tipo] {
+		return
+	}
+	acao.Jogador = jogoJogadorIndice(jogo, remoto.Jogador)
+	jogoExecutarAcao(jogo, acao)
+}
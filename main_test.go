@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+// Os nomes em inglês mudam as mesmas opções e contam como escolhidas pelo nome em português
+func TestOpcoesApelidos(t *testing.T) {
+	fs := flag.NewFlagSet("jogar", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	o := opcoesRegistrar(fs)
+	args := []string{"--map", "maze.txt", "--seed", "42", "--difficulty", "dificil", "--renderer", "nenhum", "--profile", "ana"}
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if o.Mapa != "maze.txt" || o.Semente != 42 || o.Dificuldade != "dificil" || o.Renderizador != "nenhum" || o.Perfil != "ana" {
+		t.Errorf("opções lidas: %+v", *o)
+	}
+	escolhidas := opcoesEscolhidas(fs)
+	for _, nome := range []string{"mapa", "semente", "dificuldade", "renderizador", "perfil"} {
+		if !escolhidas[nome] {
+			t.Errorf("-%s não foi marcada como escolhida: %v", nome, escolhidas)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// mensagens.go - Registro das mensagens da barra de status e o histórico
+// Várias mensagens podem estar ativas ao mesmo tempo, cada uma com sua prioridade e
+// validade; a barra de status mostra as mais importantes e o histórico guarda as demais.
+package main
+
+import (
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Prioridade define a ordem em que as mensagens aparecem na barra de status
+type Prioridade int
+
+const (
+	PrioridadeDica    Prioridade = iota // dicas do Guian; não entram no histórico
+	PrioridadeInfo                      // acontecimentos comuns
+	PrioridadeAviso                     // perigos: monstro, armadilhas, roubos
+	PrioridadeCritica                   // fim de jogo
+)
+
+// Quantas linhas a barra de status tem para as mensagens
+const LinhasStatus = 3
+
+// Quantas mensagens o histórico guarda
+const LimiteHistorico = 200
+
+// Mensagem é um texto exibido na barra de status (pode ter várias linhas, separadas por '\n')
+type Mensagem struct {
+	Texto      string
+	Prioridade Prioridade
+	Chave      string    // mensagens com a mesma chave se substituem (ex: a dica do Guian)
+	Destaque   bool      // exibida num quadro no canto da tela (ex: conquistas), fora da barra de status
+	Instante   time.Time // quando foi registrada
+	Expira     time.Time
+}
+
+// Linhas do texto da mensagem
+func (m Mensagem) Linhas() []string {
+	return strings.Split(m.Texto, "\n")
+}
+
+// RegistroMensagens guarda as mensagens ativas e o histórico; pode ser usado por várias goroutines
+type RegistroMensagens struct {
+	mu        sync.Mutex
+	ativas    []Mensagem
+	historico []Mensagem
+}
+
+func registroMensagensNovo() *RegistroMensagens {
+	return &RegistroMensagens{}
+}
+
+// Registra uma mensagem. Uma mensagem com a mesma chave, ou com o mesmo texto,
+// que ainda esteja ativa é substituída em vez de repetida.
+func (r *RegistroMensagens) Adicionar(m Mensagem) {
+	if m.Texto == "" {
+		return
+	}
+	if m.Instante.IsZero() {
+		m.Instante = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	repetida := false
+	for i, a := range r.ativas {
+		if (m.Chave != "" && a.Chave == m.Chave) || a.Texto == m.Texto {
+			repetida = a.Texto == m.Texto
+			r.ativas = append(r.ativas[:i], r.ativas[i+1:]...)
+			break
+		}
+	}
+	r.ativas = append(r.ativas, m)
+
+	if m.Prioridade > PrioridadeDica && !repetida {
+		r.historico = append(r.historico, m)
+		if len(r.historico) > LimiteHistorico {
+			r.historico = r.historico[len(r.historico)-LimiteHistorico:]
+		}
+	}
+}
+
+// Mensagens ainda válidas, da mais importante para a menos importante
+// (na mesma prioridade, a mais recente primeiro)
+func (r *RegistroMensagens) Ativas(agora time.Time) []Mensagem {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// descarta as expiradas
+	validas := r.ativas[:0]
+	for _, m := range r.ativas {
+		if agora.Before(m.Expira) {
+			validas = append(validas, m)
+		}
+	}
+	r.ativas = validas
+
+	ativas := append([]Mensagem(nil), r.ativas...)
+	sort.SliceStable(ativas, func(i, j int) bool {
+		if ativas[i].Prioridade != ativas[j].Prioridade {
+			return ativas[i].Prioridade > ativas[j].Prioridade
+		}
+		return ativas[i].Instante.After(ativas[j].Instante)
+	})
+	return ativas
+}
+
+// Cópia do histórico, da mais antiga para a mais recente
+func (r *RegistroMensagens) Historico() []Mensagem {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Mensagem(nil), r.historico...)
+}
+
+// Remove as mensagens ativas (o histórico é mantido)
+func (r *RegistroMensagens) Limpar() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ativas = nil
+}
+
+// Registra uma mensagem com a prioridade indicada
+func (j *Jogo) Notificar(msg string, p Prioridade, duracao time.Duration) {
+	j.Mensagens.Adicionar(Mensagem{Texto: msg, Prioridade: p, Expira: time.Now().Add(duracao)})
+}
+
+// Cores padrão de cada prioridade na barra de status
+var coresMensagensPadrao = map[Prioridade]termbox.Attribute{
+	PrioridadeDica:    CorRoxa,
+	PrioridadeInfo:    CorCinzaEscuro,
+	PrioridadeAviso:   CorAmarela,
+	PrioridadeCritica: CorVermelho,
+}
+
+// Cor de cada prioridade na barra de status (o tema pode trocá-las)
+var coresPrioridade = maps.Clone(coresMensagensPadrao)
+
+// Linhas exibidas na barra de status, das mensagens mais importantes
+func mensagensLinhasStatus(jogo *Jogo, max int) ([]string, []termbox.Attribute) {
+	var linhas []string
+	var cores []termbox.Attribute
+	for _, m := range jogo.Mensagens.Ativas(time.Now()) {
+		if m.Destaque {
+			continue
+		}
+		for _, l := range m.Linhas() {
+			if len(linhas) == max {
+				return linhas, cores
+			}
+			linhas = append(linhas, l)
+			cores = append(cores, coresPrioridade[m.Prioridade])
+		}
+	}
+	return linhas, cores
+}
+
+// Mensagens em destaque ainda válidas, a mais recente primeiro
+func mensagensDestaques(jogo *Jogo) []Mensagem {
+	var destaques []Mensagem
+	for _, m := range jogo.Mensagens.Ativas(time.Now()) {
+		if m.Destaque {
+			destaques = append(destaques, m)
+		}
+	}
+	return destaques
+}
+
+// TelaHistorico mostra as mensagens antigas, rolando com as setas
+type TelaHistorico struct {
+	Inicio int // primeira linha visível, contada a partir do fim
+}
+
+// Quantas linhas do histórico cabem no painel
+const LinhasHistorico = 15
+
+// Abre o painel com o histórico de mensagens
+func jogoAbrirHistorico(jogo *Jogo) {
+	jogoAbrirTela(jogo, &TelaHistorico{})
+}
+
+// Todas as linhas do histórico, com o horário de cada mensagem
+func (t *TelaHistorico) linhas(jogo *Jogo) []string {
+	var linhas []string
+	for _, m := range jogo.Mensagens.Historico() {
+		for i, l := range m.Linhas() {
+			if i == 0 {
+				linhas = append(linhas, m.Instante.Format("15:04:05")+"  "+l)
+			} else {
+				linhas = append(linhas, "          "+l)
+			}
+		}
+	}
+	return linhas
+}
+
+func (t *TelaHistorico) Desenhar(jogo *Jogo) {
+	linhas := t.linhas(jogo)
+
+	// o painel mostra as mensagens mais recentes; Inicio rola para as mais antigas
+	fim := len(linhas) - t.Inicio
+	ini := fim - LinhasHistorico
+	if ini < 0 {
+		ini = 0
+	}
+	visiveis := linhas[ini:fim]
+	if len(linhas) == 0 {
+		visiveis = []string{tr("historico.vazio")}
+	}
+
+	interfaceDesenharMenu(&Menu{
+		Titulo:      tr("historico.titulo"),
+		Linhas:      visiveis,
+		Opcoes:      []OpcaoMenu{{Texto: tr("menu.voltar")}},
+		Selecionada: 0,
+	})
+}
+
+func (t *TelaHistorico) Tecla(jogo *Jogo, atalho AtalhoTecla) {
+	acao := Acao{}
+	if jogo.Teclas != nil {
+		acao = jogo.Teclas.Traduzir(atalho)
+	}
+	total := len(t.linhas(jogo))
+
+	switch {
+	case atalho.Tecla == termbox.KeyEsc || atalho.Tecla == termbox.KeyEnter || acao.Tipo == AcaoInteragir:
+		jogoFecharTela(jogo)
+	case atalho.Tecla == termbox.KeyArrowUp || acao.Tipo == AcaoMover && acao.Direcao == DirecaoCima:
+		if t.Inicio+LinhasHistorico < total {
+			t.Inicio++
+		}
+	case atalho.Tecla == termbox.KeyArrowDown || acao.Tipo == AcaoMover && acao.Direcao == DirecaoBaixo:
+		if t.Inicio > 0 {
+			t.Inicio--
+		}
+	case atalho.Tecla == termbox.KeyPgup:
+		t.Inicio = min(t.Inicio+LinhasHistorico, max(total-LinhasHistorico, 0))
+	case atalho.Tecla == termbox.KeyPgdn:
+		t.Inicio = max(t.Inicio-LinhasHistorico, 0)
+	}
+}
@@ -0,0 +1,325 @@
+// menu.go - Telas sobrepostas ao jogo: pausa, configurações, título e fim de jogo
+package main
+
+import (
+	"cmp"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Tela é uma camada desenhada por cima do jogo que recebe as teclas enquanto está aberta
+type Tela interface {
+	Desenhar(jogo *Jogo)
+	Tecla(jogo *Jogo, atalho AtalhoTecla)
+}
+
+// Abre uma tela por cima das que já estão abertas
+func jogoAbrirTela(jogo *Jogo, t Tela) {
+	jogo.Telas = append(jogo.Telas, t)
+}
+
+// Fecha a tela que está por cima
+func jogoFecharTela(jogo *Jogo) {
+	if len(jogo.Telas) > 0 {
+		jogo.Telas = jogo.Telas[:len(jogo.Telas)-1]
+	}
+}
+
+// Fecha a tela t e as que estão por cima dela; não faz nada se t não está aberta
+func jogoFecharAte(jogo *Jogo, t Tela) {
+	if i := slices.Index(jogo.Telas, t); i >= 0 {
+		jogo.Telas = jogo.Telas[:i]
+	}
+}
+
+// Retorna a tela que está por cima, ou nil se nenhuma está aberta
+func jogoTelaAtual(jogo *Jogo) Tela {
+	if len(jogo.Telas) == 0 {
+		return nil
+	}
+	return jogo.Telas[len(jogo.Telas)-1]
+}
+
+// OpcaoMenu é uma linha selecionável de um menu
+type OpcaoMenu struct {
+	Texto    string
+	Rotulo   func() string // se definido, gera o texto na hora de desenhar (ex: "Som: ligado")
+	Executar func()
+}
+
+// Texto exibido para a opção
+func (o OpcaoMenu) String() string {
+	if o.Rotulo != nil {
+		return o.Rotulo()
+	}
+	return o.Texto
+}
+
+// Menu é uma lista de opções navegável com as setas (ou as teclas de movimento)
+type Menu struct {
+	Titulo      string
+	Linhas      []string // texto informativo exibido abaixo do título
+	Opcoes      []OpcaoMenu
+	Selecionada int
+	AoFechar    func() // executado quando Esc é pressionado; nil se o menu não pode ser fechado
+}
+
+func (m *Menu) Desenhar(jogo *Jogo) {
+	interfaceDesenharMenu(m)
+}
+
+func (m *Menu) Tecla(jogo *Jogo, atalho AtalhoTecla) {
+	if len(m.Opcoes) == 0 {
+		return
+	}
+
+	// além das setas, as teclas de movimento e interação do jogador também navegam
+	acao := Acao{}
+	if jogo.Teclas != nil {
+		acao = jogo.Teclas.Traduzir(atalho)
+	}
+
+	switch {
+	case atalho.Tecla == termbox.KeyEsc:
+		if m.AoFechar != nil {
+			m.AoFechar()
+		}
+	case atalho.Tecla == termbox.KeyArrowUp || acao.Tipo == AcaoMover && acao.Direcao == DirecaoCima:
+		m.Selecionada = (m.Selecionada + len(m.Opcoes) - 1) % len(m.Opcoes)
+	case atalho.Tecla == termbox.KeyArrowDown || acao.Tipo == AcaoMover && acao.Direcao == DirecaoBaixo:
+		m.Selecionada = (m.Selecionada + 1) % len(m.Opcoes)
+	case atalho.Tecla == termbox.KeyEnter || acao.Tipo == AcaoInteragir:
+		if exec := m.Opcoes[m.Selecionada].Executar; exec != nil {
+			exec()
+		}
+	}
+}
+
+// Menu de pausa: congela o jogo até o jogador escolher continuar
+func menuPausa(s *Sessao) *Menu {
+	jogo := s.Jogo
+	continuar := func() {
+		jogoFecharTela(jogo)
+		jogoRetomar(jogo)
+	}
+
+	return &Menu{
+		Titulo: tr("menu.pausado"),
+		Opcoes: []OpcaoMenu{
+			{Texto: tr("menu.continuar"), Executar: continuar},
+			{Texto: tr("menu.salvar"), Executar: func() {
+				if err := jogoSalvar(jogo, ArquivoSalvamento); err != nil {
+					jogo.SetMessage(tr("salvar.erro", err), 5*time.Second)
+				} else {
+					jogo.SetMessage(tr("salvar.ok", ArquivoSalvamento), 3*time.Second)
+				}
+				continuar()
+			}},
+			{Texto: tr("menu.carregar"), Executar: func() {
+				if err := s.Carregar(ArquivoSalvamento); err != nil {
+					jogo.SetMessage(tr("menu.erro_carregar", err), 5*time.Second)
+				}
+			}},
+			{Texto: tr("menu.missoes"), Executar: func() { jogoAbrirMissoes(jogo) }},
+			{Texto: tr("menu.conquistas"), Executar: func() { jogoAbrirConquistas(jogo) }},
+			{Texto: tr("menu.mensagens"), Executar: func() { jogoAbrirHistorico(jogo) }},
+			{Texto: tr("menu.configuracoes"), Executar: func() { jogoAbrirTela(jogo, menuConfiguracoes(s)) }},
+			{Texto: tr("menu.controles"), Executar: func() {
+				jogoAbrirTela(jogo, &MenuTeclas{Teclas: jogo.Teclas, Arquivo: s.ArquivoTeclas})
+			}},
+			{Texto: tr("menu.sair"), Executar: func() { s.Sair = true }},
+		},
+		AoFechar: continuar,
+	}
+}
+
+// Menu de configurações da partida
+func menuConfiguracoes(s *Sessao) *Menu {
+	jogo := s.Jogo
+	ligado := func(b bool) string {
+		if b {
+			return tr("menu.ligado")
+		}
+		return tr("menu.desligado")
+	}
+
+	// a dificuldade, o tema e o idioma escolhidos aqui ficam guardados no perfil
+	return &Menu{
+		Titulo: tr("menu.configuracoes_titulo"),
+		Opcoes: []OpcaoMenu{
+			{
+				Rotulo:   func() string { return tr("menu.quebra_cabeca", ligado(jogo.QuebraCabeca)) },
+				Executar: func() { jogo.QuebraCabeca = !jogo.QuebraCabeca; jogo.Historico = nil },
+			},
+			{
+				// a nova dificuldade vale a partir da próxima partida
+				Rotulo: func() string {
+					return tr("menu.dificuldade", tr("dificuldade."+s.Configuracao.Dificuldade))
+				},
+				Executar: func() {
+					s.Configuracao, _ = configuracaoDificuldade(dificuldadeSeguinte(s.Configuracao.Dificuldade))
+					jogo.SetMessage(tr("menu.dificuldade_proxima"), 3*time.Second)
+					if s.Perfil != nil {
+						s.Perfil.Configuracao.Dificuldade = s.Configuracao.Dificuldade
+						s.SalvarPerfil()
+					}
+				},
+			},
+			{
+				Rotulo: func() string { return tr("menu.tema", cmp.Or(s.Tema, "padrao")) },
+				Executar: func() {
+					if err := s.TrocarTema(seguinte(temasDisponiveis(), cmp.Or(s.Tema, "padrao"))); err != nil {
+						jogo.SetMessage(tr("menu.erro_tema", err), 5*time.Second)
+					}
+				},
+			},
+			{
+				Rotulo: func() string { return tr("menu.idioma", i18nNome(idiomaAtual)) },
+				Executar: func() {
+					if err := s.TrocarIdioma(seguinte(i18nIdiomas(), idiomaAtual)); err != nil {
+						jogo.SetMessage(tr("menu.erro_idioma", err), 5*time.Second)
+						return
+					}
+					// os menus abertos são refeitos no novo idioma
+					base := menuTitulo(s)
+					if len(jogo.Mapa) > 0 {
+						base = menuPausa(s)
+					}
+					config := menuConfiguracoes(s)
+					config.Selecionada = 3
+					jogo.Telas = []Tela{base, config}
+				},
+			},
+			{Texto: tr("menu.voltar"), Executar: func() { jogoFecharTela(jogo) }},
+		},
+		AoFechar: func() { jogoFecharTela(jogo) },
+	}
+}
+
+// O valor seguinte ao atual na lista, voltando ao primeiro depois do último
+func seguinte(lista []string, atual string) string {
+	if len(lista) == 0 {
+		return atual
+	}
+	return lista[(slices.Index(lista, atual)+1)%len(lista)]
+}
+
+// Tela de vitória ou derrota, exibida por cima do mapa quando a partida termina. Numa
+// campanha, é o resumo do nível: vencido, leva ao próximo; perdido, pode ser repetido.
+func menuFimDeJogo(s *Sessao) *Menu {
+	jogo := s.Jogo
+	c := jogo.Campanha
+	titulo := tr("menu.fim")
+	if jogo.Vitoria {
+		titulo = tr("menu.vitoria")
+		if c != nil {
+			titulo = tr("campanha.nivel_concluido")
+		}
+	}
+
+	linhas := []string{jogo.MensagemFim}
+	if c != nil {
+		linhas = append(linhas, c.Texto())
+	}
+	linhas = append(linhas,
+		tr("menu.tesouros", jogo.Tesouros, jogo.MetaTesouros),
+		tr("menu.pontos", jogo.Pontos, jogoPontosTotal(jogo)),
+	)
+
+	executar := func(f func() error, chave string) func() {
+		return func() {
+			if err := f(); err != nil {
+				jogo.SetMessage(tr(chave, err), 5*time.Second)
+			}
+		}
+	}
+	var opcoes []OpcaoMenu
+	switch {
+	case c != nil && jogo.Vitoria && !c.Ultimo():
+		opcoes = append(opcoes, OpcaoMenu{Texto: tr("menu.proximo_nivel"), Executar: executar(s.ProximoNivel, "menu.erro_iniciar")})
+	case c != nil && jogo.Vitoria:
+		linhas = append(linhas, tr("campanha.concluida", c.Campanha.Nome))
+		opcoes = append(opcoes, OpcaoMenu{Texto: tr("menu.recomecar_campanha"), Executar: executar(s.RecomecarCampanha, "menu.erro_reiniciar")})
+	default:
+		opcoes = append(opcoes, OpcaoMenu{Texto: tr("menu.jogar_novamente"), Executar: executar(s.NovaPartida, "menu.erro_reiniciar")})
+	}
+	if s.Recordes != "" {
+		opcoes = append(opcoes, OpcaoMenu{Texto: tr("menu.recordes"), Executar: func() { jogoAbrirRecordes(jogo, s.Recordes, jogo.Modo.Nome()) }})
+	}
+	if jogo.Conquistas != nil {
+		opcoes = append(opcoes, OpcaoMenu{Texto: tr("menu.conquistas"), Executar: func() { jogoAbrirConquistas(jogo) }})
+	}
+	opcoes = append(opcoes, OpcaoMenu{Texto: tr("menu.sair"), Executar: func() { s.Sair = true }})
+
+	return &Menu{
+		Titulo: titulo,
+		Linhas: linhas,
+		Opcoes: opcoes,
+	}
+}
+
+// Menu da tela de título, exibido antes da partida começar
+func menuTitulo(s *Sessao) *Menu {
+	jogo := s.Jogo
+	opcoes := []OpcaoMenu{
+		{Texto: tr("menu.novo_jogo"), Executar: func() {
+			if err := s.NovaPartida(); err != nil {
+				jogo.SetMessage(tr("menu.erro_iniciar", err), 5*time.Second)
+			}
+		}},
+		// o modo escolhido vale para as próximas partidas e fica guardado no perfil
+		{Rotulo: func() string { return tr("menu.modo", tr("modo."+s.Modo)) }, Executar: func() {
+			s.Modo = seguinte(ordemModos, s.Modo)
+			if s.Perfil != nil {
+				s.Perfil.Configuracao.Modo = s.Modo
+				s.SalvarPerfil()
+			}
+		}},
+	}
+	if s.Perfil != nil {
+		opcoes = append(opcoes, OpcaoMenu{Texto: tr("menu.perfil", s.Perfil.Nome), Executar: func() {
+			jogoAbrirTela(jogo, menuPerfis(s))
+		}})
+	}
+	if _, err := os.Stat(ArquivoSalvamento); err == nil {
+		opcoes = append(opcoes, OpcaoMenu{Texto: tr("menu.continuar"), Executar: func() {
+			if err := s.Carregar(ArquivoSalvamento); err != nil {
+				jogo.SetMessage(tr("menu.erro_carregar", err), 5*time.Second)
+			}
+		}})
+	}
+	if s.Recordes != "" {
+		opcoes = append(opcoes, OpcaoMenu{Texto: tr("menu.recordes"), Executar: func() { jogoAbrirRecordes(jogo, s.Recordes, s.Modo) }})
+	}
+	if jogo.Conquistas != nil {
+		opcoes = append(opcoes, OpcaoMenu{Texto: tr("menu.conquistas"), Executar: func() { jogoAbrirConquistas(jogo) }})
+	}
+	opcoes = append(opcoes,
+		OpcaoMenu{Texto: tr("menu.configuracoes"), Executar: func() { jogoAbrirTela(jogo, menuConfiguracoes(s)) }},
+		OpcaoMenu{Texto: tr("menu.controles"), Executar: func() {
+			jogoAbrirTela(jogo, &MenuTeclas{Teclas: jogo.Teclas, Arquivo: s.ArquivoTeclas})
+		}},
+		OpcaoMenu{Texto: tr("menu.sair"), Executar: func() { s.Sair = true }},
+	)
+
+	return &Menu{
+		Titulo: tr("menu.titulo"),
+		Linhas: []string{tr("menu.subtitulo", CaixaElemento.simbolo)},
+		Opcoes: opcoes,
+	}
+}
+
+// Pausa o jogo, congelando as entidades
+func jogoPausar(jogo *Jogo) {
+	jogo.Pausa.Pausar()
+}
+
+// Retoma o jogo, empurrando o surgimento do monstro pelo tempo que ficou pausado
+func jogoRetomar(jogo *Jogo) {
+	if pausado := jogo.Pausa.Retomar(); pausado > 0 {
+		jogo.MonstroSpawn = jogo.MonstroSpawn.Add(pausado)
+	}
+}
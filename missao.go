@@ -0,0 +1,344 @@
+// missao.go - Objetivos de cada mapa e o registro de missões
+// Os objetivos de um mapa ficam num arquivo ao lado dele, com a extensão trocada por
+// ".missoes.json" (ex: "mapa.txt" -> "mapa.missoes.json"), por exemplo:
+//
+//	[
+//	  {"tipo": "conversar", "recompensa": {"item": "lanterna"}},
+//	  {"tipo": "alcancar", "x": 10, "y": 5, "descricao": "Chegue à torre"},
+//	  {"tipo": "tesouros", "quantidade": 4, "principal": true}
+//	]
+//
+// A partida é vencida quando todos os objetivos principais são concluídos (se nenhum
+// for marcado como principal, todos contam). Sem arquivo, o único objetivo é
+// encontrar a quantidade de tesouros definida em -meta.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Tipos de objetivo
+const (
+	ObjetivoTesouros        = "tesouros"         // encontrar N tesouros
+	ObjetivoItem            = "item"             // carregar N unidades de um item
+	ObjetivoAlcancar        = "alcancar"         // chegar a uma célula do mapa
+	ObjetivoDerrotarMonstro = "derrotar_monstro" // derrotar o monstro N vezes
+	ObjetivoSobreviver      = "sobreviver"       // continuar vivo por N segundos
+	ObjetivoConversar       = "conversar"        // conversar com o Guian
+)
+
+// Recompensa entregue ao concluir um objetivo
+type Recompensa struct {
+	Item     string `json:"item,omitempty"`     // item entregue ao jogador que concluiu
+	Mensagem string `json:"mensagem,omitempty"` // mensagem exibida na barra de status
+}
+
+// Objetivo é uma meta da partida e o seu progresso
+type Objetivo struct {
+	Tipo       string      `json:"tipo"`
+	Descricao  string      `json:"descricao,omitempty"`
+	Quantidade int         `json:"quantidade,omitempty"` // tesouros, itens, vitórias ou segundos
+	Item       string      `json:"item,omitempty"`
+	X          int         `json:"x,omitempty"`
+	Y          int         `json:"y,omitempty"`
+	Principal  bool        `json:"principal,omitempty"`
+	Recompensa *Recompensa `json:"recompensa,omitempty"`
+
+	Progresso int  `json:"progresso,omitempty"`
+	Concluido bool `json:"concluido,omitempty"`
+}
+
+// Missoes guarda os objetivos da partida atual
+type Missoes struct {
+	Objetivos []*Objetivo
+	Decorrido time.Duration // tempo de jogo sem contar as pausas
+}
+
+// Arquivo de objetivos correspondente a um mapa (a versão traduzida, se houver)
+func missoesArquivo(mapa string) string {
+	return i18nArquivo(strings.TrimSuffix(mapa, filepath.Ext(mapa)) + ".missoes.json")
+}
+
+// Objetivos usados quando o mapa não declara nenhum
+func missoesPadrao() *Missoes {
+	return &Missoes{Objetivos: []*Objetivo{{Tipo: ObjetivoTesouros, Principal: true}}}
+}
+
+// Lê os objetivos declarados para o mapa, ou usa os objetivos padrão
+func missoesCarregar(mapa string, jogo *Jogo) (*Missoes, error) {
+	nome := missoesArquivo(mapa)
+	dados, err := os.ReadFile(nome)
+	if errors.Is(err, os.ErrNotExist) {
+		return missoesPadrao(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Missoes{}
+	if err := json.Unmarshal(dados, &m.Objetivos); err != nil {
+		return nil, fmt.Errorf("%s: %w", nome, err)
+	}
+	if err := m.Validar(jogo); err != nil {
+		return nil, fmt.Errorf("%s: %w", nome, err)
+	}
+	return m, nil
+}
+
+// Verifica se os objetivos fazem sentido no mapa carregado
+func (m *Missoes) Validar(jogo *Jogo) error {
+	if len(m.Objetivos) == 0 {
+		return errors.New("nenhum objetivo declarado")
+	}
+	for i, o := range m.Objetivos {
+		switch o.Tipo {
+		case ObjetivoTesouros, ObjetivoDerrotarMonstro, ObjetivoConversar:
+		case ObjetivoItem:
+			if o.Item == "" {
+				return fmt.Errorf("objetivo %d: falta o item", i+1)
+			}
+		case ObjetivoAlcancar:
+			if o.Y < 0 || o.Y >= len(jogo.Mapa) || o.X < 0 || o.X >= len(jogo.Mapa[o.Y]) {
+				return fmt.Errorf("objetivo %d: posição (%d, %d) fora do mapa", i+1, o.X, o.Y)
+			}
+		case ObjetivoSobreviver:
+			if o.Quantidade <= 0 {
+				return fmt.Errorf("objetivo %d: falta a quantidade de segundos", i+1)
+			}
+		default:
+			return fmt.Errorf("objetivo %d: tipo desconhecido: %q", i+1, o.Tipo)
+		}
+		if o.Quantidade < 0 {
+			return fmt.Errorf("objetivo %d: quantidade negativa", i+1)
+		}
+	}
+	return nil
+}
+
+// Quantidade necessária para concluir o objetivo
+func (o *Objetivo) Total(jogo *Jogo) int {
+	switch {
+	case o.Tipo == ObjetivoTesouros && o.Quantidade == 0:
+		return jogo.MetaTesouros
+	case o.Tipo == ObjetivoAlcancar:
+		return 1
+	case o.Quantidade == 0:
+		return 1
+	}
+	return o.Quantidade
+}
+
+// Texto exibido no registro de missões
+func (o *Objetivo) Texto(jogo *Jogo) string {
+	if o.Descricao != "" {
+		return o.Descricao
+	}
+	total := o.Total(jogo)
+	switch o.Tipo {
+	case ObjetivoTesouros:
+		if jogo.Disputa == DisputaCompetitiva {
+			return trN("missao.tesouros_competitivo", total, total)
+		}
+		return trN("missao.tesouros", total, total)
+	case ObjetivoItem:
+		return trN("missao.item", total, total, trItem(o.Item))
+	case ObjetivoAlcancar:
+		return tr("missao.alcancar", o.X, o.Y)
+	case ObjetivoDerrotarMonstro:
+		return trN("missao.derrotar", total, total)
+	case ObjetivoSobreviver:
+		return trN("missao.sobreviver", total, total)
+	case ObjetivoConversar:
+		return tr("missao.conversar")
+	}
+	return o.Tipo
+}
+
+// Quantas unidades do item o jogador carrega
+func jogadorContarItem(j *Jogador, item string) int {
+	n := 0
+	for _, i := range j.Itens {
+		if i == item {
+			n++
+		}
+	}
+	return n
+}
+
+// Atualiza o progresso do objetivo com o evento.
+// Retorna quem mais avançou no objetivo (nil para o relógio) e se ele foi concluído.
+func (o *Objetivo) avancar(jogo *Jogo, ev Evento, m *Missoes) (*Jogador, bool) {
+	total := o.Total(jogo)
+
+	switch o.Tipo {
+	// objetivos que dependem do estado atual: o melhor jogador define o progresso
+	case ObjetivoTesouros, ObjetivoItem, ObjetivoAlcancar:
+		melhor, progresso := (*Jogador)(nil), 0
+		for _, j := range jogo.Jogadores {
+			p := 0
+			switch o.Tipo {
+			case ObjetivoTesouros:
+				p = jogadorTesourosEncontrados(jogo, j)
+			case ObjetivoItem:
+				p = jogadorContarItem(j, o.Item)
+			case ObjetivoAlcancar:
+				if j.X == o.X && j.Y == o.Y {
+					p = 1
+				}
+			}
+			// em caso de empate, fica com quem causou o evento
+			if p > progresso || p == progresso && p > 0 && j == ev.Jogador {
+				melhor, progresso = j, p
+			}
+		}
+		o.Progresso = progresso
+		return melhor, o.Progresso >= total
+
+	// objetivos que contam acontecimentos
+	case ObjetivoDerrotarMonstro:
+		if ev.Tipo == EventoMonstroDerrotado {
+			o.Progresso++
+		}
+	case ObjetivoConversar:
+		if ev.Tipo == EventoConversa {
+			o.Progresso++
+		}
+	case ObjetivoSobreviver:
+		o.Progresso = int(m.Decorrido / time.Second)
+	}
+	return ev.Jogador, o.Progresso >= total
+}
+
+// Atualiza os objetivos com um evento da partida, concluindo os que ele completar.
+// Quando todos os objetivos principais são concluídos, a partida é vencida (jogoVencer).
+// Deve ser chamada pelo dono do estado.
+func missoesProcessar(jogo *Jogo, ev Evento) {
+	m := jogo.Missoes
+	if m == nil || jogo.FimDeJogo {
+		return
+	}
+
+	if ev.Tipo == EventoTempo {
+		m.Decorrido += ev.Tempo
+	}
+
+	var ultimo *Jogador
+	for _, o := range m.Objetivos {
+		if o.Concluido {
+			continue
+		}
+		j, concluido := o.avancar(jogo, ev, m)
+		if !concluido {
+			continue
+		}
+		o.Concluido = true
+		ultimo = j
+		missaoRecompensar(jogo, o, j)
+	}
+
+	if m.principaisConcluidos() {
+		// na disputa competitiva vence quem concluiu o último objetivo
+		if jogo.Disputa == DisputaCompetitiva && ultimo != nil {
+			jogoVencer(jogo, tr("fim.vitoria_jogador", ultimo.ID+1, ultimo.Tesouros))
+		} else {
+			jogoVencer(jogo, tr("fim.vitoria"))
+		}
+	}
+}
+
+// Entrega a recompensa do objetivo concluído
+func missaoRecompensar(jogo *Jogo, o *Objetivo, j *Jogador) {
+	msg := tr("missao.concluido", o.Texto(jogo))
+	if r := o.Recompensa; r != nil {
+		if r.Item != "" && j != nil {
+			j.Itens = append(j.Itens, r.Item)
+			msg = tr("missao.recompensa", msg, trItem(r.Item))
+		}
+		if r.Mensagem != "" {
+			msg = r.Mensagem
+		}
+	}
+	jogoPublicar(jogo, Evento{Tipo: EventoObjetivoConcluido, Jogador: j, Mensagem: msg})
+}
+
+// Indica se todos os objetivos que encerram a partida foram concluídos
+func (m *Missoes) principaisConcluidos() bool {
+	temPrincipal := false
+	for _, o := range m.Objetivos {
+		if o.Principal {
+			temPrincipal = true
+		}
+	}
+	for _, o := range m.Objetivos {
+		if (o.Principal || !temPrincipal) && !o.Concluido {
+			return false
+		}
+	}
+	return true
+}
+
+// Primeiro objetivo ainda não concluído, exibido junto ao placar
+func (m *Missoes) Pendente() *Objetivo {
+	for _, o := range m.Objetivos {
+		if !o.Concluido {
+			return o
+		}
+	}
+	return nil
+}
+
+// Linhas do registro de missões, uma por objetivo
+func (m *Missoes) Linhas(jogo *Jogo) []string {
+	var linhas []string
+	for _, o := range m.Objetivos {
+		marca := "[ ]"
+		if o.Concluido {
+			marca = "[x]"
+		}
+		texto := marca + " " + o.Texto(jogo)
+		if total := o.Total(jogo); total > 1 {
+			texto += fmt.Sprintf(" (%d/%d)", min(o.Progresso, total), total)
+		}
+		if o.Principal {
+			texto += " *"
+		}
+		linhas = append(linhas, texto)
+	}
+	return linhas
+}
+
+// TelaMissoes é o painel com o registro de missões
+type TelaMissoes struct {
+	menu *Menu
+}
+
+// Abre o registro de missões
+func jogoAbrirMissoes(jogo *Jogo) {
+	t := &TelaMissoes{}
+	fechar := func() { jogoFecharTela(jogo) }
+	t.menu = &Menu{
+		Titulo:   tr("missao.titulo"),
+		Opcoes:   []OpcaoMenu{{Texto: tr("menu.voltar"), Executar: fechar}},
+		AoFechar: fechar,
+	}
+	jogoAbrirTela(jogo, t)
+}
+
+func (t *TelaMissoes) Desenhar(jogo *Jogo) {
+	// o progresso muda enquanto o painel está aberto, então as linhas são refeitas a cada quadro
+	t.menu.Linhas = nil
+	if jogo.Missoes != nil {
+		t.menu.Linhas = jogo.Missoes.Linhas(jogo)
+	}
+	interfaceDesenharMenu(t.menu)
+}
+
+func (t *TelaMissoes) Tecla(jogo *Jogo, atalho AtalhoTecla) {
+	t.menu.Tecla(jogo, atalho)
+}
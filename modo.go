@@ -0,0 +1,199 @@
+// modo.go - Modos de jogo: o que vence e o que perde uma partida
+// As entidades só publicam o que aconteceu (a caixa aberta, o tesouro roubado, o
+// relógio que andou); as regras da partida (jogoIniciarRegras) entregam cada evento ao
+// modo escolhido, que decide se a partida acabou. Há três modos:
+//
+//   - clássico: vence quem cumpre as missões do mapa; perde quem fica sem vida ou, no
+//     cooperativo, quem deixa o monstro roubar todos os tesouros
+//   - contra o relógio: as mesmas missões, com um relógio que conta para trás; cada
+//     caixa aberta dá mais tempo e, se o relógio chegar a zero, a partida está perdida
+//   - sobrevivência: não há vitória, só pontos por segundo vivo; o monstro volta em
+//     ondas cada vez mais rápidas e fortes, e alcançar um jogador sem tesouros para
+//     roubar encerra a partida
+//
+// O modo também cria o monstro e diz quanto ele demora a aparecer. O estado de cada
+// modo é gravado em JSON junto com a partida salva.
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ModoJogo decide como uma partida é vencida e perdida. Todos os métodos são chamados
+// pelo dono do estado.
+type ModoJogo interface {
+	Nome() string                           // nome usado na linha de comando, no perfil e nos salvamentos
+	Iniciar(jogo *Jogo)                     // prepara o modo para uma nova partida, com o mapa já carregado
+	Tratar(jogo *Jogo, ev Evento)           // aplica ao evento as condições de vitória e derrota
+	Monstro(jogo *Jogo) *Monstro            // cria o próximo monstro
+	EsperaMonstro(jogo *Jogo) time.Duration // quanto falta para o próximo monstro aparecer
+	Placar(jogo *Jogo) string               // linha do modo no placar ("": nenhuma)
+}
+
+// Nomes dos modos, na ordem em que aparecem no menu
+const (
+	ModoNomeClassico      = "classico"
+	ModoNomeContraRelogio = "contra-relogio"
+	ModoNomeSobrevivencia = "sobrevivencia"
+)
+
+var ordemModos = []string{ModoNomeClassico, ModoNomeContraRelogio, ModoNomeSobrevivencia}
+
+const ModoPadrao = ModoNomeClassico
+
+// Pontos por segundo vivo na sobrevivência, multiplicados pelo número da onda
+const PontosPorSegundo = 1
+
+// Intervalo mínimo entre os passos do monstro, por mais avançada que seja a onda
+const MonstroVelocidadeMinima = 200 * time.Millisecond
+
+// Cria o modo de jogo com o nome informado, sem estado
+func modoNovo(nome string) (ModoJogo, error) {
+	switch nome {
+	case ModoNomeClassico:
+		return &ModoClassico{}, nil
+	case ModoNomeContraRelogio:
+		return &ModoContraRelogio{}, nil
+	case ModoNomeSobrevivencia:
+		return &ModoSobrevivencia{}, nil
+	}
+	return nil, fmt.Errorf("modo de jogo desconhecido: %q (use %s)", nome, strings.Join(ordemModos, ", "))
+}
+
+// Derrotas comuns aos modos com missões: a armadilha que tira a última vida de quem
+// abriu a caixa e, no cooperativo, o monstro que rouba o último tesouro
+func modoDerrotas(jogo *Jogo, ev Evento) {
+	switch ev.Tipo {
+	case EventoArmadilha:
+		if ev.Jogador == nil || ev.Jogador.Vida.Pontos == 0 {
+			jogoEncerrar(jogo, false, tr("fim.armadilha"))
+		}
+	case EventoTesouroRoubado:
+		if jogo.Disputa == DisputaCooperativa && jogo.Tesouros <= 0 {
+			jogoEncerrar(jogo, false, tr("fim.roubo"))
+		}
+	}
+}
+
+// ModoClassico é o jogo original: as missões do mapa dizem quando a partida é vencida
+type ModoClassico struct{}
+
+func (m *ModoClassico) Nome() string        { return ModoNomeClassico }
+func (m *ModoClassico) Iniciar(jogo *Jogo)  {}
+func (m *ModoClassico) Placar(*Jogo) string { return "" }
+
+func (m *ModoClassico) Tratar(jogo *Jogo, ev Evento) {
+	modoDerrotas(jogo, ev)
+	missoesProcessar(jogo, ev)
+}
+
+func (m *ModoClassico) Monstro(jogo *Jogo) *Monstro {
+	return monstroNovo(jogo)
+}
+
+func (m *ModoClassico) EsperaMonstro(jogo *Jogo) time.Duration {
+	return time.Duration(jogo.Configuracao.MonstroSurge)
+}
+
+// ModoContraRelogio é o clássico com um relógio que conta para trás; cada caixa aberta
+// (com tesouro, vazia ou armadilha) acrescenta tempo a ele
+type ModoContraRelogio struct {
+	ModoClassico
+	Restante time.Duration `json:"restante"`
+}
+
+func (m *ModoContraRelogio) Nome() string { return ModoNomeContraRelogio }
+
+func (m *ModoContraRelogio) Iniciar(jogo *Jogo) {
+	m.Restante = time.Duration(jogo.Configuracao.Tempo)
+}
+
+func (m *ModoContraRelogio) Tratar(jogo *Jogo, ev Evento) {
+	if jogo.FimDeJogo {
+		return
+	}
+	switch ev.Tipo {
+	case EventoTempo:
+		// com o nível vencido, o relógio para enquanto os jogadores vão até o portal
+		if jogoPortaisAbertos(jogo) {
+			return
+		}
+		m.Restante -= ev.Tempo
+		if m.Restante <= 0 {
+			m.Restante = 0
+			jogoEncerrar(jogo, false, tr("fim.tempo"))
+			return
+		}
+	case EventoTesouroEncontrado, EventoCaixaVazia, EventoArmadilha:
+		extra := time.Duration(jogo.Configuracao.TempoCaixa)
+		m.Restante += extra
+		jogo.SetMessage(tr("modo.tempo_extra", int(extra/time.Second)), 2*time.Second)
+	}
+	m.ModoClassico.Tratar(jogo, ev)
+}
+
+func (m *ModoContraRelogio) Placar(*Jogo) string {
+	// arredonda para cima: o relógio só mostra 0:00 quando o tempo acabou
+	return tr("placar.tempo", recordesTempo(Duracao((m.Restante + time.Second - 1).Truncate(time.Second))))
+}
+
+// ModoSobrevivencia dura até o monstro alcançar alguém. A cada onda o monstro anda mais
+// rápido, precisa de mais golpes e volta mais cedo depois de derrotado.
+type ModoSobrevivencia struct {
+	Onda      int           `json:"onda"`
+	Decorrido time.Duration `json:"decorrido"` // tempo de jogo desde o começo da partida
+}
+
+func (m *ModoSobrevivencia) Nome() string { return ModoNomeSobrevivencia }
+
+func (m *ModoSobrevivencia) Iniciar(jogo *Jogo) {
+	m.Onda = 1
+	m.Decorrido = 0
+}
+
+func (m *ModoSobrevivencia) Tratar(jogo *Jogo, ev Evento) {
+	if jogo.FimDeJogo {
+		return
+	}
+	switch ev.Tipo {
+	case EventoTempo:
+		antes := m.Decorrido / time.Second
+		m.Decorrido += ev.Tempo
+		jogo.Pontos += int(m.Decorrido/time.Second-antes) * PontosPorSegundo * m.Onda
+		if m.Decorrido >= time.Duration(m.Onda)*time.Duration(jogo.Configuracao.Onda) {
+			m.Onda++
+			// um monstro que ainda ia demorar aparece no ritmo da nova onda
+			if proximo := time.Now().Add(m.EsperaMonstro(jogo)); !jogo.MonstroAtivo && proximo.Before(jogo.MonstroSpawn) {
+				jogo.MonstroSpawn = proximo
+			}
+			jogoPublicar(jogo, Evento{Tipo: EventoOnda, Quantidade: m.Onda})
+		}
+	case EventoArmadilha:
+		if ev.Jogador == nil || ev.Jogador.Vida.Pontos == 0 {
+			jogoEncerrar(jogo, false, tr("fim.armadilha"))
+		}
+	case EventoJogadorAlcancado:
+		// os tesouros protegem o jogador: o monstro só o pega quando não há o que roubar
+		jogoEncerrar(jogo, false, tr("fim.alcancado", recordesTempo(Duracao(m.Decorrido)), m.Onda))
+	}
+}
+
+func (m *ModoSobrevivencia) Monstro(jogo *Jogo) *Monstro {
+	monstro := monstroNovo(jogo)
+	for range m.Onda - 1 {
+		monstro.Velocidade = monstro.Velocidade * 4 / 5
+	}
+	monstro.Velocidade = max(monstro.Velocidade, MonstroVelocidadeMinima)
+	monstro.Vida = vidaNova(MonstroVida + (m.Onda-1)/2)
+	return monstro
+}
+
+func (m *ModoSobrevivencia) EsperaMonstro(jogo *Jogo) time.Duration {
+	return time.Duration(jogo.Configuracao.MonstroSurge) / time.Duration(max(m.Onda, 1))
+}
+
+func (m *ModoSobrevivencia) Placar(*Jogo) string {
+	return tr("placar.onda", m.Onda, recordesTempo(Duracao(m.Decorrido)))
+}
@@ -1,41 +1,57 @@
 package main
 
 import (
-	"fmt"
+	"log/slog"
 	"math/rand"
-	"sync"
 	"time"
 )
 
 type Monstro struct {
-	X, Y             int
-	Ativo            bool
-	mu               sync.Mutex
-	Velocidade       time.Duration
-	TesourosRoubados int
+	*Posicao
+	*Inventario            // tesouros roubados, devolvidos quando o monstro é derrotado
+	Ocupante    IDEntidade // entidade do monstro no mapa (ecs.go)
+	Vida        *Vida
+	Ativo       bool
+	Velocidade  time.Duration
+	Alvo        *Jogador // jogador mais próximo, que o monstro persegue
 }
 
-func monstroNovo() *Monstro {
+// Golpes necessários para derrotar o monstro
+const MonstroVida = 1
+
+func monstroNovo(jogo *Jogo) *Monstro {
 	return &Monstro{
-		Velocidade: 2 * time.Second,
+		Posicao:    &Posicao{},
+		Inventario: &Inventario{},
+		Vida:       vidaNova(MonstroVida),
+		Velocidade: time.Duration(jogo.Configuracao.MonstroVelocidade),
 	}
 }
 
+// Coloca o monstro no mapa como entidade; a cada passo, sua IA decide a direção pelo
+// retrato do mundo e o dono do estado move o monstro e o faz roubar
 func (m *Monstro) Iniciar(jogo *Jogo) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	m.Ativo = true
 	encontrarPosicaoInicialMonstro(jogo, m)
-
-	go m.comportamento(jogo)
+	slog.Info("monstro surgiu", "x", m.X, "y", m.Y, "velocidade", m.Velocidade)
+
+	o := jogo.Ocupantes
+	m.Ocupante = o.Criar()
+	o.Colocar(m.Ocupante, m.Posicao)
+	o.Donos[m.Ocupante] = m
+	o.Desenhos[m.Ocupante] = &Desenhavel{MonstroElemento, CamadaMonstro, Entidade{"monstro", 0}}
+	o.Bloqueantes[m.Ocupante] = Bloqueante{}
+	o.Inventarios[m.Ocupante] = m.Inventario
+	o.Vidas[m.Ocupante] = m.Vida
+	o.IAs[m.Ocupante] = &IA{Nome: "monstro", Intervalo: m.Velocidade, Decidir: m.decidir}
+	sistemaIAIniciar(jogo, m.Ocupante)
 }
 
 // Atualizar é chamado pelo loop principal para avançar o estado do monstro
 func (m *Monstro) Atualizar(jogo *Jogo) {
 	if m.Ativo {
-		m.mover(jogo)
-		m.roubarTesouro(jogo)
+		dx, dy := monstroDecidir(jogo.Mundo.Retrato(), m.X, m.Y)
+		IntencaoMonstroPasso{m, dx, dy}.Aplicar(jogo)
 	}
 }
 
@@ -45,8 +61,10 @@ func encontrarPosicaoInicialMonstro(jogo *Jogo, m *Monstro) {
 		x := rand.Intn(len(jogo.Mapa[0]))
 		y := rand.Intn(len(jogo.Mapa))
 
-		if jogo.Mapa[y][x] == Vazio &&
-			calculaDistancia(jogo.PosX, jogo.PosY, x, y) > 10 {
+		if jogo.Mapa[y][x] != Vazio || jogo.Ocupantes.Bloqueado(x, y) {
+			continue
+		}
+		if j := jogoJogadorMaisProximo(jogo, x, y); j == nil || calculaDistancia(j.X, j.Y, x, y) > 10 {
 			m.X, m.Y = x, y
 			return
 		}
@@ -54,7 +72,7 @@ func encontrarPosicaoInicialMonstro(jogo *Jogo, m *Monstro) {
 	// Se não encontrar posição ideal, coloca em qualquer lugar vazio
 	for y, linha := range jogo.Mapa {
 		for x, elem := range linha {
-			if elem == Vazio {
+			if elem == Vazio && !jogo.Ocupantes.Bloqueado(x, y) {
 				m.X, m.Y = x, y
 				return
 			}
@@ -62,71 +80,94 @@ func encontrarPosicaoInicialMonstro(jogo *Jogo, m *Monstro) {
 	}
 }
 
-func (m *Monstro) comportamento(jogo *Jogo) {
-	for m.Ativo && !jogo.FimDeJogo {
-		m.mover(jogo)
-		m.roubarTesouro(jogo)
-		time.Sleep(m.Velocidade)
+func (m *Monstro) decidir(r *Retrato, eu Posicao) Intencao {
+	if r.FimDeJogo {
+		return nil
 	}
+	dx, dy := monstroDecidir(r, eu.X, eu.Y)
+	return IntencaoMonstroPasso{m, dx, dy}
 }
 
-func (m *Monstro) mover(jogo *Jogo) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	// 50% de chance de mover na direção do jogador, 50% aleatório
-	var dx, dy int
-	if rand.Intn(2) == 0 {
+// Direção do próximo passo do monstro em (x, y): 50% de chance de ir na direção
+// do jogador mais próximo, 50% aleatória
+func monstroDecidir(r *Retrato, x, y int) (dx, dy int) {
+	alvo := r.JogadorMaisProximo(x, y)
+	if alvo != nil && rand.Intn(2) == 0 {
 		// Movimento inteligente em direção ao jogador
-		if jogo.PosX > m.X {
+		if alvo.X > x {
 			dx = 1
-		} else if jogo.PosX < m.X {
+		} else if alvo.X < x {
 			dx = -1
 		}
-		if jogo.PosY > m.Y {
+		if alvo.Y > y {
 			dy = 1
-		} else if jogo.PosY < m.Y {
+		} else if alvo.Y < y {
 			dy = -1
 		}
-	} else {
-		// Movimento aleatório
-		dx = rand.Intn(3) - 1 // -1, 0 ou 1
-		dy = rand.Intn(3) - 1
+		return dx, dy
 	}
+	// Movimento aleatório
+	return rand.Intn(3) - 1, rand.Intn(3) - 1 // -1, 0 ou 1
+}
 
-	nx, ny := m.X+dx, m.Y+dy
+// IntencaoMonstroPasso pede para o monstro andar (DX, DY) e roubar quem estiver perto
+type IntencaoMonstroPasso struct {
+	Monstro *Monstro
+	DX, DY  int
+}
 
-	if ny >= 0 && ny < len(jogo.Mapa) &&
-		nx >= 0 && nx < len(jogo.Mapa[0]) &&
-		!jogo.Mapa[ny][nx].tangivel {
+func (in IntencaoMonstroPasso) Aplicar(jogo *Jogo) {
+	m := in.Monstro
+	if !m.Ativo || jogo.FimDeJogo {
+		return
+	}
+	m.mover(jogo, in.DX, in.DY)
+	m.roubarTesouro(jogo)
+}
 
-		m.X, m.Y = nx, ny
+func (m *Monstro) mover(jogo *Jogo, dx, dy int) {
+	m.Alvo = jogoJogadorMaisProximo(jogo, m.X, m.Y)
+
+	nx, ny := m.X+dx, m.Y+dy
+
+	if (dx != 0 || dy != 0) && jogoPodeMoverPara(jogo, nx, ny) {
+		jogo.Ocupantes.Mover(m.Ocupante, nx, ny)
 	}
 }
 
 func (m *Monstro) roubarTesouro(jogo *Jogo) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	// Verifica se está perto de algum jogador (distância <= 1)
+	alvo := jogoJogadorMaisProximo(jogo, m.X, m.Y)
+	if alvo == nil || calculaDistancia(alvo.X, alvo.Y, m.X, m.Y) > 1 {
+		return
+	}
 
-	// Verifica se está perto do jogador (distância <= 1)
-	if calculaDistancia(jogo.PosX, jogo.PosY, m.X, m.Y) <= 1 && jogo.Tesouros > 0 {
+	// o monstro só publica o que fez; o modo de jogo decide se a partida acabou (modo.go)
+	switch {
+	case jogo.Disputa == DisputaCompetitiva && alvo.Tesouros > 0:
+		// no modo competitivo o monstro só rouba de quem tem tesouros
+		alvo.Tesouros--
 		jogo.Tesouros--
-		m.TesourosRoubados++
-		jogo.SetMessage(fmt.Sprintf("O monstro roubou um tesouro! (%d restantes)", jogo.Tesouros), 1*time.Minute) 
-
-		if jogo.Tesouros <= 0 {
-			jogo.SetMessage("GAME OVER!\nO monstro roubou TODOS os tesouros!", 30*time.Second)
-			jogo.FimDeJogo = true
-			return
+		m.Tesouros++
+		jogoPublicar(jogo, Evento{Tipo: EventoTesouroRoubado, Jogador: alvo, X: m.X, Y: m.Y, Quantidade: alvo.Tesouros})
+	case jogo.Disputa == DisputaCooperativa && jogo.Tesouros > 0:
+		jogo.Tesouros--
+		if alvo.Tesouros > 0 {
+			alvo.Tesouros--
 		}
+		m.Tesouros++
+		jogoPublicar(jogo, Evento{Tipo: EventoTesouroRoubado, Jogador: alvo, X: m.X, Y: m.Y, Quantidade: jogo.Tesouros})
+	default:
+		jogoPublicar(jogo, Evento{Tipo: EventoJogadorAlcancado, Jogador: alvo, X: m.X, Y: m.Y})
 	}
 }
 
-func (m *Monstro) derrotar(jogo *Jogo) {
-	if m.TesourosRoubados > 0 {
-		jogo.Tesouros += m.TesourosRoubados
-		jogo.SetMessage(fmt.Sprintf("Você recuperou %d tesouros!", m.TesourosRoubados), 3*time.Second)
-		m.TesourosRoubados = 0
-	}
+func (m *Monstro) derrotar(jogo *Jogo, j *Jogador) {
+	recuperados := m.Tesouros
+	jogo.Tesouros += recuperados
+	m.Tesouros = 0
 	m.Ativo = false
-}
\ No newline at end of file
+	jogo.Ocupantes.Remover(m.Ocupante)
+	slog.Info("monstro derrotado", "x", m.X, "y", m.Y, "jogador", j.ID, "recuperados", recuperados)
+	jogoPublicar(jogo, Evento{Tipo: EventoMonstroDerrotado, Jogador: j, X: m.X, Y: m.Y, Quantidade: recuperados})
+}
@@ -0,0 +1,161 @@
+// mundo.go - Dono único do estado do mundo
+// O estado da partida (mapa, jogadores, caixas, monstro, tesouros...) pertence a uma só
+// goroutine: o loop principal (jogar), que também lê o teclado, desenha a tela e troca
+// de partida. As goroutines das entidades e da rede não mexem nele: mandam intenções por
+// um canal, que o dono aplica uma de cada vez, e decidem o que fazer a partir de um
+// retrato somente leitura que o dono publica depois de cada mudança.
+package main
+
+import (
+	"sync/atomic"
+)
+
+// Intencao é um pedido de mudança no mundo, aplicado pela goroutine dona do estado
+type Intencao interface {
+	Aplicar(jogo *Jogo)
+}
+
+// IntencaoFunc permite usar uma função comum como intenção
+type IntencaoFunc func(jogo *Jogo)
+
+func (f IntencaoFunc) Aplicar(jogo *Jogo) {
+	f(jogo)
+}
+
+// Mundo liga as goroutines ao dono do estado: recebe as intenções e guarda o último retrato
+type Mundo struct {
+	intencoes chan Intencao
+	retrato   atomic.Pointer[Retrato]
+}
+
+func mundoNovo() *Mundo {
+	m := &Mundo{intencoes: make(chan Intencao)}
+	m.retrato.Store(&Retrato{})
+	return m
+}
+
+// Entrega uma intenção ao dono do estado. O canal não tem fila: quando Enviar retorna
+// true, o dono já recebeu a intenção e a aplica antes de qualquer troca de partida.
+// Retorna false se cancelado antes disso (a partida acabou ou o jogo está saindo).
+func (m *Mundo) Enviar(in Intencao, cancelado <-chan struct{}) bool {
+	select {
+	case m.intencoes <- in:
+		return true
+	case <-cancelado:
+		return false
+	}
+}
+
+// Canal lido pelo dono do estado
+func (m *Mundo) Intencoes() <-chan Intencao {
+	return m.intencoes
+}
+
+// Último retrato publicado; nunca é nil, mas fica sem mapa entre uma partida e outra
+func (m *Mundo) Retrato() *Retrato {
+	return m.retrato.Load()
+}
+
+// PosicaoRetrato é um jogador no retrato
+type PosicaoRetrato struct {
+	ID       int
+	X, Y     int
+	Tesouros int
+	Vida     int
+}
+
+// OcupanteRetrato é uma entidade do mapa no retrato, com cópias dos seus componentes
+type OcupanteRetrato struct {
+	ID       IDEntidade
+	Posicao  Posicao
+	Desenho  *Desenhavel // nil se a entidade não aparece no mapa
+	Bloqueia bool
+}
+
+// Retrato é uma cópia somente leitura do mundo; ninguém deve alterá-lo depois de publicado
+type Retrato struct {
+	Mapa      [][]Elemento // só o terreno; o que está em cima dele são os Ocupantes
+	Jogadores []PosicaoRetrato
+	Ocupantes []OcupanteRetrato  // na ordem em que as entidades foram criadas
+	indices   map[IDEntidade]int // posição de cada entidade em Ocupantes
+	bloqueios map[Posicao]bool   // células com entidades bloqueantes
+	Tesouros  int
+	Meta      int
+	Pontos    int // da sessão, somando os níveis já vencidos da campanha
+	Disputa   Disputa
+	Placar    string // linha do modo de jogo no placar (modo.go)
+	FimDeJogo bool
+}
+
+// Publica o retrato do estado atual (chamada pelo dono do estado)
+func jogoRetratar(jogo *Jogo) {
+	r := &Retrato{
+		Mapa:      make([][]Elemento, len(jogo.Mapa)),
+		Tesouros:  jogo.Tesouros,
+		Meta:      jogo.MetaTesouros,
+		Pontos:    jogoPontosTotal(jogo),
+		Disputa:   jogo.Disputa,
+		Placar:    jogo.Modo.Placar(jogo),
+		FimDeJogo: jogo.FimDeJogo,
+		indices:   make(map[IDEntidade]int),
+		bloqueios: make(map[Posicao]bool),
+	}
+	for y, linha := range jogo.Mapa {
+		r.Mapa[y] = append([]Elemento(nil), linha...)
+	}
+	for _, j := range jogo.Jogadores {
+		r.Jogadores = append(r.Jogadores, PosicaoRetrato{ID: j.ID, X: j.X, Y: j.Y, Tesouros: j.Tesouros, Vida: j.Vida.Pontos})
+	}
+	o := jogo.Ocupantes
+	for _, id := range o.ids() {
+		ocupante := OcupanteRetrato{ID: id, Posicao: *o.Posicoes[id]}
+		if d, ok := o.Desenhos[id]; ok {
+			copia := *d
+			ocupante.Desenho = &copia
+		}
+		_, ocupante.Bloqueia = o.Bloqueantes[id]
+		r.indices[id] = len(r.Ocupantes)
+		r.Ocupantes = append(r.Ocupantes, ocupante)
+		if ocupante.Bloqueia {
+			r.bloqueios[ocupante.Posicao] = true
+		}
+	}
+	jogo.Mundo.retrato.Store(r)
+}
+
+// Descarta o retrato da partida que terminou, para nenhuma goroutine da partida
+// seguinte decidir com base no mapa antigo
+func jogoApagarRetrato(jogo *Jogo) {
+	jogo.Mundo.retrato.Store(&Retrato{})
+}
+
+// Indica se (x, y) está dentro do mapa e não é tangível
+func (r *Retrato) Passavel(x, y int) bool {
+	return y >= 0 && y < len(r.Mapa) && x >= 0 && x < len(r.Mapa[y]) && !r.Mapa[y][x].tangivel
+}
+
+// Como jogoPodeMoverPara: passável e sem uma entidade bloqueante na célula
+func (r *Retrato) Livre(x, y int) bool {
+	return r.Passavel(x, y) && !r.bloqueios[Posicao{x, y}]
+}
+
+// Posição da entidade no retrato; false se ela não estava no mapa quando o retrato foi tirado
+func (r *Retrato) Posicao(id IDEntidade) (Posicao, bool) {
+	i, ok := r.indices[id]
+	if !ok {
+		return Posicao{}, false
+	}
+	return r.Ocupantes[i].Posicao, true
+}
+
+// Jogador mais próximo de (x, y), ou nil se não há nenhum
+func (r *Retrato) JogadorMaisProximo(x, y int) *PosicaoRetrato {
+	var maisProximo *PosicaoRetrato
+	for i := range r.Jogadores {
+		j := &r.Jogadores[i]
+		if maisProximo == nil || calculaDistancia(j.X, j.Y, x, y) < calculaDistancia(maisProximo.X, maisProximo.Y, x, y) {
+			maisProximo = j
+		}
+	}
+	return maisProximo
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Muitas caixas e monstros mandam intenções ao mesmo tempo em que outras goroutines
+// leem os retratos; o dono do estado é o único a aplicá-las. Rode com -race.
+func TestMundoIntencoesConcorrentes(t *testing.T) {
+	jogo := jogoNovo()
+	config := *jogo.Configuracao
+	config.CaixaMove = Duracao(time.Millisecond)
+	config.MonstroVelocidade = Duracao(time.Millisecond)
+	config.NPCIntervalo = Duracao(time.Millisecond)
+	config.Caixas = 40
+	jogo.Configuracao = &config
+	jogo.Semente = 1
+	if err := jogoCarregarMapa("mapa.txt", &jogo); err != nil {
+		t.Fatal(err)
+	}
+	for range 20 {
+		m := monstroNovo(&jogo)
+		m.Iniciar(&jogo)
+	}
+	jogo.Guian = npcIniciar(&jogo)
+	jogoRetratar(&jogo)
+
+	// leitores dos retratos, como as IAs e o envio pela rede
+	parar := make(chan struct{})
+	var leitores sync.WaitGroup
+	for range 8 {
+		leitores.Add(1)
+		go func() {
+			defer leitores.Done()
+			for {
+				select {
+				case <-parar:
+					return
+				default:
+				}
+				r := jogo.Mundo.Retrato()
+				for _, o := range r.Ocupantes {
+					r.Posicao(o.ID)
+					r.Livre(o.Posicao.X, o.Posicao.Y)
+				}
+				if len(r.Mapa) > 0 {
+					r.JogadorMaisProximo(rand.Intn(len(r.Mapa[0])), rand.Intn(len(r.Mapa)))
+				}
+			}
+		}()
+	}
+
+	// o dono do estado aplica as intenções e publica um retrato depois de cada uma
+	aplicadas := 0
+	fim := time.After(500 * time.Millisecond)
+	for rodando := true; rodando; {
+		select {
+		case in := <-jogo.Mundo.Intencoes():
+			in.Aplicar(&jogo)
+			aplicadas++
+		case <-fim:
+			rodando = false
+		}
+		jogoRetratar(&jogo)
+	}
+	close(parar)
+	leitores.Wait()
+	if err := jogoEncerrarEntidades(&jogo); err != nil {
+		t.Fatal(err)
+	}
+	if aplicadas == 0 {
+		t.Fatal("nenhuma intenção foi aplicada")
+	}
+
+	// a grade espacial continua de acordo com as posições das entidades
+	o := jogo.Ocupantes
+	for id, p := range o.Posicoes {
+		if !slices.Contains(o.Em(p.X, p.Y), id) {
+			t.Errorf("entidade %d em (%d, %d) fora da grade", id, p.X, p.Y)
+		}
+	}
+	t.Logf("%d intenções aplicadas", aplicadas)
+}
@@ -2,36 +2,15 @@
 package main
 
 import (
-	"sync"
 	"time"
+
 	"jogo/util"
 )
 
 // NPCGuian representa o estado do NPC guia
 type NPCGuian struct {
-	PosX, PosY int        // Posição atual do NPC
-	Ativo      bool       // Indica se o NPC está ativo
-	mu         sync.Mutex // Mutex para sincronização de acesso ao NPC
-}
-
-func (npc *NPCGuian) IniciarSeguidor(jogo *Jogo, parar <-chan struct{}) {
-	ticker := time.NewTicker(10 * time.Millisecond) // move a cada 500ms
-
-	go func() {
-		for {
-			select {
-			case <-ticker.C:
-				npc.seguirPersonagem(jogo)
-			case <-parar:
-				ticker.Stop()
-				return
-			}
-		}
-	}()
-}
-
-func (npc *NPCGuian) seguirPersonagem(jogo *Jogo) {
-	panic("unimplemented")
+	*Posicao            // Posição atual do NPC
+	Ocupante IDEntidade // entidade do NPC no mapa (ecs.go)
 }
 
 // Elemento visual do NPC Guian
@@ -42,170 +21,174 @@ var (
 // Inicia o NPC em uma posição válida próxima ao jogador
 func npcIniciar(jogo *Jogo) *NPCGuian {
 	// Cria um novo NPC
-	npc := &NPCGuian{
-		Ativo: true,
-	}
+	npc := npcNovo(jogo)
+
+	// Inicia a IA que controla o movimento do NPC
+	npc.Iniciar(jogo)
+
+	return npc
+}
+
+// Cria o NPC como entidade do mapa, sem IA. O Guian não bloqueia a passagem: os
+// jogadores podem passar por ele.
+func npcNovo(jogo *Jogo) *NPCGuian {
+	npc := &NPCGuian{Posicao: &Posicao{}}
 
 	// Encontra uma posição inicial válida para o NPC (próxima ao jogador)
 	encontrarPosicaoInicial(jogo, npc)
 
-	// Inicia a goroutine que controla o movimento do NPC
-	go npcExecutar(jogo, npc)
-
+	o := jogo.Ocupantes
+	npc.Ocupante = o.Criar()
+	o.Colocar(npc.Ocupante, npc.Posicao)
+	o.Donos[npc.Ocupante] = npc
+	o.Desenhos[npc.Ocupante] = &Desenhavel{NPC, CamadaGuian, Entidade{"guian", 0}}
+	o.Interativos[npc.Ocupante] = &Interativo{npc.interagir}
 	return npc
 }
 
-// Encontra uma posição válida para o NPC iniciar (próxima ao jogador)
-func encontrarPosicaoInicial(jogo *Jogo, npc *NPCGuian) {
-	// Direções possíveis para verificar (acima, direita, abaixo, esquerda)
-	direcoes := [][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
-
-	// Verifica cada direção
-	for _, dir := range direcoes {
-		dx, dy := dir[0], dir[1]
-		nx, ny := jogo.PosX+dx, jogo.PosY+dy
-
-		// Se a posição for válida, coloca o NPC lá
-		if jogoPodeMoverPara(jogo, nx, ny) {
-			npc.PosX = nx
-			npc.PosY = ny
-			return
-		}
+// Interação com o Guian: conversa com o jogador. Jogadores remotos (ou jogos sem
+// diálogos, reproduções e partidas sem tela, em que ninguém escolheria as respostas)
+// não veem o painel de conversa e recebem só a dica.
+func (npc *NPCGuian) interagir(jogo *Jogo, j *Jogador) {
+	if j.Remoto || jogo.Dialogos == nil || jogo.SemConversas {
+		jogo.SetMessage(tr("dialogo.guian", getDica(jogo, j)), 3*time.Second)
+	} else {
+		jogoConversar(jogo, j)
 	}
+	jogoPublicar(jogo, Evento{Tipo: EventoConversa, Jogador: j, X: j.X, Y: j.Y})
+}
 
-	// Se nenhuma posição adjacente for válida, coloca o NPC em algum lugar por perto
-	// Tenta em um raio maior
-	for r := 2; r < 5; r++ {
-		for x := -r; x <= r; x++ {
-			for y := -r; y <= r; y++ {
-				if x == 0 && y == 0 {
-					//continue // Pula a posição do jogador
-				}
-
-				nx, ny := jogo.PosX+x, jogo.PosY+y
-				if jogoPodeMoverPara(jogo, nx, ny) {
-					npc.PosX = nx
-					npc.PosY = ny
-					return
-				}
-			}
-		}
+// Encontra uma posição válida para o NPC iniciar (próxima ao jogador)
+func encontrarPosicaoInicial(jogo *Jogo, npc *NPCGuian) {
+	j := jogo.Jogadores[0]
+	if nx, ny, ok := jogoPosicaoLivreProxima(jogo, j.X, j.Y); ok {
+		npc.X = nx
+		npc.Y = ny
 	}
 }
 
-// Move o NPC em direção ao jogador
-func npcMoverEmDirecaoAoJogador(jogo *Jogo, npc *NPCGuian) {
-	npc.mu.Lock()
-	defer npc.mu.Unlock()
-
+// Direção do passo do NPC em (x, y) até o alvo, pelo retrato do mundo
+func npcDirecao(r *Retrato, x, y int, alvo *PosicaoRetrato) (int, int) {
 	// Calcula a direção para o jogador
 	dx := 0
-	if jogo.PosX > npc.PosX {
+	if alvo.X > x {
 		dx = 1
-	} else if jogo.PosX < npc.PosX {
+	} else if alvo.X < x {
 		dx = -1
 	}
 
 	dy := 0
-	if jogo.PosY > npc.PosY {
+	if alvo.Y > y {
 		dy = 1
-	} else if jogo.PosY < npc.PosY {
+	} else if alvo.Y < y {
 		dy = -1
 	}
 
-	// Tenta mover na direção horizontal ou vertical (prefere o movimento mais distante)
-	nx, ny := npc.PosX, npc.PosY
-
 	// Verifica diferença absoluta nas coordenadas
-	diffX := util.Abs(jogo.PosX - npc.PosX)
-	diffY := util.Abs(jogo.PosY - npc.PosY)
+	diffX := util.Abs(alvo.X - x)
+	diffY := util.Abs(alvo.Y - y)
 
 	// Tenta mover primeiro na direção com maior diferença
 	if diffX > diffY {
 		// Tenta mover horizontalmente primeiro
-		if jogoPodeMoverPara(jogo, npc.PosX+dx, npc.PosY) {
-			nx = npc.PosX + dx
-		} else if dy != 0 && jogoPodeMoverPara(jogo, npc.PosX, npc.PosY+dy) {
-			ny = npc.PosY + dy
+		if r.Livre(x+dx, y) {
+			return dx, 0
+		} else if dy != 0 && r.Livre(x, y+dy) {
+			return 0, dy
 		}
 	} else {
 		// Tenta mover verticalmente primeiro
-		if jogoPodeMoverPara(jogo, npc.PosX, npc.PosY+dy) {
-			ny = npc.PosY + dy
-		} else if dx != 0 && jogoPodeMoverPara(jogo, npc.PosX+dx, npc.PosY) {
-			nx = npc.PosX + dx
+		if r.Livre(x, y+dy) {
+			return 0, dy
+		} else if dx != 0 && r.Livre(x+dx, y) {
+			return dx, 0
 		}
 	}
+	return 0, 0
+}
 
-	// Atualiza a posição se houve movimento
-	npc.PosX, npc.PosY = nx, ny
+// IntencaoGuianPasso pede para o Guian andar (DX, DY) e, se Dica, dar a dica do jogador mais próximo
+type IntencaoGuianPasso struct {
+	Guian  *NPCGuian
+	DX, DY int
+	Dica   bool
 }
 
-// Goroutine que executa o comportamento do NPC
-func npcExecutar(jogo *Jogo, npc *NPCGuian) {
-	// Intervalo de atualização (X segundos)
-	intervalo := 500 * time.Millisecond
-
-	// Loop principal do NPC
-	for npc.Ativo {
-		// Move o NPC em direção ao jogador
-		npcMoverEmDirecaoAoJogador(jogo, npc)
-		if jogo.GetMessage() == "" {
-            jogo.SetMessage("Guian: "+getDica(jogo), 2*time.Second)
-        }
-        
-		// Pausa pelo intervalo definido
-		time.Sleep(intervalo)
+func (in IntencaoGuianPasso) Aplicar(jogo *Jogo) {
+	npc := in.Guian
+	if jogo.Guian != npc {
+		return
+	}
+	// o retrato pode estar atrasado: o destino é conferido no estado atual
+	if (in.DX != 0 || in.DY != 0) && jogoPodeMoverPara(jogo, npc.X+in.DX, npc.Y+in.DY) {
+		jogo.Ocupantes.Mover(npc.Ocupante, npc.X+in.DX, npc.Y+in.DY)
+	}
+	// a dica tem a menor prioridade e substitui a anterior, sem encher o histórico
+	if alvo := jogoJogadorMaisProximo(jogo, npc.X, npc.Y); in.Dica && alvo != nil {
+		jogo.Mensagens.Adicionar(Mensagem{
+			Texto:      tr("dialogo.guian", getDica(jogo, alvo)),
+			Prioridade: PrioridadeDica,
+			Chave:      "dica",
+			Expira:     time.Now().Add(2 * time.Second),
+		})
 	}
 }
 
-// Desenha o NPC no mapa
-func npcDesenhar(jogo *Jogo, npc *NPCGuian) {
-	npc.mu.Lock()
-	defer npc.mu.Unlock()
-
-	// Sobrescreve temporariamente com o NPC
-	interfaceDesenharElemento(npc.PosX, npc.PosY, NPC)
+// Inicia a IA do NPC: a cada intervalo (da dificuldade escolhida), escolhe o passo em
+// direção ao jogador pelo retrato; o dono do estado move o NPC e dá a dica
+func (npc *NPCGuian) Iniciar(jogo *Jogo) {
+	jogo.Ocupantes.IAs[npc.Ocupante] = &IA{
+		Nome:      "guian",
+		Intervalo: time.Duration(jogo.Configuracao.NPCIntervalo),
+		Decidir: func(r *Retrato, eu Posicao) Intencao {
+			var dx, dy int
+			if alvo := r.JogadorMaisProximo(eu.X, eu.Y); alvo != nil {
+				dx, dy = npcDirecao(r, eu.X, eu.Y, alvo)
+			}
+			return IntencaoGuianPasso{npc, dx, dy, true}
+		},
+	}
+	sistemaIAIniciar(jogo, npc.Ocupante)
 }
 
 // calculaDistancia retorna a distância de Manhattan entre duas posições
 func calculaDistancia(x1, y1, x2, y2 int) int {
-    return util.Abs(x1-x2) + util.Abs(y1-y2)
+	return util.Abs(x1-x2) + util.Abs(y1-y2)
 }
 
 // encontraCaixaTesouroMaisProxima retorna a caixa tesouro mais próxima do jogador
-func encontraCaixaTesouroMaisProxima(jogo *Jogo) *Caixa {
-    var maisProxima *Caixa
-    menorDistancia := 9999 // valor inicial alto
-
-    for _, caixa := range jogo.Caixas {
-        if caixa.Tipo == TESOURO && !caixa.Removida {
-            dist := calculaDistancia(jogo.PosX, jogo.PosY, caixa.X, caixa.Y)
-            if dist < menorDistancia {
-                menorDistancia = dist
-                maisProxima = caixa
-            }
-        }
-    }
-    return maisProxima
+func encontraCaixaTesouroMaisProxima(jogo *Jogo, j *Jogador) *Caixa {
+	caixa, _ := ocupanteMaisProximo(jogo.Ocupantes, j.X, j.Y, func(c *Caixa) bool {
+		return c.Tipo == TESOURO
+	})
+	return caixa
+}
+
+// encontraCaixaFechadaMaisProxima retorna a caixa ainda fechada e não revelada mais próxima do jogador
+func encontraCaixaFechadaMaisProxima(jogo *Jogo, j *Jogador) *Caixa {
+	caixa, _ := ocupanteMaisProximo(jogo.Ocupantes, j.X, j.Y, func(c *Caixa) bool {
+		return !c.Revelada
+	})
+	return caixa
 }
 
 // getDica retorna a dica baseada na distância do jogador para a caixa tesouro mais próxima
-func getDica(jogo *Jogo) string {
-    caixa := encontraCaixaTesouroMaisProxima(jogo)
-    if caixa == nil {
-        return "FRIO (nenhum tesouro encontrado)"
-    }
-
-    dist := calculaDistancia(jogo.PosX, jogo.PosY, caixa.X, caixa.Y)
-    switch {
-    case dist <= 1:
-        return "QUENTE! (TESOURO BEM PERTO!)"
-    case dist <= 3:
-        return "QUENTE"
-    case dist <= 6:
-        return "MORNO"
-    default:
-        return "FRIO"
-    }
-}
\ No newline at end of file
+func getDica(jogo *Jogo, j *Jogador) string {
+	caixa := encontraCaixaTesouroMaisProxima(jogo, j)
+	if caixa == nil {
+		return tr("dica.nenhum")
+	}
+
+	dist := calculaDistancia(j.X, j.Y, caixa.X, caixa.Y)
+	limites := jogo.Configuracao.Dicas
+	switch {
+	case dist <= limites[0]:
+		return tr("dica.muito_quente")
+	case dist <= limites[1]:
+		return tr("dica.quente")
+	case dist <= limites[2]:
+		return tr("dica.morno")
+	default:
+		return tr("dica.frio")
+	}
+}
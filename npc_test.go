@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+// Numa reprodução (ou sem tela) falar com o Guian não pausa o jogo nem abre o painel
+func TestConversaSemTela(t *testing.T) {
+	jogo := jogoNovo()
+	jogo.Dialogos = dialogosPadrao()
+	if err := jogoCarregarMapa("mapa.txt", &jogo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogoEncerrarEntidades(&jogo) })
+	jogo.Guian = npcIniciar(&jogo)
+	j := jogo.Jogadores[0]
+
+	jogo.SemConversas = true
+	jogo.Guian.interagir(&jogo, j)
+	if len(jogo.Telas) != 0 || jogo.Pausa.Ativa() {
+		t.Fatalf("conversa aberta sem ninguém para responder: %d telas, pausado: %v", len(jogo.Telas), jogo.Pausa.Ativa())
+	}
+
+	jogo.SemConversas = false
+	jogo.Guian.interagir(&jogo, j)
+	if _, ok := jogoTelaAtual(&jogo).(*TelaDialogo); !ok || !jogo.Pausa.Ativa() {
+		t.Errorf("conversa não aberta: tela %T, pausado: %v", jogoTelaAtual(&jogo), jogo.Pausa.Ativa())
+	}
+	jogoRetomar(&jogo)
+}
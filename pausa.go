@@ -0,0 +1,65 @@
+// pausa.go - Controle de pausa compartilhado por todas as goroutines do jogo
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Pausa congela as goroutines das entidades enquanto o jogo está pausado.
+// Cada goroutine chama Esperar antes de agir e fica bloqueada até a partida ser retomada.
+type Pausa struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pausado bool
+	desde   time.Time // quando a pausa começou
+}
+
+// Cria um controle de pausa com o jogo em andamento
+func pausaNova() *Pausa {
+	p := &Pausa{}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// Pausa o jogo; não faz nada se ele já estiver pausado
+func (p *Pausa) Pausar() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.pausado {
+		p.pausado = true
+		p.desde = time.Now()
+	}
+}
+
+// Retoma o jogo, liberando as goroutines bloqueadas.
+// Retorna por quanto tempo o jogo ficou pausado.
+func (p *Pausa) Retomar() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.pausado {
+		return 0
+	}
+	p.pausado = false
+	p.cond.Broadcast()
+	return time.Since(p.desde)
+}
+
+// Indica se o jogo está pausado
+func (p *Pausa) Ativa() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pausado
+}
+
+// Bloqueia enquanto o jogo estiver pausado
+func (p *Pausa) Esperar() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for p.pausado {
+		p.cond.Wait()
+	}
+}
@@ -0,0 +1,430 @@
+// perfil.go - Perfis de jogador: configurações e progresso de cada um
+// Cada perfil tem uma pasta de configuração (tema, idioma, dificuldade, modo de jogo e
+// teclas) e uma pasta de dados (recordes, conquistas e o andamento da campanha), nas
+// pastas indicadas pelo padrão XDG:
+//
+//	$XDG_CONFIG_HOME/jogo-concorrente/perfis/<nome>/  (padrão: ~/.config)
+//	$XDG_DATA_HOME/jogo-concorrente/perfis/<nome>/    (padrão: ~/.local/share)
+//
+// O último perfil escolhido fica em perfis.json, na pasta de configuração, e volta a ser
+// usado na próxima vez. As opções da linha de comando valem mais que as do perfil.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Pasta do jogo dentro das pastas de configuração e de dados do usuário
+const PastaJogo = "jogo-concorrente"
+
+// Perfil usado quando nenhum foi escolhido
+const PerfilPadrao = "jogador"
+
+// Tamanho máximo do nome de um perfil
+const MaximoNomePerfil = 20
+
+// ConfiguracaoPerfil são as escolhas do jogador guardadas no perfil; vazias, valem
+// os padrões do jogo
+type ConfiguracaoPerfil struct {
+	Tema        string `json:"tema,omitempty"`
+	Idioma      string `json:"idioma,omitempty"`
+	Dificuldade string `json:"dificuldade,omitempty"`
+	Modo        string `json:"modo,omitempty"`
+}
+
+// Perfil é um jogador, com suas configurações e as pastas onde ficam seus arquivos
+type Perfil struct {
+	Nome         string
+	Configuracao ConfiguracaoPerfil
+	config       string // pasta das configurações do perfil
+	dados        string // pasta do progresso do perfil
+}
+
+// Pastas de configuração e de dados do jogo, segundo o padrão XDG. No Windows, as
+// configurações ficam em %AppData% e os dados em %LocalAppData%.
+func perfisPastas() (config, dados string, err error) {
+	if config, err = os.UserConfigDir(); err != nil {
+		return "", "", err
+	}
+	dados = os.Getenv("XDG_DATA_HOME")
+	if runtime.GOOS == "windows" && dados == "" {
+		dados = os.Getenv("LocalAppData")
+	}
+	// caminhos relativos são ignorados, como pede o padrão
+	if dados == "" || !filepath.IsAbs(dados) {
+		casa, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", err
+		}
+		dados = filepath.Join(casa, ".local", "share")
+	}
+	return filepath.Join(config, PastaJogo), filepath.Join(dados, PastaJogo), nil
+}
+
+// Confere se o nome pode ser usado como perfil (e como nome de pasta)
+func perfilValidarNome(nome string) error {
+	if nome == "" {
+		return errors.New(tr("perfil.nome_vazio"))
+	}
+	if len([]rune(nome)) > MaximoNomePerfil {
+		return errors.New(tr("perfil.nome_longo", MaximoNomePerfil))
+	}
+	for _, c := range nome {
+		if !perfilCaractereValido(c) {
+			return errors.New(tr("perfil.nome_invalido", nome))
+		}
+	}
+	return nil
+}
+
+// Letras, números, '-' e '_'
+func perfilCaractereValido(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '-' || c == '_'
+}
+
+// Nomes dos perfis existentes, em ordem alfabética
+func perfisListar() ([]string, error) {
+	config, _, err := perfisPastas()
+	if err != nil {
+		return nil, err
+	}
+	entradas, err := os.ReadDir(filepath.Join(config, "perfis"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var nomes []string
+	for _, e := range entradas {
+		if e.IsDir() && perfilValidarNome(e.Name()) == nil {
+			nomes = append(nomes, e.Name())
+		}
+	}
+	return nomes, nil
+}
+
+// Abre o perfil, criando as pastas dele se ainda não existem
+func perfilAbrir(nome string) (*Perfil, error) {
+	if err := perfilValidarNome(nome); err != nil {
+		return nil, err
+	}
+	config, dados, err := perfisPastas()
+	if err != nil {
+		return nil, err
+	}
+	p := &Perfil{
+		Nome:   nome,
+		config: filepath.Join(config, "perfis", nome),
+		dados:  filepath.Join(dados, "perfis", nome),
+	}
+	_, err = os.Stat(p.config)
+	novo := errors.Is(err, fs.ErrNotExist)
+	for _, pasta := range []string{p.config, p.dados} {
+		if err := os.MkdirAll(pasta, 0755); err != nil {
+			return nil, err
+		}
+	}
+	if novo && nome == PerfilPadrao {
+		if err := p.migrar(); err != nil {
+			os.RemoveAll(p.config) // tenta de novo na próxima vez
+			return nil, fmt.Errorf("copiando os arquivos da pasta atual para o perfil: %w", err)
+		}
+	}
+
+	arquivo := filepath.Join(p.config, "perfil.json")
+	conteudo, err := os.ReadFile(arquivo)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(conteudo, &p.Configuracao); err != nil {
+		return nil, fmt.Errorf("%s: %w", arquivo, err)
+	}
+	return p, nil
+}
+
+// Copia para o perfil as teclas, os recordes e as conquistas que as versões sem perfis
+// guardavam na pasta atual. É feito uma vez só, quando o perfil padrão é criado; os
+// arquivos da pasta atual continuam lá, para quem jogar com -teclas, -recordes e -progresso.
+func (p *Perfil) migrar() error {
+	arquivos := []struct{ antigo, novo string }{
+		{"teclas.json", p.Teclas()},
+		{ArquivoRecordes, p.Recordes()},
+		{ArquivoProgresso, p.Progresso()},
+	}
+	for _, a := range arquivos {
+		dados, err := os.ReadFile(a.antigo)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := gravarAtomico(a.novo, dados); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cria um perfil novo com as configurações informadas
+func perfilCriar(nome string, c ConfiguracaoPerfil) (*Perfil, error) {
+	nomes, err := perfisListar()
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(nomes, nome) {
+		return nil, errors.New(tr("perfil.existe", nome))
+	}
+	p, err := perfilAbrir(nome)
+	if err != nil {
+		return nil, err
+	}
+	p.Configuracao = c
+	return p, p.Salvar()
+}
+
+// Apaga as pastas do perfil, com as configurações e o progresso dele
+func perfilExcluir(nome string) error {
+	if err := perfilValidarNome(nome); err != nil {
+		return err
+	}
+	config, dados, err := perfisPastas()
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(config, "perfis", nome)); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(dados, "perfis", nome))
+}
+
+// Escolha do perfil guardada entre as sessões
+type perfisEscolha struct {
+	Atual string `json:"atual"`
+}
+
+// Último perfil escolhido, ou o perfil padrão
+func perfilAtual() string {
+	config, _, err := perfisPastas()
+	if err != nil {
+		return PerfilPadrao
+	}
+	var e perfisEscolha
+	dados, err := os.ReadFile(filepath.Join(config, "perfis.json"))
+	if err != nil || json.Unmarshal(dados, &e) != nil || perfilValidarNome(e.Atual) != nil {
+		return PerfilPadrao
+	}
+	return e.Atual
+}
+
+// Guarda o perfil escolhido para as próximas sessões
+func perfilLembrar(nome string) error {
+	config, _, err := perfisPastas()
+	if err != nil {
+		return err
+	}
+	dados, err := json.MarshalIndent(perfisEscolha{nome}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return gravarAtomico(filepath.Join(config, "perfis.json"), dados)
+}
+
+// Grava as configurações do perfil
+func (p *Perfil) Salvar() error {
+	dados, err := json.MarshalIndent(p.Configuracao, "", "  ")
+	if err != nil {
+		return err
+	}
+	return gravarAtomico(filepath.Join(p.config, "perfil.json"), dados)
+}
+
+// Arquivos do perfil
+func (p *Perfil) Teclas() string    { return filepath.Join(p.config, "teclas.json") }
+func (p *Perfil) Recordes() string  { return filepath.Join(p.dados, ArquivoRecordes) }
+func (p *Perfil) Progresso() string { return filepath.Join(p.dados, ArquivoProgresso) }
+func (p *Perfil) Andamento() string { return filepath.Join(p.dados, "campanha.json") }
+
+// Completa as opções com as do perfil. As configurações valem quando a opção ficou
+// vazia; os arquivos, quando a opção não foi dada na linha de comando.
+func (p *Perfil) Aplicar(o *OpcoesJogo) {
+	c := p.Configuracao
+	if o.Tema == "" {
+		o.Tema = c.Tema
+	}
+	if o.Idioma == "" {
+		o.Idioma = c.Idioma
+	}
+	// um arquivo de configuração escolhe a própria dificuldade
+	if o.Dificuldade == "" && o.Configuracao == "" {
+		o.Dificuldade = c.Dificuldade
+	}
+	if o.Modo == "" {
+		o.Modo = c.Modo
+	}
+	if !o.Escolhidas["teclas"] {
+		o.Teclas = p.Teclas()
+	}
+	if !o.Escolhidas["recordes"] {
+		o.Recordes = p.Recordes()
+	}
+	if !o.Escolhidas["progresso"] {
+		o.Progresso = p.Progresso()
+	}
+	o.Andamento = p.Andamento()
+}
+
+// Menu dos perfis, aberto pela tela de título: escolher, criar e excluir
+func menuPerfis(s *Sessao) *Menu {
+	jogo := s.Jogo
+	fechar := func() { jogoFecharTela(jogo) }
+	nomes, err := perfisListar()
+	if err != nil {
+		jogo.SetMessage(tr("perfil.erro", err), 5*time.Second)
+	}
+	if !slices.Contains(nomes, s.Perfil.Nome) {
+		nomes = append(nomes, s.Perfil.Nome)
+		slices.Sort(nomes)
+	}
+
+	var menu *Menu
+	var opcoes []OpcaoMenu
+	for _, nome := range nomes {
+		texto := "  " + nome
+		if nome == s.Perfil.Nome {
+			texto = "● " + nome
+		}
+		opcoes = append(opcoes, OpcaoMenu{Texto: texto, Executar: func() {
+			if err := sessaoEscolherPerfil(s, nome); err != nil {
+				jogo.SetMessage(tr("perfil.erro", err), 5*time.Second)
+			}
+		}})
+	}
+	opcoes = append(opcoes,
+		OpcaoMenu{Texto: tr("perfil.novo"), Executar: func() {
+			jogoAbrirTela(jogo, &TelaNomePerfil{Confirmar: func(nome string) error {
+				if _, err := perfilCriar(nome, s.Perfil.Configuracao); err != nil {
+					return err
+				}
+				return sessaoEscolherPerfil(s, nome)
+			}})
+		}},
+		OpcaoMenu{Texto: tr("perfil.excluir"), Executar: func() { jogoAbrirTela(jogo, menuExcluirPerfil(s, nomes, menu)) }},
+		OpcaoMenu{Texto: tr("menu.voltar"), Executar: fechar},
+	)
+	menu = &Menu{
+		Titulo:      tr("perfil.titulo"),
+		Linhas:      []string{tr("perfil.ativo", s.Perfil.Nome)},
+		Opcoes:      opcoes,
+		Selecionada: slices.Index(nomes, s.Perfil.Nome),
+		AoFechar:    fechar,
+	}
+	return menu
+}
+
+// Troca para o perfil escolhido e volta à tela de título, já com as configurações dele
+func sessaoEscolherPerfil(s *Sessao, nome string) error {
+	jogo := s.Jogo
+	if err := s.TrocarPerfil(nome); err != nil {
+		return err
+	}
+	jogo.Telas = nil
+	jogoAbrirTela(jogo, menuTitulo(s))
+	jogo.SetMessage(tr("perfil.trocado", nome), 3*time.Second)
+	return nil
+}
+
+// Menu para escolher o perfil a excluir, aberto pelo menu dos perfis; o perfil em uso
+// não pode ser excluído
+func menuExcluirPerfil(s *Sessao, nomes []string, perfis *Menu) *Menu {
+	jogo := s.Jogo
+	fechar := func() { jogoFecharTela(jogo) }
+	var opcoes []OpcaoMenu
+	for _, nome := range nomes {
+		if nome == s.Perfil.Nome {
+			continue
+		}
+		opcoes = append(opcoes, OpcaoMenu{Texto: nome, Executar: func() {
+			jogoAbrirTela(jogo, &Menu{
+				Titulo: tr("perfil.confirmar", nome),
+				Linhas: []string{tr("perfil.aviso_excluir")},
+				Opcoes: []OpcaoMenu{
+					{Texto: tr("perfil.nao"), Executar: fechar},
+					{Texto: tr("perfil.sim"), Executar: func() {
+						if err := perfilExcluir(nome); err != nil {
+							jogo.SetMessage(tr("perfil.erro", err), 5*time.Second)
+							return
+						}
+						jogo.SetMessage(tr("perfil.excluido", nome), 3*time.Second)
+						// fecha a confirmação, a lista e o menu dos perfis, que ainda mostra o excluído
+						jogoFecharAte(jogo, perfis)
+						jogoAbrirTela(jogo, menuPerfis(s))
+					}},
+				},
+				AoFechar: fechar,
+			})
+		}})
+	}
+	linhas := []string{tr("perfil.excluir_ajuda")}
+	if len(opcoes) == 0 {
+		linhas = []string{tr("perfil.nenhum_outro")}
+	}
+	opcoes = append(opcoes, OpcaoMenu{Texto: tr("menu.voltar"), Executar: fechar})
+	return &Menu{
+		Titulo:   tr("perfil.excluir_titulo"),
+		Linhas:   linhas,
+		Opcoes:   opcoes,
+		AoFechar: fechar,
+	}
+}
+
+// TelaNomePerfil pede o nome de um perfil novo
+type TelaNomePerfil struct {
+	Nome      []rune
+	Erro      string
+	Confirmar func(nome string) error // chamada com Enter; um erro mantém a tela aberta
+}
+
+func (t *TelaNomePerfil) Desenhar(jogo *Jogo) {
+	linhas := []string{tr("perfil.nome", string(t.Nome)+"_"), t.Erro, tr("perfil.nome_ajuda")}
+	interfaceDesenharMenu(&Menu{Titulo: tr("perfil.novo_titulo"), Linhas: textoAlinhar(linhas)})
+}
+
+func (t *TelaNomePerfil) Tecla(jogo *Jogo, atalho AtalhoTecla) {
+	switch {
+	case atalho.Tecla == termbox.KeyEsc:
+		jogoFecharTela(jogo)
+	case atalho.Tecla == termbox.KeyEnter:
+		nome := strings.TrimSpace(string(t.Nome))
+		if err := perfilValidarNome(nome); err != nil {
+			t.Erro = err.Error()
+			return
+		}
+		if err := t.Confirmar(nome); err != nil {
+			t.Erro = err.Error()
+		}
+	case atalho.Tecla == termbox.KeyBackspace || atalho.Tecla == termbox.KeyBackspace2:
+		if len(t.Nome) > 0 {
+			t.Nome = t.Nome[:len(t.Nome)-1]
+		}
+	case atalho.Tecla == 0 && perfilCaractereValido(atalho.Ch) && len(t.Nome) < MaximoNomePerfil:
+		t.Nome = append(t.Nome, atalho.Ch)
+		t.Erro = ""
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// O perfil padrão, ao ser criado, fica com os arquivos que estavam na pasta atual
+func TestPerfilPadraoMigra(t *testing.T) {
+	pasta := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(pasta, "config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(pasta, "dados"))
+	t.Chdir(pasta)
+	antigos := map[string]string{
+		"teclas.json":    `{"cima": ["w"]}`,
+		ArquivoRecordes:  `[]`,
+		ArquivoProgresso: `{"desbloqueadas": {}}`,
+	}
+	for nome, conteudo := range antigos {
+		if err := os.WriteFile(nome, []byte(conteudo), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outro, err := perfilAbrir("ana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(outro.Recordes()); err == nil {
+		t.Error("os recordes da pasta atual foram copiados para outro perfil")
+	}
+
+	p, err := perfilAbrir(PerfilPadrao)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arquivos := map[string]string{"teclas.json": p.Teclas(), ArquivoRecordes: p.Recordes(), ArquivoProgresso: p.Progresso()}
+	for antigo, novo := range arquivos {
+		dados, err := os.ReadFile(novo)
+		if err != nil || string(dados) != antigos[antigo] {
+			t.Errorf("%s no perfil: %q, %v; esperado %q", antigo, dados, err, antigos[antigo])
+		}
+	}
+
+	// só na criação: depois, o perfil não é mais sobrescrito pela pasta atual
+	if err := os.WriteFile(ArquivoRecordes, []byte(`[{}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := perfilAbrir(PerfilPadrao); err != nil {
+		t.Fatal(err)
+	}
+	if dados, _ := os.ReadFile(p.Recordes()); string(dados) != antigos[ArquivoRecordes] {
+		t.Errorf("recordes do perfil sobrescritos: %q", dados)
+	}
+}
@@ -1,48 +1,49 @@
 // personagem.go - Funções para movimentação e ações do personagem
 package main
 
-import "fmt"
+import "time"
 
-// Atualiza a posição do personagem com base na tecla pressionada (WASD)
-func personagemMover(tecla rune, jogo *Jogo) {
-	dx, dy := 0, 0
-	switch tecla {
-	case 'w': dy = -1 // Move para cima
-	case 'a': dx = -1 // Move para a esquerda
-	case 's': dy = 1  // Move para baixo
-	case 'd': dx = 1  // Move para a direita
-	}
+// Anda uma célula na direção indicada; retorna true se o personagem se moveu
+func personagemMover(dir Direcao, j *Jogador, jogo *Jogo) bool {
+	dx, dy := dir.Delta()
+	j.Direcao = dir // mesmo sem conseguir andar, o personagem se vira
 
-	nx, ny := jogo.PosX+dx, jogo.PosY+dy
+	nx, ny := j.X+dx, j.Y+dy
 	// Verifica se o movimento é permitido e realiza a movimentação
 	if jogoPodeMoverPara(jogo, nx, ny) {
-		jogoMoverElemento(jogo, jogo.PosX, jogo.PosY, dx, dy)
-		jogo.PosX, jogo.PosY = nx, ny
+		jogo.Ocupantes.Mover(j.Ocupante, nx, ny)
+		jogoPublicar(jogo, Evento{Tipo: EventoJogadorMoveu, Jogador: j, X: nx, Y: ny})
+		sistemaGatilhos(jogo, j)
+		return true
+	}
+	if jogoCaixaEm(jogo, nx, ny) != nil {
+		jogo.SetMessage(tr("personagem.bloqueio"), 2*time.Second)
 	}
+	return false
 }
 
-// Define o que ocorre quando o jogador pressiona a tecla de interação
-// Neste exemplo, apenas exibe uma mensagem de status
-// Você pode expandir essa função para incluir lógica de interação com objetos
-func personagemInteragir(jogo *Jogo) {
-	// Atualmente apenas exibe uma mensagem de status
-	jogo.StatusMsg = fmt.Sprintf("Interagindo em (%d, %d)", jogo.PosX, jogo.PosY)
-
-	interagir(jogo) // logica para interagir com as caixas
+// Define o que ocorre quando o jogador pressiona a tecla de interação: age sobre a
+// caixa, o Guian, a porta ou a alavanca para onde ele está virado ou, se não houver,
+// a mais próxima (interacao.go)
+func personagemInteragir(j *Jogador, jogo *Jogo) {
+	if !jogoInteragir(jogo, j) {
+		// Sem nada ao alcance, apenas exibe uma mensagem de status
+		jogo.SetMessage(tr("personagem.interagindo", j.X, j.Y), 2*time.Second)
+	}
 }
 
-// Processa o evento do teclado e executa a ação correspondente
-func personagemExecutarAcao(ev EventoTeclado, jogo *Jogo) bool {
-	switch ev.Tipo {
-	case "sair":
-		// Retorna false para indicar que o jogo deve terminar
-		return false
-	case "interagir":
-		// Executa a ação de interação
-		personagemInteragir(jogo)
-	case "mover":
-		// Move o personagem com base na tecla
-		personagemMover(ev.Tecla, jogo)
+// Ataca o monstro se ele estiver ao lado do jogador, recuperando os tesouros roubados
+func personagemAtacar(j *Jogador, jogo *Jogo) {
+	if !jogo.MonstroAtivo || calculaDistancia(j.X, j.Y, jogo.Monstro.X, jogo.Monstro.Y) > 1 {
+		jogo.SetMessage(tr("personagem.nada_atacar"), 2*time.Second)
+		return
 	}
-	return true // Continua o jogo
-}
\ No newline at end of file
+	if !jogoFerir(jogo, jogo.Monstro.Ocupante, 1) {
+		jogo.SetMessage(tr("personagem.acertou"), 2*time.Second)
+		return
+	}
+
+	jogo.Monstro.derrotar(jogo, j)
+	jogo.MonstroAtivo = false
+	jogo.MonstroSpawn = time.Now().Add(jogo.Modo.EsperaMonstro(jogo)) // outro monstro aparece mais tarde
+}
@@ -0,0 +1,114 @@
+// porta.go - Portas e alavancas
+// Uma porta fechada bloqueia a passagem como uma parede; o jogador a abre ou fecha
+// interagindo com ela. Uma alavanca abre ou fecha todas as portas do mapa de uma vez.
+// As duas são entidades (ecs.go) e aparecem no arquivo de mapa pelos seus símbolos.
+package main
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Elementos visuais das portas e alavancas
+var (
+	PortaFechada      = Elemento{'▯', CorAmarela, CorPadrao, true}
+	PortaAberta       = Elemento{'▭', CorCinzaEscuro, CorPadrao, false}
+	AlavancaDesligada = Elemento{'¬', CorCinzaEscuro, CorPadrao, true}
+	AlavancaLigada    = Elemento{'⌐', CorAmarela, CorPadrao, true}
+)
+
+// Porta é uma passagem que pode estar aberta ou fechada
+type Porta struct {
+	*Posicao
+	Ocupante IDEntidade // entidade da porta no mapa (ecs.go)
+	Aberta   bool
+	desenho  *Desenhavel
+}
+
+// Alavanca abre ou fecha as portas do mapa
+type Alavanca struct {
+	*Posicao
+	Ocupante IDEntidade // entidade da alavanca no mapa (ecs.go)
+	Ligada   bool
+	desenho  *Desenhavel
+}
+
+// Coloca uma porta fechada em (x, y)
+func jogoAdicionarPorta(jogo *Jogo, x, y int) *Porta {
+	p := &Porta{Posicao: &Posicao{x, y}, desenho: &Desenhavel{Elemento: PortaFechada, Camada: CamadaObjeto}}
+	o := jogo.Ocupantes
+	p.Ocupante = o.Criar()
+	p.desenho.Alvo = Entidade{"porta", int(p.Ocupante)}
+	o.Colocar(p.Ocupante, p.Posicao)
+	o.Donos[p.Ocupante] = p
+	o.Desenhos[p.Ocupante] = p.desenho
+	o.Bloqueantes[p.Ocupante] = Bloqueante{}
+	o.Interativos[p.Ocupante] = &Interativo{p.interagir}
+	return p
+}
+
+// Coloca uma alavanca desligada em (x, y)
+func jogoAdicionarAlavanca(jogo *Jogo, x, y int) *Alavanca {
+	a := &Alavanca{Posicao: &Posicao{x, y}, desenho: &Desenhavel{Elemento: AlavancaDesligada, Camada: CamadaObjeto}}
+	o := jogo.Ocupantes
+	a.Ocupante = o.Criar()
+	a.desenho.Alvo = Entidade{"alavanca", int(a.Ocupante)}
+	o.Colocar(a.Ocupante, a.Posicao)
+	o.Donos[a.Ocupante] = a
+	o.Desenhos[a.Ocupante] = a.desenho
+	o.Bloqueantes[a.Ocupante] = Bloqueante{}
+	o.Interativos[a.Ocupante] = &Interativo{a.interagir}
+	return a
+}
+
+// Abre ou fecha a porta; ela não fecha com alguém (ou algo) no vão. Retorna false se
+// a porta ficou como estava.
+func (p *Porta) Alternar(jogo *Jogo) bool {
+	o := jogo.Ocupantes
+	if p.Aberta && len(o.Em(p.X, p.Y)) > 1 {
+		return false
+	}
+	p.Aberta = !p.Aberta
+	if p.Aberta {
+		p.desenho.Elemento = PortaAberta
+		delete(o.Bloqueantes, p.Ocupante)
+	} else {
+		p.desenho.Elemento = PortaFechada
+		o.Bloqueantes[p.Ocupante] = Bloqueante{}
+	}
+	return true
+}
+
+// Liga ou desliga a alavanca (ao carregar um salvamento), sem mexer nas portas
+func (a *Alavanca) Posicionar(ligada bool) {
+	a.Ligada = ligada
+	a.desenho.Elemento = AlavancaDesligada
+	if ligada {
+		a.desenho.Elemento = AlavancaLigada
+	}
+}
+
+func (p *Porta) interagir(jogo *Jogo, j *Jogador) {
+	switch {
+	case !p.Alternar(jogo):
+		jogo.SetMessage(tr("porta.bloqueada"), 2*time.Second)
+	case p.Aberta:
+		jogo.SetMessage(tr("porta.aberta"), 2*time.Second)
+	default:
+		jogo.SetMessage(tr("porta.fechada"), 2*time.Second)
+	}
+	slog.Debug("porta", "x", p.X, "y", p.Y, "aberta", p.Aberta, "jogador", j.ID)
+}
+
+// Puxar a alavanca alterna todas as portas do mapa
+func (a *Alavanca) interagir(jogo *Jogo, j *Jogador) {
+	a.Posicionar(!a.Ligada)
+	mudaram := 0
+	for _, dono := range jogo.Ocupantes.Donos {
+		if p, ok := dono.(*Porta); ok && p.Alternar(jogo) {
+			mudaram++
+		}
+	}
+	jogo.SetMessage(tr("alavanca.puxada", mudaram), 2*time.Second)
+	slog.Info("alavanca puxada", "x", a.X, "y", a.Y, "ligada", a.Ligada, "portas", mudaram, "jogador", j.ID)
+}
@@ -0,0 +1,265 @@
+// recordes.go - Estatísticas das partidas e a tabela de recordes
+// As regras da partida (jogoIniciarRegras) contam o que acontece nela: passos, caixas
+// abertas, armadilhas, tesouros roubados e recuperados, tempo de jogo. Quando a partida
+// termina, essas estatísticas entram na tabela de recordes, um arquivo JSON local. O
+// arquivo é gravado num temporário e depois renomeado por cima do anterior, então uma
+// queda no meio da gravação deixa a tabela antiga intacta, nunca uma pela metade.
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Arquivo padrão da tabela de recordes
+const ArquivoRecordes = "recordes.json"
+
+// Quantas partidas a tabela guarda; as piores saem primeiro
+const MaximoRecordes = 100
+
+// Quantos recordes a tela mostra
+const RecordesNaTela = 10
+
+// Estatisticas é o resultado de uma partida, como guardado na tabela de recordes
+type Estatisticas struct {
+	Data          time.Time `json:"data"`
+	Mapa          string    `json:"mapa"`
+	Dificuldade   string    `json:"dificuldade"`
+	Modo          string    `json:"modo"` // modo de jogo (modo.go)
+	Semente       int64     `json:"semente"`
+	Vitoria       bool      `json:"vitoria"`
+	Tempo         Duracao   `json:"tempo"` // tempo de jogo, sem as pausas, até a vitória ou a derrota
+	Pontos        int       `json:"pontos"`
+	Tesouros      int       `json:"tesouros"`
+	CaixasAbertas int       `json:"caixas_abertas"`
+	Armadilhas    int       `json:"armadilhas"`
+	Passos        int       `json:"passos"`
+	Roubados      int       `json:"tesouros_roubados"`
+	Recuperados   int       `json:"tesouros_recuperados"`
+	Monstros      int       `json:"monstros"`            // monstros que apareceram
+	Derrotados    int       `json:"monstros_derrotados"` // monstros derrotados pelos jogadores
+}
+
+// Conta o evento nas estatísticas da partida (chamada pelo dono do estado)
+func estatisticasContar(e *Estatisticas, ev Evento) {
+	switch ev.Tipo {
+	case EventoTesouroEncontrado:
+		e.Tesouros++
+		e.CaixasAbertas++
+	case EventoCaixaVazia:
+		e.CaixasAbertas++
+	case EventoArmadilha:
+		e.Armadilhas++
+		e.CaixasAbertas++
+	case EventoJogadorMoveu:
+		e.Passos++
+	case EventoTesouroRoubado:
+		e.Roubados++
+	case EventoMonstroApareceu:
+		e.Monstros++
+	case EventoMonstroDerrotado:
+		e.Derrotados++
+		e.Recuperados += ev.Quantidade
+	case EventoTempo:
+		e.Tempo += Duracao(ev.Tempo)
+	}
+}
+
+// Melhor primeiro: vitórias antes de derrotas, depois mais pontos e, no empate, menos tempo
+func recordesComparar(a, b Estatisticas) int {
+	switch {
+	case a.Vitoria != b.Vitoria:
+		if a.Vitoria {
+			return -1
+		}
+		return 1
+	case a.Pontos != b.Pontos:
+		return b.Pontos - a.Pontos
+	case a.Tempo != b.Tempo:
+		if a.Tempo < b.Tempo {
+			return -1
+		}
+		return 1
+	}
+	return a.Data.Compare(b.Data)
+}
+
+// Lê a tabela de recordes, do melhor para o pior; sem arquivo, a tabela está vazia
+func recordesCarregar(nome string) ([]Estatisticas, error) {
+	dados, err := os.ReadFile(nome)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lista []Estatisticas
+	if err := json.Unmarshal(dados, &lista); err != nil {
+		return nil, fmt.Errorf("%s: %w", nome, err)
+	}
+	for i := range lista {
+		// as partidas de antes dos modos de jogo são do clássico
+		lista[i].Modo = cmp.Or(lista[i].Modo, ModoPadrao)
+	}
+	slices.SortStableFunc(lista, recordesComparar)
+	return lista, nil
+}
+
+// Põe a partida na tabela de recordes; retorna a posição dela entre as partidas do
+// mesmo modo de jogo (1 é a melhor), ou 0 se ela não coube na tabela
+func recordesAdicionar(nome string, e Estatisticas) (int, error) {
+	lista, err := recordesCarregar(nome)
+	if err != nil {
+		return 0, err
+	}
+	pos, _ := slices.BinarySearchFunc(lista, e, func(a, b Estatisticas) int {
+		if recordesComparar(a, b) <= 0 {
+			return -1 // empates ficam atrás dos recordes mais antigos
+		}
+		return 1
+	})
+	lista = slices.Insert(lista, pos, e)
+	if len(lista) > MaximoRecordes {
+		lista = lista[:MaximoRecordes]
+	}
+
+	dados, err := json.MarshalIndent(lista, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := gravarAtomico(nome, dados); err != nil {
+		return 0, err
+	}
+	if pos >= MaximoRecordes {
+		return 0, nil
+	}
+	posicao := 1
+	for _, r := range lista[:pos] {
+		if r.Modo == e.Modo {
+			posicao++
+		}
+	}
+	return posicao, nil
+}
+
+// Grava o arquivo de uma vez: escreve um temporário na mesma pasta, força os dados
+// para o disco e o renomeia por cima do original
+func gravarAtomico(nome string, dados []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(nome), "."+filepath.Base(nome)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// se algo falhar antes do rename, o temporário não fica para trás
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(dados); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), nome)
+}
+
+// Registra a partida que acabou de terminar na tabela de recordes (chamada pelo dono
+// do estado, ao tratar o fim de jogo). Reproduções não são registradas.
+func jogoRegistrarRecorde(jogo *Jogo) {
+	if jogo.Recordes == "" || jogo.Estatisticas == nil {
+		return
+	}
+	e := *jogo.Estatisticas
+	e.Data = time.Now()
+	e.Dificuldade = jogo.Configuracao.Dificuldade
+	e.Modo = jogo.Modo.Nome()
+	e.Vitoria = jogo.Vitoria
+	e.Pontos = jogo.Pontos
+
+	pos, err := recordesAdicionar(jogo.Recordes, e)
+	if err != nil {
+		slog.Error("falha ao gravar o recorde", "arquivo", jogo.Recordes, "erro", err)
+		jogo.Notificar(tr("recordes.erro", err), PrioridadeAviso, 5*time.Second)
+		return
+	}
+	slog.Info("recorde registrado", "arquivo", jogo.Recordes, "modo", e.Modo, "posicao", pos, "pontos", e.Pontos,
+		"tempo", time.Duration(e.Tempo), "vitoria", e.Vitoria)
+	if pos > 0 && pos <= RecordesNaTela {
+		jogo.Notificar(tr("recordes.novo", pos), PrioridadeInfo, 5*time.Second)
+	}
+}
+
+// Tempo de jogo como "m:ss"
+func recordesTempo(d Duracao) string {
+	s := int(time.Duration(d).Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// Linhas da tabela de recordes, com o cabeçalho, todas da mesma largura
+func recordesLinhas(lista []Estatisticas, n int) []string {
+	if len(lista) == 0 {
+		return []string{tr("recordes.vazio")}
+	}
+	formato := "%3s %-8s %6s %6s %4s  %-12s %-8s %s"
+	var colunas []any
+	for _, c := range strings.Split(tr("recordes.colunas"), "|") {
+		colunas = append(colunas, c)
+	}
+	linhas := []string{fmt.Sprintf(formato, colunas...)}
+	for i, e := range lista[:min(n, len(lista))] {
+		resultado := tr("recordes.derrota")
+		if e.Vitoria {
+			resultado = tr("recordes.vitoria")
+		}
+		linhas = append(linhas, fmt.Sprintf(formato, fmt.Sprintf("%d.", i+1), resultado, fmt.Sprint(e.Pontos),
+			recordesTempo(e.Tempo), fmt.Sprint(e.Tesouros), filepath.Base(e.Mapa), tr("dificuldade."+e.Dificuldade),
+			e.Data.Format("2006-01-02")))
+	}
+	return textoAlinhar(linhas)
+}
+
+// Completa as linhas com espaços até a largura da maior, para que a caixa do menu
+// as centralize juntas
+func textoAlinhar(linhas []string) []string {
+	largura := 0
+	for _, l := range linhas {
+		largura = max(largura, utf8.RuneCountInString(l))
+	}
+	for i, l := range linhas {
+		linhas[i] = l + strings.Repeat(" ", largura-utf8.RuneCountInString(l))
+	}
+	return linhas
+}
+
+// Abre a tela com os melhores recordes do arquivo no modo de jogo informado
+func jogoAbrirRecordes(jogo *Jogo, arquivo, modo string) {
+	lista, err := recordesCarregar(arquivo)
+	lista = slices.DeleteFunc(lista, func(e Estatisticas) bool { return e.Modo != modo })
+	linhas := recordesLinhas(lista, RecordesNaTela)
+	if err != nil {
+		linhas = []string{tr("recordes.erro", err)}
+	}
+	fechar := func() { jogoFecharTela(jogo) }
+	jogoAbrirTela(jogo, &Menu{
+		Titulo:   tr("recordes.titulo", tr("modo."+modo)),
+		Linhas:   linhas,
+		Opcoes:   []OpcaoMenu{{Texto: tr("menu.voltar"), Executar: fechar}},
+		AoFechar: fechar,
+	})
+}
@@ -0,0 +1,499 @@
+// rede.go - Modo cooperativo em rede
+// O anfitrião executa a simulação do jogo e um segundo jogador se conecta por TCP.
+// O cliente apenas envia as ações do jogador e desenha o estado recebido do anfitrião.
+//
+// Protocolo: cada mensagem é um objeto JSON em uma linha (MensagemRede).
+//  1. cliente -> anfitrião: {"tipo":"ola","versao":N}
+//  2. anfitrião -> cliente: {"tipo":"bemvindo","jogador":ID} ou {"tipo":"recusado","motivo":"..."}
+//  3. cliente -> anfitrião: {"tipo":"acao","acao":{"acao":"mover","direcao":"cima"}} a cada ação
+//  4. anfitrião -> cliente: {"tipo":"estado","estado":{...}} com as diferenças desde o último envio
+//  5. qualquer lado -> outro: {"tipo":"tchau"} antes de fechar a conexão
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Versão do protocolo de rede; cliente e anfitrião precisam usar a mesma
+const VersaoProtocolo = 3
+
+// Tipos de mensagem trocados entre cliente e anfitrião
+const (
+	MsgOla      = "ola"
+	MsgBemVindo = "bemvindo"
+	MsgRecusado = "recusado"
+	MsgAcao     = "acao"
+	MsgEstado   = "estado"
+	MsgTchau    = "tchau"
+)
+
+// MensagemRede é o envelope de todas as mensagens do protocolo
+type MensagemRede struct {
+	Tipo    string      `json:"tipo"`
+	Versao  int         `json:"versao,omitempty"`
+	Jogador int         `json:"jogador,omitempty"`
+	Motivo  string      `json:"motivo,omitempty"`
+	Acao    *Acao       `json:"acao,omitempty"`
+	Estado  *EstadoRede `json:"estado,omitempty"`
+}
+
+// CelulaRede é uma célula do mapa que mudou desde o último envio
+type CelulaRede struct {
+	X        int  `json:"x"`
+	Y        int  `json:"y"`
+	Simbolo  rune `json:"s"`
+	Cor      Cor  `json:"c"`
+	CorFundo Cor  `json:"f"`
+	Tangivel bool `json:"t,omitempty"`
+}
+
+// PosicaoRede é a posição e o placar de um jogador
+type PosicaoRede struct {
+	ID       int `json:"id,omitempty"`
+	X        int `json:"x"`
+	Y        int `json:"y"`
+	Tesouros int `json:"tesouros,omitempty"`
+	Vida     int `json:"vida"`
+}
+
+// OcupanteRede é uma entidade desenhada por cima do mapa (ecs.go)
+type OcupanteRede struct {
+	X        int      `json:"x"`
+	Y        int      `json:"y"`
+	Simbolo  rune     `json:"s"`
+	Cor      Cor      `json:"c"`
+	CorFundo Cor      `json:"f"`
+	Camada   int      `json:"camada,omitempty"`
+	Alvo     Entidade `json:"alvo"`
+}
+
+// EstadoRede é a diferença de estado enviada pelo anfitrião
+type EstadoRede struct {
+	Completo  bool           `json:"completo,omitempty"` // se true, Celulas contém o mapa inteiro
+	Largura   int            `json:"largura,omitempty"`
+	Altura    int            `json:"altura,omitempty"`
+	Celulas   []CelulaRede   `json:"celulas,omitempty"`
+	Jogadores []PosicaoRede  `json:"jogadores"`
+	Ocupantes []OcupanteRede `json:"ocupantes"` // entidades visíveis, incluindo os jogadores
+	Tesouros  int            `json:"tesouros"`
+	Meta      int            `json:"meta"`
+	Pontos    int            `json:"pontos,omitempty"`
+	Disputa   Disputa        `json:"disputa"`
+	Placar    string         `json:"placar,omitempty"` // linha do modo de jogo, já no idioma do anfitrião
+	Mensagem  string         `json:"mensagem"`
+	FimDeJogo bool           `json:"fim,omitempty"`
+	Animacoes []Disparo      `json:"animacoes,omitempty"` // animações disparadas desde o último estado
+}
+
+// EventoRemoto é uma ação (ou desconexão) de um jogador remoto, entregue ao loop principal
+type EventoRemoto struct {
+	Jogador     *Jogador
+	Acao        Acao
+	Desconectou bool
+}
+
+// ServidorRede aceita o segundo jogador e mantém o cliente sincronizado
+type ServidorRede struct {
+	jogo      *Jogo
+	listener  net.Listener
+	eventos   chan<- EventoRemoto
+	parar     <-chan struct{}
+	mu        sync.Mutex
+	conectado bool // só aceitamos um cliente por vez
+}
+
+// Começa a escutar conexões no endereço informado (ex: "127.0.0.1:4000")
+func redeServir(jogo *Jogo, endereco string, eventos chan<- EventoRemoto, parar <-chan struct{}) (*ServidorRede, error) {
+	ln, err := net.Listen("tcp", endereco)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ServidorRede{jogo: jogo, listener: ln, eventos: eventos, parar: parar}
+	go s.aceitar()
+	go func() {
+		<-parar
+		ln.Close()
+	}()
+	return s, nil
+}
+
+// Endereço em que o servidor está escutando
+func (s *ServidorRede) Endereco() string {
+	return s.listener.Addr().String()
+}
+
+// Aceita conexões até o listener ser fechado
+func (s *ServidorRede) aceitar() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.atender(conn)
+	}
+}
+
+// Faz o aperto de mão com o cliente e repassa suas ações ao loop principal
+func (s *ServidorRede) atender(conn net.Conn) {
+	defer conn.Close()
+
+	enc := json.NewEncoder(conn)
+	dec := json.NewDecoder(conn)
+
+	// o cliente tem alguns segundos para se apresentar
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var ola MensagemRede
+	if err := dec.Decode(&ola); err != nil || ola.Tipo != MsgOla {
+		return
+	}
+	if ola.Versao != VersaoProtocolo {
+		enc.Encode(MensagemRede{Tipo: MsgRecusado, Motivo: tr("rede.versao", VersaoProtocolo, ola.Versao)})
+		return
+	}
+
+	s.mu.Lock()
+	if s.conectado {
+		s.mu.Unlock()
+		enc.Encode(MensagemRede{Tipo: MsgRecusado, Motivo: tr("rede.cheia")})
+		return
+	}
+	s.conectado = true
+	s.mu.Unlock()
+
+	// o jogador entra na partida pelas mãos do dono do estado (mundo.go)
+	endereco := conn.RemoteAddr().String()
+	entrou := make(chan *Jogador, 1)
+	adicionar := IntencaoFunc(func(jogo *Jogo) {
+		j := jogoAdicionarJogador(jogo)
+		j.Remoto = true
+		jogo.SetMessage(tr("rede.entrou", j.ID+1), 3*time.Second)
+		slog.Info("jogador remoto entrou", "jogador", j.ID, "endereco", endereco)
+		entrou <- j
+	})
+	if !s.jogo.Mundo.Enviar(adicionar, s.parar) {
+		return
+	}
+	j := <-entrou
+	id := j.ID // j pertence ao dono do estado; só o número é usado aqui
+
+	conn.SetReadDeadline(time.Time{})
+	if err := enc.Encode(MensagemRede{Tipo: MsgBemVindo, Versao: VersaoProtocolo, Jogador: id}); err != nil {
+		s.desconectar(j)
+		return
+	}
+
+	// envia o estado em paralelo enquanto lê as ações do cliente
+	fim := make(chan struct{})
+	go s.enviarEstado(conn, enc, fim)
+
+	for {
+		var msg MensagemRede
+		if err := dec.Decode(&msg); err != nil || msg.Tipo == MsgTchau {
+			break
+		}
+		if msg.Tipo == MsgAcao && msg.Acao != nil {
+			select {
+			case s.eventos <- EventoRemoto{Jogador: j, Acao: *msg.Acao}:
+			case <-s.parar:
+			}
+		}
+	}
+
+	close(fim)
+	s.desconectar(j)
+}
+
+// Libera a vaga do cliente e avisa o loop principal
+func (s *ServidorRede) desconectar(j *Jogador) {
+	s.mu.Lock()
+	s.conectado = false
+	s.mu.Unlock()
+
+	select {
+	case s.eventos <- EventoRemoto{Jogador: j, Desconectou: true}:
+	case <-s.parar:
+	}
+}
+
+// Envia periodicamente as diferenças de estado para o cliente
+func (s *ServidorRede) enviarEstado(conn net.Conn, enc *json.Encoder, fim <-chan struct{}) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	var mapaAnterior [][]Elemento
+	var anterior EstadoRede
+
+	// as animações acontecem no renderizador de cada lado; o cliente só precisa saber quais começaram
+	eventos := s.jogo.Eventos.Assinar(eventosAnimados...)
+	defer eventos.Cancelar()
+	var disparos []Disparo
+
+	enviar := func() error {
+		mapa, est := redeCapturarEstado(s.jogo.Mundo.Retrato(), s.jogo.GetMessage())
+		est.Animacoes = disparos
+		if mapaAnterior == nil {
+			est.Completo = true
+			est.Altura = len(mapa)
+			if len(mapa) > 0 {
+				est.Largura = len(mapa[0])
+			}
+		}
+		est.Celulas = redeDiferenca(mapaAnterior, mapa)
+
+		// nada mudou: não precisa enviar
+		if !est.Completo && len(est.Celulas) == 0 && len(est.Animacoes) == 0 && reflect.DeepEqual(est, anterior) {
+			return nil
+		}
+
+		mapaAnterior = mapa
+		disparos = nil
+		anterior = est
+		anterior.Completo, anterior.Largura, anterior.Altura, anterior.Celulas, anterior.Animacoes = false, 0, 0, nil, nil
+		return enc.Encode(MensagemRede{Tipo: MsgEstado, Estado: &est})
+	}
+
+	for {
+		select {
+		case ev := <-eventos.C:
+			disparos = append(disparos, animacoesDoEvento(ev)...)
+		case <-ticker.C:
+			if err := enviar(); err != nil {
+				conn.Close()
+				return
+			}
+		case <-s.parar:
+			// manda o estado final antes de encerrar a conexão
+			enviar()
+			enc.Encode(MensagemRede{Tipo: MsgTchau, Motivo: tr("rede.fim_anfitriao")})
+			conn.Close()
+			return
+		case <-fim:
+			return
+		}
+	}
+}
+
+// Monta o estado para envio a partir do retrato do mundo; o mapa do retrato
+// não é alterado depois de publicado e pode ser guardado para a próxima diferença
+func redeCapturarEstado(r *Retrato, mensagem string) ([][]Elemento, EstadoRede) {
+	est := EstadoRede{
+		Tesouros:  r.Tesouros,
+		Meta:      r.Meta,
+		Pontos:    r.Pontos,
+		Disputa:   r.Disputa,
+		Placar:    r.Placar,
+		Mensagem:  mensagem,
+		FimDeJogo: r.FimDeJogo,
+	}
+	for _, j := range r.Jogadores {
+		est.Jogadores = append(est.Jogadores, PosicaoRede{ID: j.ID, X: j.X, Y: j.Y, Tesouros: j.Tesouros, Vida: j.Vida})
+	}
+	for _, o := range r.Ocupantes {
+		if d := o.Desenho; d != nil {
+			e := d.Elemento
+			est.Ocupantes = append(est.Ocupantes, OcupanteRede{o.Posicao.X, o.Posicao.Y, e.simbolo, e.cor, e.corFundo, d.Camada, d.Alvo})
+		}
+	}
+	return r.Mapa, est
+}
+
+// Lista as células que mudaram entre dois mapas (ou todas, se não houver anterior)
+func redeDiferenca(anterior, atual [][]Elemento) []CelulaRede {
+	var celulas []CelulaRede
+	for y, linha := range atual {
+		for x, elem := range linha {
+			if anterior != nil && y < len(anterior) && x < len(anterior[y]) && anterior[y][x] == elem {
+				continue
+			}
+			celulas = append(celulas, CelulaRede{
+				X: x, Y: y,
+				Simbolo:  elem.simbolo,
+				Cor:      elem.cor,
+				CorFundo: elem.corFundo,
+				Tangivel: elem.tangivel,
+			})
+		}
+	}
+	return celulas
+}
+
+// Conecta-se a um anfitrião e joga como cliente até sair ou a conexão cair
+func redeConectar(endereco string, teclas *MapaTeclas) error {
+	conn, err := net.DialTimeout("tcp", endereco, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	enc := json.NewEncoder(conn)
+	dec := json.NewDecoder(conn)
+
+	if err := enc.Encode(MensagemRede{Tipo: MsgOla, Versao: VersaoProtocolo}); err != nil {
+		return err
+	}
+	var resposta MensagemRede
+	if err := dec.Decode(&resposta); err != nil {
+		return fmt.Errorf("sem resposta do anfitrião: %w", err)
+	}
+	if resposta.Tipo == MsgRecusado {
+		return fmt.Errorf("conexão recusada: %s", resposta.Motivo)
+	}
+	if resposta.Tipo != MsgBemVindo {
+		return fmt.Errorf("resposta inesperada do anfitrião: %q", resposta.Tipo)
+	}
+
+	// o cliente mantém apenas uma cópia do estado para desenhar, que pertence a este
+	// loop, como o estado do anfitrião pertence ao loop principal dele
+	jogo := jogoNovo()
+	parar := make(chan struct{})
+	defer close(parar)
+
+	// recebe as mensagens do anfitrião em segundo plano; o canal é fechado se a conexão cair
+	recebidas := make(chan MensagemRede)
+	go func() {
+		defer close(recebidas)
+		for {
+			var msg MensagemRede
+			if err := dec.Decode(&msg); err != nil {
+				return
+			}
+			select {
+			case recebidas <- msg:
+			case <-parar:
+				return
+			}
+		}
+	}()
+
+	// lê o teclado em segundo plano; depois que a conexão termina, a tecla que estava
+	// sendo esperada é descartada e a goroutine acaba, em vez de ficar presa no envio
+	teclado := make(chan Acao)
+	go func() {
+		for {
+			a := interfaceLerAcao(teclas)
+			select {
+			case teclado <- a:
+			case <-parar:
+				return
+			}
+		}
+	}()
+
+	// redesenha a cada 100ms
+	renderizador := time.NewTicker(100 * time.Millisecond)
+	defer renderizador.Stop()
+
+	for {
+		select {
+		case a := <-teclado:
+			switch a.Tipo {
+			case AcaoNenhuma, AcaoMenuTeclas, AcaoMissoes, AcaoHistorico, AcaoDepuracao, AcaoDesfazer:
+				continue
+			case AcaoSair:
+				enc.Encode(MensagemRede{Tipo: MsgTchau})
+				return nil
+			}
+			if err := enc.Encode(MensagemRede{Tipo: MsgAcao, Acao: &a}); err != nil {
+				return err
+			}
+		case msg, ok := <-recebidas:
+			if !ok {
+				jogo.SetMessage(tr("rede.perdida"), time.Minute)
+				return redeEncerrada(&jogo)
+			}
+			switch msg.Tipo {
+			case MsgEstado:
+				if msg.Estado != nil {
+					redeAplicarEstado(&jogo, msg.Estado)
+				}
+			case MsgTchau:
+				if !jogo.FimDeJogo {
+					jogo.SetMessage(tr("rede.encerrada", msg.Motivo), time.Minute)
+				}
+				return redeEncerrada(&jogo)
+			}
+		case <-renderizador.C:
+			interfaceDesenharJogo(&jogo)
+		}
+	}
+}
+
+// Deixa a mensagem final visível antes de o cliente sair
+func redeEncerrada(jogo *Jogo) error {
+	interfaceDesenharJogo(jogo)
+	time.Sleep(5 * time.Second)
+	return nil
+}
+
+// Aplica no jogo local do cliente a diferença recebida do anfitrião
+func redeAplicarEstado(jogo *Jogo, est *EstadoRede) {
+	if est.Completo {
+		jogo.Mapa = make([][]Elemento, est.Altura)
+		for y := range jogo.Mapa {
+			jogo.Mapa[y] = make([]Elemento, est.Largura)
+		}
+	}
+	for _, c := range est.Celulas {
+		if c.Y < 0 || c.Y >= len(jogo.Mapa) || c.X < 0 || c.X >= len(jogo.Mapa[c.Y]) {
+			continue
+		}
+		jogo.Mapa[c.Y][c.X] = Elemento{c.Simbolo, c.Cor, c.CorFundo, c.Tangivel}
+	}
+
+	// o cliente só desenha as entidades: elas não têm IA nem bloqueiam nada aqui
+	jogo.Ocupantes.Limpar()
+	for _, oc := range est.Ocupantes {
+		id := jogo.Ocupantes.Criar()
+		jogo.Ocupantes.Colocar(id, &Posicao{oc.X, oc.Y})
+		jogo.Ocupantes.Desenhos[id] = &Desenhavel{Elemento{oc.Simbolo, oc.Cor, oc.CorFundo, false}, oc.Camada, oc.Alvo}
+	}
+
+	// os jogadores do cliente servem ao placar; quem os desenha são as entidades acima
+	jogo.Jogadores = jogo.Jogadores[:0]
+	for _, p := range est.Jogadores {
+		jogo.Jogadores = append(jogo.Jogadores, &Jogador{
+			ID:         p.ID,
+			Posicao:    &Posicao{p.X, p.Y},
+			Inventario: &Inventario{Tesouros: p.Tesouros},
+			Vida:       &Vida{Pontos: p.Vida, Maximo: JogadorVida},
+			Elemento:   elementosJogadores[p.ID%len(elementosJogadores)],
+		})
+	}
+
+	for _, d := range est.Animacoes {
+		jogo.Animacoes.Iniciar(d, time.Now())
+	}
+
+	jogo.Tesouros = est.Tesouros
+	jogo.MetaTesouros = est.Meta
+	jogo.Pontos = est.Pontos // no cliente não há campanha: são os pontos da sessão
+	jogo.Disputa = est.Disputa
+	jogo.Modo = ModoRemoto{est.Placar}
+	jogo.FimDeJogo = est.FimDeJogo
+	// a mensagem do anfitrião substitui a anterior; sem mensagem, a barra fica vazia
+	if est.Mensagem == "" {
+		jogo.Mensagens.Limpar()
+	} else {
+		jogo.Mensagens.Adicionar(Mensagem{Texto: est.Mensagem, Chave: "anfitriao", Prioridade: PrioridadeInfo, Expira: time.Now().Add(time.Minute)})
+	}
+}
+
+// ModoRemoto é o modo de jogo visto pelo cliente: as regras ficam com o anfitrião, que
+// só manda a linha do placar
+type ModoRemoto struct {
+	placar string
+}
+
+func (m ModoRemoto) Nome() string                           { return "remoto" }
+func (m ModoRemoto) Iniciar(jogo *Jogo)                     {}
+func (m ModoRemoto) Tratar(jogo *Jogo, ev Evento)           {}
+func (m ModoRemoto) Monstro(jogo *Jogo) *Monstro            { return nil }
+func (m ModoRemoto) EsperaMonstro(jogo *Jogo) time.Duration { return 0 }
+func (m ModoRemoto) Placar(*Jogo) string                    { return m.placar }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+// Cliente de teste: conecta, se apresenta com a versão dada e lê a resposta do anfitrião
+func redeClienteTeste(t *testing.T, endereco string, versao int) (net.Conn, *json.Encoder, MensagemRede) {
+	t.Helper()
+	conn, err := net.DialTimeout("tcp", endereco, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	enc := json.NewEncoder(conn)
+	if err := enc.Encode(MensagemRede{Tipo: MsgOla, Versao: versao}); err != nil {
+		t.Fatal(err)
+	}
+	var resposta MensagemRede
+	if err := json.NewDecoder(conn).Decode(&resposta); err != nil {
+		t.Fatal(err)
+	}
+	return conn, enc, resposta
+}
+
+// Espera o próximo evento de um jogador remoto
+func redeEventoTeste(t *testing.T, remotos <-chan EventoRemoto) EventoRemoto {
+	t.Helper()
+	select {
+	case ev := <-remotos:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("nenhum evento remoto")
+		return EventoRemoto{}
+	}
+}
+
+// Aperto de mão, recusas, ações e desconexão pelo protocolo, num anfitrião local
+func TestRedeProtocolo(t *testing.T) {
+	jogo := jogoNovo()
+	if err := jogoCarregarMapa("mapa.txt", &jogo); err != nil {
+		t.Fatal(err)
+	}
+	jogoRetratar(&jogo)
+
+	// o teste faz o papel do loop principal: aplica as intenções e publica os retratos
+	parar := make(chan struct{})
+	dono := make(chan struct{})
+	go func() {
+		defer close(dono)
+		for {
+			select {
+			case in := <-jogo.Mundo.Intencoes():
+				in.Aplicar(&jogo)
+				jogoRetratar(&jogo)
+			case <-parar:
+				return
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		close(parar)
+		<-dono
+		jogoEncerrarEntidades(&jogo)
+	})
+
+	remotos := make(chan EventoRemoto)
+	servidor, err := redeServir(&jogo, "127.0.0.1:0", remotos, parar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endereco := servidor.Endereco()
+
+	conn, enc, resposta := redeClienteTeste(t, endereco, VersaoProtocolo)
+	if resposta.Tipo != MsgBemVindo || resposta.Versao != VersaoProtocolo || resposta.Jogador == 0 {
+		t.Fatalf("resposta ao cliente: %+v, esperado bem-vindo como segundo jogador", resposta)
+	}
+	id := resposta.Jogador
+
+	if _, _, r := redeClienteTeste(t, endereco, VersaoProtocolo+1); r.Tipo != MsgRecusado || r.Motivo == "" {
+		t.Errorf("versão diferente: %+v, esperado recusado com motivo", r)
+	}
+	if _, _, r := redeClienteTeste(t, endereco, VersaoProtocolo); r.Tipo != MsgRecusado || r.Motivo == "" {
+		t.Errorf("segundo cliente: %+v, esperado recusado com motivo", r)
+	}
+
+	acao := Acao{Tipo: AcaoMover, Direcao: DirecaoCima}
+	if err := enc.Encode(MensagemRede{Tipo: MsgAcao, Acao: &acao}); err != nil {
+		t.Fatal(err)
+	}
+	ev := redeEventoTeste(t, remotos)
+	if ev.Desconectou || ev.Acao.Tipo != acao.Tipo || ev.Acao.Direcao != acao.Direcao || ev.Jogador.ID != id {
+		t.Errorf("evento %+v do jogador %d, esperado a ação %+v do jogador %d", ev, ev.Jogador.ID, acao, id)
+	}
+
+	// ao sair, a vaga fica livre para outro cliente
+	enc.Encode(MensagemRede{Tipo: MsgTchau})
+	conn.Close()
+	if ev := redeEventoTeste(t, remotos); !ev.Desconectou || ev.Jogador.ID != id {
+		t.Errorf("evento %+v, esperada a desconexão do jogador %d", ev, id)
+	}
+	if _, _, r := redeClienteTeste(t, endereco, VersaoProtocolo); r.Tipo != MsgBemVindo {
+		t.Errorf("cliente depois da desconexão: %+v, esperado bem-vindo", r)
+	}
+}
@@ -0,0 +1,330 @@
+// salvamento.go - Gravação e leitura de partidas salvas
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Arquivo usado pela ação de salvar
+const ArquivoSalvamento = "jogo.sav"
+
+// Versão do formato do arquivo de salvamento
+const VersaoSalvamento = 1
+
+// ElementoSalvo é uma célula do mapa no arquivo de salvamento
+type ElementoSalvo struct {
+	Simbolo  rune `json:"s"`
+	Cor      Cor  `json:"c"`
+	CorFundo Cor  `json:"f"`
+	Tangivel bool `json:"t,omitempty"`
+}
+
+// JogadorSalvo é o estado de um jogador no arquivo de salvamento
+type JogadorSalvo struct {
+	X              int            `json:"x"`
+	Y              int            `json:"y"`
+	Tesouros       int            `json:"tesouros"`
+	UltimoVisitado *ElementoSalvo `json:"ultimo_visitado,omitempty"` // só em salvamentos antigos, em que o mapa tinha os personagens
+	Itens          []string       `json:"itens,omitempty"`
+}
+
+// CaixaSalva é uma caixa ainda fechada no arquivo de salvamento
+type CaixaSalva struct {
+	X        int       `json:"x"`
+	Y        int       `json:"y"`
+	Tipo     TipoCaixa `json:"tipo"`
+	Revelada bool      `json:"revelada,omitempty"`
+}
+
+// PortaSalva é uma porta no arquivo de salvamento
+type PortaSalva struct {
+	X      int  `json:"x"`
+	Y      int  `json:"y"`
+	Aberta bool `json:"aberta,omitempty"`
+}
+
+// AlavancaSalva é uma alavanca no arquivo de salvamento
+type AlavancaSalva struct {
+	X      int  `json:"x"`
+	Y      int  `json:"y"`
+	Ligada bool `json:"ligada,omitempty"`
+}
+
+// PortalSalvo é um portal no arquivo de salvamento
+type PortalSalvo struct {
+	X        int    `json:"x"`
+	Y        int    `json:"y"`
+	Aberto   bool   `json:"aberto,omitempty"`
+	Mensagem string `json:"mensagem,omitempty"`
+}
+
+// CampanhaSalva é o andamento da campanha no arquivo de salvamento
+type CampanhaSalva struct {
+	Arquivo string     `json:"arquivo"`
+	Nivel   int        `json:"nivel"`
+	Pontos  int        `json:"pontos,omitempty"` // dos níveis já vencidos
+	Itens   [][]string `json:"itens,omitempty"`  // dos jogadores ao começar o nível
+}
+
+// MonstroSalvo é o estado do monstro no arquivo de salvamento
+type MonstroSalvo struct {
+	X                int `json:"x"`
+	Y                int `json:"y"`
+	TesourosRoubados int `json:"tesouros_roubados"`
+}
+
+// JogoSalvo é o conteúdo do arquivo de salvamento
+type JogoSalvo struct {
+	Versao       int               `json:"versao"`
+	Mapa         [][]ElementoSalvo `json:"mapa"`
+	Jogadores    []JogadorSalvo    `json:"jogadores"`
+	Caixas       []CaixaSalva      `json:"caixas"`
+	Portas       []PortaSalva      `json:"portas,omitempty"`
+	Alavancas    []AlavancaSalva   `json:"alavancas,omitempty"`
+	Portais      []PortalSalvo     `json:"portais,omitempty"`
+	Tesouros     int               `json:"tesouros"`
+	Pontos       int               `json:"pontos,omitempty"`
+	Campanha     *CampanhaSalva    `json:"campanha,omitempty"`
+	MetaTesouros int               `json:"meta_tesouros"`
+	Disputa      Disputa           `json:"disputa"`
+	Modo         string            `json:"modo,omitempty"`        // salvamentos antigos são do modo clássico
+	EstadoModo   json.RawMessage   `json:"estado_modo,omitempty"` // relógio ou onda, conforme o modo
+	Monstro      *MonstroSalvo     `json:"monstro,omitempty"`
+	MonstroEm    time.Duration     `json:"monstro_em"` // tempo restante até o monstro aparecer
+	Objetivos    []*Objetivo       `json:"objetivos,omitempty"`
+	Decorrido    time.Duration     `json:"decorrido,omitempty"` // tempo de jogo, para os objetivos de sobreviver
+	Estatisticas *Estatisticas     `json:"estatisticas,omitempty"`
+
+	// preenchidos por salvamentoLer
+	modo     ModoJogo
+	campanha *ProgressoCampanha
+}
+
+func elementoSalvar(e Elemento) ElementoSalvo {
+	return ElementoSalvo{e.simbolo, e.cor, e.corFundo, e.tangivel}
+}
+
+func elementoCarregar(e ElementoSalvo) Elemento {
+	return Elemento{e.Simbolo, e.Cor, e.CorFundo, e.Tangivel}
+}
+
+// Grava o estado atual da partida no arquivo
+func jogoSalvar(jogo *Jogo, nome string) error {
+	salvo := JogoSalvo{
+		Versao:       VersaoSalvamento,
+		Tesouros:     jogo.Tesouros,
+		Pontos:       jogo.Pontos,
+		Estatisticas: jogo.Estatisticas,
+		MetaTesouros: jogo.MetaTesouros,
+		Disputa:      jogo.Disputa,
+		Modo:         jogo.Modo.Nome(),
+		MonstroEm:    time.Until(jogo.MonstroSpawn),
+	}
+	for _, linha := range jogo.Mapa {
+		var l []ElementoSalvo
+		for _, e := range linha {
+			l = append(l, elementoSalvar(e))
+		}
+		salvo.Mapa = append(salvo.Mapa, l)
+	}
+	// jogadores remotos não fazem parte do salvamento: eles se reconectam depois
+	for _, j := range jogo.Jogadores {
+		if j.Remoto {
+			continue
+		}
+		salvo.Jogadores = append(salvo.Jogadores, JogadorSalvo{j.X, j.Y, j.Tesouros, nil, j.Itens})
+	}
+	for _, c := range jogo.Caixas {
+		if !c.Removida {
+			salvo.Caixas = append(salvo.Caixas, CaixaSalva{c.X, c.Y, c.Tipo, c.Revelada})
+		}
+	}
+	for _, id := range jogo.Ocupantes.ids() {
+		switch dono := jogo.Ocupantes.Donos[id].(type) {
+		case *Porta:
+			salvo.Portas = append(salvo.Portas, PortaSalva{dono.X, dono.Y, dono.Aberta})
+		case *Alavanca:
+			salvo.Alavancas = append(salvo.Alavancas, AlavancaSalva{dono.X, dono.Y, dono.Ligada})
+		case *Portal:
+			salvo.Portais = append(salvo.Portais, PortalSalvo{dono.X, dono.Y, dono.Aberto, dono.Mensagem})
+		}
+	}
+	if c := jogo.Campanha; c != nil {
+		salvo.Campanha = &CampanhaSalva{c.Arquivo, c.Nivel, c.Pontos, c.Itens}
+	}
+	if m := jogo.Missoes; m != nil {
+		salvo.Objetivos = m.Objetivos
+		salvo.Decorrido = m.Decorrido
+	}
+	estado, err := json.Marshal(jogo.Modo)
+	if err != nil {
+		return err
+	}
+	salvo.EstadoModo = estado
+	if jogo.MonstroAtivo {
+		salvo.Monstro = &MonstroSalvo{jogo.Monstro.X, jogo.Monstro.Y, jogo.Monstro.Tesouros}
+	}
+
+	dados, err := json.Marshal(salvo)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(nome, dados, 0644); err != nil {
+		slog.Error("falha ao salvar a partida", "arquivo", nome, "erro", err)
+		return err
+	}
+	slog.Info("partida salva", "arquivo", nome)
+	return nil
+}
+
+// Lê e confere o arquivo de salvamento, sem mexer na partida atual
+func salvamentoLer(nome string) (*JogoSalvo, error) {
+	dados, err := os.ReadFile(nome)
+	if err != nil {
+		return nil, err
+	}
+
+	var salvo JogoSalvo
+	if err := json.Unmarshal(dados, &salvo); err != nil {
+		return nil, fmt.Errorf("%s: %w", nome, err)
+	}
+	if salvo.Versao != VersaoSalvamento {
+		return nil, fmt.Errorf("%s: versão de salvamento %d não suportada", nome, salvo.Versao)
+	}
+	if len(salvo.Mapa) == 0 || len(salvo.Jogadores) == 0 {
+		return nil, fmt.Errorf("%s: salvamento incompleto", nome)
+	}
+	salvo.modo, err = modoNovo(cmp.Or(salvo.Modo, ModoPadrao))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", nome, err)
+	}
+	if salvo.EstadoModo != nil {
+		if err := json.Unmarshal(salvo.EstadoModo, salvo.modo); err != nil {
+			return nil, fmt.Errorf("%s: %w", nome, err)
+		}
+	}
+
+	// as posições guardadas precisam estar dentro do mapa salvo
+	fora := func(x, y int) bool {
+		return y < 0 || y >= len(salvo.Mapa) || x < 0 || x >= len(salvo.Mapa[y])
+	}
+	for _, js := range salvo.Jogadores {
+		if fora(js.X, js.Y) {
+			return nil, fmt.Errorf("%s: jogador em (%d, %d), fora do mapa", nome, js.X, js.Y)
+		}
+	}
+	for _, c := range salvo.Caixas {
+		if fora(c.X, c.Y) {
+			return nil, fmt.Errorf("%s: caixa em (%d, %d), fora do mapa", nome, c.X, c.Y)
+		}
+	}
+	for _, p := range salvo.Portas {
+		if fora(p.X, p.Y) {
+			return nil, fmt.Errorf("%s: porta em (%d, %d), fora do mapa", nome, p.X, p.Y)
+		}
+	}
+	for _, a := range salvo.Alavancas {
+		if fora(a.X, a.Y) {
+			return nil, fmt.Errorf("%s: alavanca em (%d, %d), fora do mapa", nome, a.X, a.Y)
+		}
+	}
+	for _, p := range salvo.Portais {
+		if fora(p.X, p.Y) {
+			return nil, fmt.Errorf("%s: portal em (%d, %d), fora do mapa", nome, p.X, p.Y)
+		}
+	}
+	if m := salvo.Monstro; m != nil && fora(m.X, m.Y) {
+		return nil, fmt.Errorf("%s: monstro em (%d, %d), fora do mapa", nome, m.X, m.Y)
+	}
+
+	// a partida salva no meio de uma campanha continua nela; as outras jogam só o mapa
+	if cs := salvo.Campanha; cs != nil {
+		c, err := campanhaCarregar(cs.Arquivo)
+		if err != nil {
+			return nil, err
+		}
+		if cs.Nivel < 0 || cs.Nivel >= len(c.Niveis) {
+			return nil, fmt.Errorf("%s: a campanha %s não tem o nível %d", nome, cs.Arquivo, cs.Nivel+1)
+		}
+		salvo.campanha = &ProgressoCampanha{Arquivo: cs.Arquivo, Campanha: c, Nivel: cs.Nivel, Pontos: cs.Pontos, Itens: cs.Itens}
+	}
+	return &salvo, nil
+}
+
+// Recria a partida salva num jogo já limpo (jogoLimpar), com as goroutines das caixas e do monstro
+func jogoRestaurarSalvo(jogo *Jogo, salvo *JogoSalvo) {
+	jogo.Campanha = salvo.campanha
+	jogo.Mapa = nil
+	for _, linha := range salvo.Mapa {
+		var l []Elemento
+		for _, e := range linha {
+			l = append(l, elementoCarregar(e))
+		}
+		jogo.Mapa = append(jogo.Mapa, l)
+	}
+
+	jogo.Jogadores = nil
+	for i, js := range salvo.Jogadores {
+		j := jogadorNovo(jogo, i, js.X, js.Y)
+		j.Tesouros = js.Tesouros
+		j.Itens = js.Itens
+		jogo.Jogadores = append(jogo.Jogadores, j)
+		// salvamentos antigos guardavam no mapa o personagem, com o terreno embaixo dele à parte
+		if js.UltimoVisitado != nil {
+			jogo.Mapa[js.Y][js.X] = elementoCarregar(*js.UltimoVisitado)
+		}
+	}
+
+	for _, c := range salvo.Caixas {
+		jogo.Mapa[c.Y][c.X] = Vazio // salvamentos antigos também guardavam as caixas no mapa
+		caixa := jogoAdicionarCaixa(jogo, c.X, c.Y, c.Tipo)
+		if c.Revelada {
+			caixa.Revelar()
+		}
+	}
+
+	for _, p := range salvo.Portas {
+		porta := jogoAdicionarPorta(jogo, p.X, p.Y)
+		if p.Aberta {
+			porta.Alternar(jogo)
+		}
+	}
+	for _, a := range salvo.Alavancas {
+		jogoAdicionarAlavanca(jogo, a.X, a.Y).Posicionar(a.Ligada)
+	}
+	for _, p := range salvo.Portais {
+		portal := jogoAdicionarPortal(jogo, p.X, p.Y)
+		if p.Aberto {
+			portal.Abrir(p.Mensagem)
+		}
+	}
+
+	jogo.Tesouros = salvo.Tesouros
+	jogo.Pontos = salvo.Pontos
+	if salvo.Estatisticas != nil {
+		jogo.Estatisticas = salvo.Estatisticas
+	}
+	jogo.MetaTesouros = salvo.MetaTesouros
+	jogo.Disputa = salvo.Disputa
+	jogo.Modo = salvo.modo
+	jogo.MonstroSpawn = time.Now().Add(salvo.MonstroEm)
+	// salvamentos antigos não têm objetivos: usa os padrão
+	jogo.Missoes = missoesPadrao()
+	if len(salvo.Objetivos) > 0 {
+		jogo.Missoes = &Missoes{Objetivos: salvo.Objetivos, Decorrido: salvo.Decorrido}
+	}
+	jogoIniciarRegras(jogo)
+
+	if salvo.Monstro != nil {
+		jogo.Monstro = jogo.Modo.Monstro(jogo)
+		jogo.Monstro.Iniciar(jogo)
+		jogo.Ocupantes.Mover(jogo.Monstro.Ocupante, salvo.Monstro.X, salvo.Monstro.Y)
+		jogo.Monstro.Tesouros = salvo.Monstro.TesourosRoubados
+		jogo.MonstroAtivo = true
+	}
+}
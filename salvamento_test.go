@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Um salvamento com posições fora do mapa é recusado, em vez de derrubar o jogo
+func TestSalvamentoForaDoMapa(t *testing.T) {
+	jogo := jogoNovo()
+	if err := jogoCarregarMapa("mapa.txt", &jogo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogoEncerrarEntidades(&jogo) })
+	arquivo := filepath.Join(t.TempDir(), "salvo.json")
+	if err := jogoSalvar(&jogo, arquivo); err != nil {
+		t.Fatal(err)
+	}
+	dados, err := os.ReadFile(arquivo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	casos := map[string]func(s *JogoSalvo){
+		"jogador": func(s *JogoSalvo) { s.Jogadores[0].Y = len(s.Mapa) },
+		"caixa":   func(s *JogoSalvo) { s.Caixas[0].X = -1 },
+		"monstro": func(s *JogoSalvo) { s.Monstro = &MonstroSalvo{X: len(s.Mapa[0]), Y: 0} },
+	}
+	for tipo, estragar := range casos {
+		var salvo JogoSalvo
+		if err := json.Unmarshal(dados, &salvo); err != nil {
+			t.Fatal(err)
+		}
+		estragar(&salvo)
+		estragado, err := json.Marshal(salvo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(arquivo, estragado, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := salvamentoLer(arquivo); err == nil || !strings.Contains(err.Error(), tipo+" em") {
+			t.Errorf("%s fora do mapa: erro %v", tipo, err)
+		}
+	}
+}
+
+// Carregar um arquivo inválido mantém a partida em andamento
+func TestCarregarInvalidoMantemPartida(t *testing.T) {
+	jogo := jogoNovo()
+	if err := jogoCarregarMapa("mapa.txt", &jogo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { jogoEncerrarEntidades(&jogo) })
+	s := &Sessao{Jogo: &jogo, Configuracao: jogo.Configuracao}
+	arquivo := filepath.Join(t.TempDir(), "salvo.json")
+	if err := os.WriteFile(arquivo, []byte("{corrompido"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	caixas, entidades := len(jogo.Caixas), len(jogo.Ocupantes.Posicoes)
+	if err := s.Carregar(arquivo); err == nil {
+		t.Fatal("arquivo corrompido carregado")
+	}
+	if len(jogo.Mapa) == 0 || len(jogo.Caixas) != caixas || len(jogo.Ocupantes.Posicoes) != entidades {
+		t.Errorf("partida mexida: %d linhas, %d caixas (eram %d), %d entidades (eram %d)",
+			len(jogo.Mapa), len(jogo.Caixas), caixas, len(jogo.Ocupantes.Posicoes), entidades)
+	}
+}
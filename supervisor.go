@@ -0,0 +1,93 @@
+// supervisor.go - Ciclo de vida das goroutines das entidades
+// Cada partida tem um Supervisor: as caixas, o monstro, o Guian e as regras da partida
+// rodam sob o contexto dele. Ao trocar de partida ou sair, o contexto é cancelado e o
+// supervisor espera todas terminarem, para nenhuma goroutine antiga mexer no mapa novo.
+// Como no errgroup, o primeiro erro (inclusive um pânico recuperado) cancela o grupo.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+)
+
+// Supervisor inicia e acompanha um grupo de goroutines
+type Supervisor struct {
+	ctx      context.Context
+	cancelar context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu  sync.Mutex
+	err error // primeiro erro de uma goroutine do grupo
+
+	// chamado (na goroutine que falhou) com o primeiro erro; pode ser nil
+	AoFalhar func(nome string, err error)
+}
+
+func supervisorNovo(pai context.Context) *Supervisor {
+	ctx, cancelar := context.WithCancel(pai)
+	return &Supervisor{ctx: ctx, cancelar: cancelar}
+}
+
+// Contexto do grupo, cancelado quando o grupo é encerrado ou uma goroutine falha
+func (s *Supervisor) Contexto() context.Context {
+	return s.ctx
+}
+
+// Inicia uma goroutine do grupo. Ela deve terminar quando o contexto for cancelado;
+// um erro retornado ou um pânico é registrado e cancela as demais.
+func (s *Supervisor) Iniciar(nome string, f func(ctx context.Context) error) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.executar(f); err != nil {
+			s.falhar(nome, err)
+		}
+	}()
+}
+
+// Executa f transformando um pânico em erro
+func (s *Supervisor) executar(f func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("pânico: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return f(s.ctx)
+}
+
+func (s *Supervisor) falhar(nome string, err error) {
+	slog.Error("goroutine falhou", "goroutine", nome, "erro", err)
+
+	s.mu.Lock()
+	primeiro := s.err == nil
+	if primeiro {
+		s.err = fmt.Errorf("%s: %w", nome, err)
+	}
+	s.mu.Unlock()
+
+	if primeiro {
+		s.cancelar()
+		if s.AoFalhar != nil {
+			s.AoFalhar(nome, err)
+		}
+	}
+}
+
+// Primeiro erro de uma goroutine do grupo, ou nil
+func (s *Supervisor) Erro() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Cancela o contexto e espera todas as goroutines terminarem; retorna o primeiro erro.
+// As goroutines que esperavam o dono do estado receber uma intenção desistem dela com
+// o cancelamento (Mundo.Enviar).
+func (s *Supervisor) Encerrar() error {
+	s.cancelar()
+	s.wg.Wait()
+	return s.Erro()
+}
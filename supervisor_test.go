@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Espera o contexto do grupo ser cancelado, com um limite para o teste não travar
+func esperarCancelamento(t *testing.T, s *Supervisor) {
+	t.Helper()
+	select {
+	case <-s.Contexto().Done():
+	case <-time.After(time.Second):
+		t.Fatal("o grupo não foi cancelado")
+	}
+}
+
+// Encerrar cancela o contexto e só retorna depois que todas as goroutines terminaram
+func TestSupervisorEncerrar(t *testing.T) {
+	s := supervisorNovo(context.Background())
+	var rodando atomic.Int32
+	for range 10 {
+		rodando.Add(1)
+		s.Iniciar("entidade", func(ctx context.Context) error {
+			defer rodando.Add(-1)
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond) // termina depois do cancelamento
+			return nil
+		})
+	}
+	if err := s.Encerrar(); err != nil {
+		t.Errorf("Encerrar = %v, esperado nil", err)
+	}
+	if n := rodando.Load(); n != 0 {
+		t.Errorf("%d goroutines ainda rodando depois de Encerrar", n)
+	}
+}
+
+// O primeiro erro cancela as demais goroutines e é o que Encerrar retorna
+func TestSupervisorPrimeiroErro(t *testing.T) {
+	s := supervisorNovo(context.Background())
+	var falhas []string
+	s.AoFalhar = func(nome string, err error) { falhas = append(falhas, nome) }
+
+	primeiro := errors.New("primeiro")
+	s.Iniciar("a", func(ctx context.Context) error { return primeiro })
+	esperarCancelamento(t, s)
+	// as que falham depois do cancelamento não substituem o primeiro erro
+	s.Iniciar("b", func(ctx context.Context) error {
+		<-ctx.Done()
+		return errors.New("segundo")
+	})
+
+	err := s.Encerrar()
+	if !errors.Is(err, primeiro) || !strings.HasPrefix(err.Error(), "a: ") {
+		t.Errorf("Encerrar = %v, esperado o erro de a", err)
+	}
+	if len(falhas) != 1 || falhas[0] != "a" {
+		t.Errorf("AoFalhar chamado para %q, esperado só [a]", falhas)
+	}
+}
+
+// Um pânico numa goroutine vira o erro do grupo e cancela as demais
+func TestSupervisorPanico(t *testing.T) {
+	s := supervisorNovo(context.Background())
+	s.Iniciar("espera", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	s.Iniciar("monstro", func(ctx context.Context) error { panic("socorro") })
+	esperarCancelamento(t, s)
+
+	err := s.Encerrar()
+	if err == nil || !strings.HasPrefix(err.Error(), "monstro: pânico: socorro") {
+		t.Errorf("Encerrar = %v, esperado o pânico de monstro", err)
+	}
+}
@@ -0,0 +1,381 @@
+// teclas.go - Mapeamento configurável de teclas para ações do jogo
+// As ligações são lidas de um arquivo JSON (por padrão "teclas.json"), por exemplo:
+//
+//	[
+//	  {"tecla": "z", "acao": "mover_cima"},
+//	  {"tecla": "Up", "acao": "mover_cima", "jogador": 2},
+//	  {"tecla": "Ctrl+e", "acao": "interagir"}
+//	]
+//
+// Teclas especiais usam nomes (Esc, Enter, Space, Tab, Backspace, Up, Down, Left, Right,
+// F1..F12); letras podem ter o modificador "Ctrl+". Teclas sem ligação são ignoradas.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Ações que podem ser ligadas a teclas, na ordem em que aparecem no menu, com o
+// nome usado no arquivo (a descrição exibida fica no catálogo, como "acao.<nome>")
+var acoesConfiguraveis = []struct {
+	Nome string
+	Acao Acao
+}{
+	{"mover_cima", Acao{Tipo: AcaoMover, Direcao: DirecaoCima}},
+	{"mover_esquerda", Acao{Tipo: AcaoMover, Direcao: DirecaoEsquerda}},
+	{"mover_baixo", Acao{Tipo: AcaoMover, Direcao: DirecaoBaixo}},
+	{"mover_direita", Acao{Tipo: AcaoMover, Direcao: DirecaoDireita}},
+	{"interagir", Acao{Tipo: AcaoInteragir}},
+	{"atacar", Acao{Tipo: AcaoAtacar}},
+	{"usar_item", Acao{Tipo: AcaoUsarItem}},
+	{"pausar", Acao{Tipo: AcaoPausar}},
+	{"salvar", Acao{Tipo: AcaoSalvar}},
+	{"desfazer", Acao{Tipo: AcaoDesfazer}},
+	{"teclas", Acao{Tipo: AcaoMenuTeclas}},
+	{"missoes", Acao{Tipo: AcaoMissoes}},
+	{"historico", Acao{Tipo: AcaoHistorico}},
+	{"depuracao", Acao{Tipo: AcaoDepuracao}},
+	{"sair", Acao{Tipo: AcaoSair}},
+}
+
+// Ações que valem para a partida toda e por isso só o primeiro jogador pode ligar
+var acoesDaPartida = map[TipoAcao]bool{
+	AcaoPausar:     true,
+	AcaoSalvar:     true,
+	AcaoDesfazer:   true,
+	AcaoMenuTeclas: true,
+	AcaoMissoes:    true,
+	AcaoHistorico:  true,
+	AcaoDepuracao:  true,
+	AcaoSair:       true,
+}
+
+// AtalhoTecla identifica uma tecla pressionada
+type AtalhoTecla struct {
+	Tecla termbox.Key // tecla especial ou Ctrl+letra (0 quando é um caractere)
+	Ch    rune        // caractere digitado
+}
+
+// Ligacao associa uma tecla a uma ação de um jogador, como aparece no arquivo
+type Ligacao struct {
+	Tecla   string `json:"tecla"`
+	Acao    string `json:"acao"`
+	Jogador int    `json:"jogador,omitempty"` // 1 (padrão) ou 2
+}
+
+// MapaTeclas traduz teclas pressionadas em eventos do jogo
+type MapaTeclas struct {
+	Ligacoes []Ligacao
+	indice   map[AtalhoTecla]Ligacao
+}
+
+// Ligações usadas quando não há arquivo de teclas
+func teclasPadrao() []Ligacao {
+	return []Ligacao{
+		{"w", "mover_cima", 1}, {"W", "mover_cima", 1},
+		{"a", "mover_esquerda", 1}, {"A", "mover_esquerda", 1},
+		{"s", "mover_baixo", 1}, {"S", "mover_baixo", 1},
+		{"d", "mover_direita", 1}, {"D", "mover_direita", 1},
+		{"e", "interagir", 1}, {"E", "interagir", 1},
+		{"f", "atacar", 1}, {"F", "atacar", 1},
+		{"q", "usar_item", 1}, {"Q", "usar_item", 1},
+		{"Up", "mover_cima", 2},
+		{"Left", "mover_esquerda", 2},
+		{"Down", "mover_baixo", 2},
+		{"Right", "mover_direita", 2},
+		{"Enter", "interagir", 2},
+		{"/", "atacar", 2},
+		{".", "usar_item", 2},
+		{"p", "pausar", 1}, {"P", "pausar", 1}, {"Esc", "pausar", 1},
+		{"F5", "salvar", 1},
+		{"u", "desfazer", 1}, {"U", "desfazer", 1}, {"Ctrl+z", "desfazer", 1},
+		{"F2", "teclas", 1},
+		{"m", "missoes", 1}, {"M", "missoes", 1},
+		{"h", "historico", 1}, {"H", "historico", 1},
+		{"F3", "depuracao", 1},
+		{"Ctrl+q", "sair", 1},
+	}
+}
+
+// Cria um mapa de teclas a partir de uma lista de ligações, validando cada uma
+func teclasNovo(ligacoes []Ligacao) (*MapaTeclas, error) {
+	m := &MapaTeclas{indice: make(map[AtalhoTecla]Ligacao)}
+	for _, l := range ligacoes {
+		if err := m.Ligar(l); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Carrega as ligações do arquivo; se ele não existir, usa as ligações padrão
+func teclasCarregar(nome string) (*MapaTeclas, error) {
+	dados, err := os.ReadFile(nome)
+	if errors.Is(err, os.ErrNotExist) {
+		return teclasNovo(teclasPadrao())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ligacoes []Ligacao
+	if err := json.Unmarshal(dados, &ligacoes); err != nil {
+		return nil, fmt.Errorf("%s: %w", nome, err)
+	}
+	m, err := teclasNovo(ligacoes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", nome, err)
+	}
+	return m, nil
+}
+
+// Grava as ligações atuais no arquivo
+func (m *MapaTeclas) Salvar(nome string) error {
+	dados, err := json.MarshalIndent(m.Ligacoes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(nome, append(dados, '\n'), 0644)
+}
+
+// Adiciona uma ligação; a mesma tecla não pode estar ligada a duas ações
+func (m *MapaTeclas) Ligar(l Ligacao) error {
+	atalho, err := teclaInterpretar(l.Tecla)
+	if err != nil {
+		return err
+	}
+	acao, ok := acaoPorNome(l.Acao)
+	if !ok {
+		return fmt.Errorf("ação desconhecida: %q", l.Acao)
+	}
+	if l.Jogador == 0 {
+		l.Jogador = 1
+	}
+	if l.Jogador < 1 || l.Jogador > 2 {
+		return fmt.Errorf("tecla %q: jogador inválido %d", l.Tecla, l.Jogador)
+	}
+	if l.Jogador != 1 && acoesDaPartida[acao.Tipo] {
+		return fmt.Errorf("tecla %q: a ação %q só pode ser ligada ao jogador 1", l.Tecla, l.Acao)
+	}
+
+	m.Desligar(atalho)
+	m.indice[atalho] = l
+	m.Ligacoes = append(m.Ligacoes, l)
+	return nil
+}
+
+// Remove a ligação de uma tecla, se houver
+func (m *MapaTeclas) Desligar(atalho AtalhoTecla) {
+	if _, ok := m.indice[atalho]; !ok {
+		return
+	}
+	delete(m.indice, atalho)
+	for i, l := range m.Ligacoes {
+		if a, _ := teclaInterpretar(l.Tecla); a == atalho {
+			m.Ligacoes = append(m.Ligacoes[:i], m.Ligacoes[i+1:]...)
+			return
+		}
+	}
+}
+
+// Substitui todas as teclas de uma ação de um jogador por uma única tecla; se a
+// tecla não puder ser ligada, as ligações ficam como estavam
+func (m *MapaTeclas) Religar(acao string, jogador int, atalho AtalhoTecla) error {
+	nome := teclaNome(atalho)
+	if nome == "" {
+		return fmt.Errorf("tecla sem nome (código %d)", atalho.Tecla)
+	}
+	var ligacoes []Ligacao
+	for _, l := range m.Ligacoes {
+		if l.Acao != acao || l.Jogador != jogador {
+			ligacoes = append(ligacoes, l)
+		}
+	}
+	novo, err := teclasNovo(append(ligacoes, Ligacao{Tecla: nome, Acao: acao, Jogador: jogador}))
+	if err != nil {
+		return err
+	}
+	*m = *novo
+	return nil
+}
+
+// Lista as teclas ligadas a uma ação de um jogador, em ordem alfabética
+func (m *MapaTeclas) TeclasDe(acao string, jogador int) []string {
+	var nomes []string
+	for _, l := range m.Ligacoes {
+		if l.Acao == acao && l.Jogador == jogador {
+			nomes = append(nomes, l.Tecla)
+		}
+	}
+	sort.Strings(nomes)
+	return nomes
+}
+
+// Traduz uma tecla em ação do jogo; teclas sem ligação geram AcaoNenhuma
+func (m *MapaTeclas) Traduzir(atalho AtalhoTecla) Acao {
+	l, ok := m.indice[atalho]
+	if !ok {
+		return Acao{}
+	}
+
+	acao, _ := acaoPorNome(l.Acao)
+	acao.Jogador = l.Jogador - 1
+	return acao
+}
+
+// Procura a ação correspondente ao nome usado no arquivo de teclas
+func acaoPorNome(nome string) (Acao, bool) {
+	for _, a := range acoesConfiguraveis {
+		if a.Nome == nome {
+			return a.Acao, true
+		}
+	}
+	return Acao{}, false
+}
+
+// Nomes das teclas especiais aceitos no arquivo de ligações
+var nomesTeclas = map[string]termbox.Key{
+	"Esc":       termbox.KeyEsc,
+	"Enter":     termbox.KeyEnter,
+	"Space":     termbox.KeySpace,
+	"Tab":       termbox.KeyTab,
+	"Backspace": termbox.KeyBackspace2,
+	"Delete":    termbox.KeyDelete,
+	"Insert":    termbox.KeyInsert,
+	"Home":      termbox.KeyHome,
+	"End":       termbox.KeyEnd,
+	"PgUp":      termbox.KeyPgup,
+	"PgDn":      termbox.KeyPgdn,
+	"Up":        termbox.KeyArrowUp,
+	"Down":      termbox.KeyArrowDown,
+	"Left":      termbox.KeyArrowLeft,
+	"Right":     termbox.KeyArrowRight,
+	"F1":        termbox.KeyF1,
+	"F2":        termbox.KeyF2,
+	"F3":        termbox.KeyF3,
+	"F4":        termbox.KeyF4,
+	"F5":        termbox.KeyF5,
+	"F6":        termbox.KeyF6,
+	"F7":        termbox.KeyF7,
+	"F8":        termbox.KeyF8,
+	"F9":        termbox.KeyF9,
+	"F10":       termbox.KeyF10,
+	"F11":       termbox.KeyF11,
+	"F12":       termbox.KeyF12,
+}
+
+// Converte um nome de tecla ("w", "Up", "Ctrl+s") no atalho correspondente
+func teclaInterpretar(nome string) (AtalhoTecla, error) {
+	var atalho AtalhoTecla
+	if strings.HasPrefix(nome, "Alt+") {
+		return atalho, fmt.Errorf("tecla inválida: %q (o terminal não distingue Alt de Esc)", nome)
+	}
+	if r, ok := strings.CutPrefix(nome, "Ctrl+"); ok && len(r) == 1 {
+		c := strings.ToLower(r)[0]
+		if c < 'a' || c > 'z' {
+			return atalho, fmt.Errorf("tecla inválida: %q", nome)
+		}
+		atalho.Tecla = termbox.KeyCtrlA + termbox.Key(c-'a')
+		return atalho, nil
+	}
+
+	if tecla, ok := nomesTeclas[nome]; ok {
+		atalho.Tecla = tecla
+		return atalho, nil
+	}
+	if utf8.RuneCountInString(nome) == 1 {
+		atalho.Ch, _ = utf8.DecodeRuneInString(nome)
+		return atalho, nil
+	}
+	return atalho, fmt.Errorf("tecla inválida: %q", nome)
+}
+
+// Converte um atalho de volta para o nome usado no arquivo
+func teclaNome(atalho AtalhoTecla) string {
+	if atalho.Ch != 0 {
+		return string(atalho.Ch)
+	}
+	for nome, tecla := range nomesTeclas {
+		if tecla == atalho.Tecla {
+			return nome
+		}
+	}
+	if atalho.Tecla >= termbox.KeyCtrlA && atalho.Tecla <= termbox.KeyCtrlZ {
+		return "Ctrl+" + string(rune('a'+atalho.Tecla-termbox.KeyCtrlA))
+	}
+	return ""
+}
+
+// MenuTeclas guarda o estado do menu de configuração de teclas
+type MenuTeclas struct {
+	Teclas      *MapaTeclas
+	Arquivo     string // onde as ligações são salvas ao fechar o menu
+	Selecionada int    // item selecionado
+	Aguardando  bool   // esperando a nova tecla do item selecionado
+}
+
+// ItemMenuTeclas é uma linha do menu: uma ação de um jogador
+type ItemMenuTeclas struct {
+	Acao      string // nome da ação no arquivo de teclas
+	Descricao string
+	Jogador   int
+}
+
+// Lista as ações configuráveis de cada jogador
+func (m *MenuTeclas) Itens() []ItemMenuTeclas {
+	var itens []ItemMenuTeclas
+	for jogador := 1; jogador <= 2; jogador++ {
+		for _, a := range acoesConfiguraveis {
+			if jogador != 1 && acoesDaPartida[a.Acao.Tipo] {
+				continue
+			}
+			itens = append(itens, ItemMenuTeclas{a.Nome, tr("acao." + a.Nome), jogador})
+		}
+	}
+	return itens
+}
+
+func (m *MenuTeclas) Desenhar(jogo *Jogo) {
+	interfaceDesenharMenuTeclas(m)
+}
+
+// Processa uma tecla no menu; Esc fecha o menu e salva as ligações
+func (m *MenuTeclas) Tecla(jogo *Jogo, atalho AtalhoTecla) {
+	itens := m.Itens()
+
+	if m.Aguardando {
+		m.Aguardando = false
+		if atalho.Tecla != termbox.KeyEsc || atalho.Ch != 0 {
+			item := itens[m.Selecionada]
+			if err := m.Teclas.Religar(item.Acao, item.Jogador, atalho); err != nil {
+				jogo.SetMessage(tr("teclas.invalida", err), 3*time.Second)
+			}
+		}
+		return
+	}
+
+	switch atalho.Tecla {
+	case termbox.KeyArrowUp:
+		m.Selecionada = (m.Selecionada + len(itens) - 1) % len(itens)
+	case termbox.KeyArrowDown:
+		m.Selecionada = (m.Selecionada + 1) % len(itens)
+	case termbox.KeyEnter:
+		m.Aguardando = true
+	case termbox.KeyEsc:
+		jogoFecharTela(jogo)
+		if err := m.Teclas.Salvar(m.Arquivo); err != nil {
+			jogo.SetMessage(tr("teclas.erro", err), 5*time.Second)
+		} else {
+			jogo.SetMessage(tr("teclas.salvas", m.Arquivo), 3*time.Second)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Religar troca as teclas da ação; uma tecla que não pode ser ligada não apaga as antigas
+func TestReligar(t *testing.T) {
+	m, err := teclasNovo(teclasPadrao())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Religar("atacar", 1, AtalhoTecla{Ch: 'x'}); err != nil {
+		t.Fatal(err)
+	}
+	if teclas := m.TeclasDe("atacar", 1); !slices.Equal(teclas, []string{"x"}) {
+		t.Errorf("teclas de atacar: %q, esperado [x]", teclas)
+	}
+	if a := m.Traduzir(AtalhoTecla{Ch: 'f'}); a.Tipo != AcaoNenhuma {
+		t.Errorf("a tecla antiga ainda gera %v", a.Tipo)
+	}
+	if a := m.Traduzir(AtalhoTecla{Ch: 'x'}); a.Tipo != AcaoAtacar || a.Jogador != 0 {
+		t.Errorf("x gera %+v, esperado atacar do jogador 1", a)
+	}
+
+	antes := m.TeclasDe("interagir", 1)
+	erros := []AtalhoTecla{
+		{Tecla: termbox.KeyCtrlBackslash}, // sem nome no arquivo de teclas
+		{Tecla: 0},
+	}
+	for _, atalho := range erros {
+		if err := m.Religar("interagir", 1, atalho); err == nil {
+			t.Errorf("tecla %+v ligada", atalho)
+		}
+		if teclas := m.TeclasDe("interagir", 1); !slices.Equal(teclas, antes) {
+			t.Errorf("depois de %+v, teclas de interagir: %q, esperado %q", atalho, teclas, antes)
+		}
+	}
+
+	// ações da partida só podem ser ligadas ao primeiro jogador
+	if err := m.Religar("pausar", 2, AtalhoTecla{Ch: 'z'}); err == nil {
+		t.Error("pausar ligado ao jogador 2")
+	}
+	if teclas := m.TeclasDe("pausar", 1); len(teclas) == 0 {
+		t.Error("as teclas de pausar do jogador 1 foram apagadas")
+	}
+}
+
+// Nomes do arquivo de teclas: modificadores, teclas com nome e caracteres
+func TestTeclaInterpretar(t *testing.T) {
+	casos := []struct {
+		nome   string
+		atalho AtalhoTecla
+		erro   bool
+	}{
+		{nome: "w", atalho: AtalhoTecla{Ch: 'w'}},
+		{nome: "W", atalho: AtalhoTecla{Ch: 'W'}},
+		{nome: "/", atalho: AtalhoTecla{Ch: '/'}},
+		{nome: "ç", atalho: AtalhoTecla{Ch: 'ç'}},
+		{nome: "Up", atalho: AtalhoTecla{Tecla: termbox.KeyArrowUp}},
+		{nome: "Esc", atalho: AtalhoTecla{Tecla: termbox.KeyEsc}},
+		{nome: "Backspace", atalho: AtalhoTecla{Tecla: termbox.KeyBackspace2}},
+		{nome: "F12", atalho: AtalhoTecla{Tecla: termbox.KeyF12}},
+		{nome: "Ctrl+s", atalho: AtalhoTecla{Tecla: termbox.KeyCtrlS}},
+		{nome: "Ctrl+Z", atalho: AtalhoTecla{Tecla: termbox.KeyCtrlZ}},
+		{nome: "Ctrl+1", erro: true},
+		{nome: "Ctrl+", erro: true},
+		{nome: "Alt+x", erro: true},
+		{nome: "up", erro: true},
+		{nome: "F13", erro: true},
+		{nome: "", erro: true},
+	}
+	for _, c := range casos {
+		atalho, err := teclaInterpretar(c.nome)
+		if c.erro {
+			if err == nil {
+				t.Errorf("teclaInterpretar(%q) = %+v, esperado erro", c.nome, atalho)
+			}
+			continue
+		}
+		if err != nil || atalho != c.atalho {
+			t.Errorf("teclaInterpretar(%q) = %+v, %v; esperado %+v", c.nome, atalho, err, c.atalho)
+		}
+	}
+}
+
+// Todo nome aceito volta ao mesmo atalho; teclas sem nome no arquivo dão ""
+func TestTeclaNome(t *testing.T) {
+	nomes := []string{"w", "ç", "Space", "Enter", "Tab", "Backspace", "Delete", "PgDn", "Left", "F1", "Ctrl+a", "Ctrl+q"}
+	for nome := range nomesTeclas {
+		nomes = append(nomes, nome)
+	}
+	for _, nome := range nomes {
+		atalho, err := teclaInterpretar(nome)
+		if err != nil {
+			t.Errorf("teclaInterpretar(%q): %v", nome, err)
+			continue
+		}
+		volta, err := teclaInterpretar(teclaNome(atalho))
+		if err != nil || volta != atalho {
+			t.Errorf("%q -> %+v -> %q -> %+v", nome, atalho, teclaNome(atalho), volta)
+		}
+	}
+
+	// Ctrl+i e Ctrl+m são Tab e Enter no terminal
+	if nome := teclaNome(AtalhoTecla{Tecla: termbox.KeyCtrlI}); nome != "Tab" {
+		t.Errorf("teclaNome(Ctrl+i) = %q, esperado Tab", nome)
+	}
+	for _, tecla := range []termbox.Key{termbox.KeyCtrlBackslash, termbox.KeyCtrlTilde, termbox.KeyCtrl6} {
+		if nome := teclaNome(AtalhoTecla{Tecla: tecla}); nome != "" {
+			t.Errorf("teclaNome(%d) = %q, esperado vazio", tecla, nome)
+		}
+	}
+}
@@ -0,0 +1,467 @@
+// tema.go - Temas de cores e modo de acessibilidade
+// O mapa, o salvamento e a rede guardam sempre os elementos padrão (jogo.go); o tema só
+// muda como eles aparecem na tela. Cada papel (um elemento do mapa ou uma parte da
+// interface) pode ganhar outras cores e, para os elementos, outro símbolo.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nsf/termbox-go"
+)
+
+// ModoCores é a quantidade de cores que o terminal consegue mostrar
+type ModoCores int
+
+const (
+	Cores8         ModoCores = iota // as 16 cores básicas (8 normais e 8 claras)
+	Cores256                        // paleta de 256 cores
+	CoresTrueColor                  // 24 bits (#rrggbb)
+)
+
+// Pasta onde ficam os temas que podem ser escolhidos pelo nome
+const PastaTemas = "temas"
+
+// CorTema descreve a cor da frente e do fundo de um papel. Cada cor pode ser um nome
+// ("vermelho", "cinza_escuro"), um número da paleta de 256 cores ("208") ou "#rrggbb",
+// seguidos de atributos separados por '+' ("amarelo+negrito").
+type CorTema struct {
+	Frente string `json:"frente,omitempty"`
+	Fundo  string `json:"fundo,omitempty"`
+}
+
+// Tema é o conteúdo de um arquivo de tema; papéis omitidos mantêm a aparência padrão
+type Tema struct {
+	Cores    map[string]CorTema `json:"cores"`
+	Simbolos map[string]string  `json:"simbolos,omitempty"`
+}
+
+// Elementos que o tema pode mudar, pelo nome do papel
+func temaPapeisElementos() map[string]Elemento {
+	return map[string]Elemento{
+		"personagem":         Personagem,
+		"personagem2":        Personagem2,
+		"monstro":            MonstroElemento,
+		"parede":             Parede,
+		"vegetacao":          Vegetacao,
+		"npc":                NPC,
+		"caixa":              CaixaElemento,
+		"caixa_tesouro":      CaixaTesouroAberta,
+		"caixa_armadilha":    CaixaArmadilhaAberta,
+		"caixa_vazia":        CaixaVaziaAberta,
+		"revelada_tesouro":   caixasReveladas[TESOURO],
+		"revelada_armadilha": caixasReveladas[ARMADILHA],
+		"revelada_vazia":     caixasReveladas[VAZIA],
+		"porta":              PortaFechada,
+		"porta_aberta":       PortaAberta,
+		"alavanca":           AlavancaDesligada,
+		"alavanca_ligada":    AlavancaLigada,
+		"portal":             PortalFechado,
+		"portal_aberto":      PortalAberto,
+	}
+}
+
+// Partes da interface que o tema pode mudar: a cor da frente e, se houver, a do fundo
+var temaPapeisInterface = map[string][2]*Cor{
+	"texto":   {&CorTexto, nil},
+	"titulo":  {&CorTitulo, nil},
+	"selecao": {&CorSelecao, &CorFundoSelecao},
+}
+
+// Cores das mensagens, pela prioridade
+var temaPapeisMensagens = map[string]Prioridade{
+	"dica":    PrioridadeDica,
+	"info":    PrioridadeInfo,
+	"aviso":   PrioridadeAviso,
+	"critica": PrioridadeCritica,
+}
+
+// Símbolos do modo de acessibilidade: o conteúdo das caixas não depende só da cor
+var simbolosAcessiveis = map[string]rune{
+	"caixa_tesouro":      '$',
+	"caixa_armadilha":    '✗',
+	"caixa_vazia":        '□',
+	"revelada_tesouro":   '◈',
+	"revelada_armadilha": '☒',
+	"revelada_vazia":     '▢',
+}
+
+// Nomes das 16 cores básicas, na ordem do termbox (ColorBlack é a 0)
+var nomesCores = []string{
+	"preto", "vermelho", "verde", "amarelo", "azul", "magenta", "ciano", "branco",
+	"cinza_escuro", "vermelho_claro", "verde_claro", "amarelo_claro",
+	"azul_claro", "magenta_claro", "ciano_claro", "cinza_claro",
+}
+
+// Valores RGB das 16 cores básicas (os do xterm)
+var rgbCoresBasicas = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+var atributosCores = map[string]termbox.Attribute{
+	"negrito":    termbox.AttrBold,
+	"fraco":      termbox.AttrDim,
+	"sublinhado": termbox.AttrUnderline,
+	"invertido":  termbox.AttrReverse,
+}
+
+// Tipos de cor que um tema pode usar
+const (
+	corPadrao = iota // a cor padrão do terminal
+	corBasica        // uma das 16 cores básicas
+	cor256           // um número da paleta de 256 cores
+	corRGB           // #rrggbb
+)
+
+// corEspecificada é uma cor lida do tema, antes de ser ajustada ao modo do terminal
+type corEspecificada struct {
+	tipo      int
+	indice    int // corBasica e cor256
+	r, g, b   uint8
+	atributos termbox.Attribute
+}
+
+// Lê a descrição de uma cor, como "amarelo+negrito", "208" ou "#e69f00"
+func corLer(texto string) (corEspecificada, error) {
+	partes := strings.Split(strings.TrimSpace(texto), "+")
+	var c corEspecificada
+	for _, a := range partes[1:] {
+		attr, ok := atributosCores[a]
+		if !ok {
+			return c, fmt.Errorf("atributo de cor desconhecido: %q", a)
+		}
+		c.atributos |= attr
+	}
+
+	nome := partes[0]
+	if nome == "" || nome == "padrao" {
+		return c, nil
+	}
+	for i, n := range nomesCores {
+		if n == nome {
+			c.tipo, c.indice = corBasica, i
+			return c, nil
+		}
+	}
+	if hex, ok := strings.CutPrefix(nome, "#"); ok {
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return c, fmt.Errorf("cor inválida: %q (use #rrggbb)", nome)
+		}
+		c.tipo = corRGB
+		c.r, c.g, c.b = uint8(v>>16), uint8(v>>8), uint8(v)
+		return c, nil
+	}
+	if n, err := strconv.Atoi(nome); err == nil {
+		if n < 0 || n > 255 {
+			return c, fmt.Errorf("cor fora da paleta de 256 cores: %d", n)
+		}
+		c.tipo, c.indice = cor256, n
+		return c, nil
+	}
+	return c, fmt.Errorf("cor desconhecida: %q", nome)
+}
+
+// A cor de uma das 256 da paleta em RGB
+func rgbDaPaleta(n int) (uint8, uint8, uint8) {
+	switch {
+	case n < 16:
+		c := rgbCoresBasicas[n]
+		return c[0], c[1], c[2]
+	case n < 232:
+		n -= 16
+		nivel := func(v int) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return uint8(55 + v*40)
+		}
+		return nivel(n / 36), nivel(n / 6 % 6), nivel(n % 6)
+	default:
+		v := uint8(8 + (n-232)*10)
+		return v, v, v
+	}
+}
+
+// A cor da paleta de 256 cores mais parecida com a cor RGB (no cubo 6x6x6 ou na escala de cinza)
+func paletaMaisProxima(r, g, b uint8) int {
+	melhor, menor := 0, -1
+	for n := 16; n < 256; n++ {
+		pr, pg, pb := rgbDaPaleta(n)
+		if d := distanciaRGB(r, g, b, pr, pg, pb); menor < 0 || d < menor {
+			melhor, menor = n, d
+		}
+	}
+	return melhor
+}
+
+// A cor básica mais parecida com a cor RGB
+func basicaMaisProxima(r, g, b uint8) int {
+	melhor, menor := 0, -1
+	for n, c := range rgbCoresBasicas {
+		if d := distanciaRGB(r, g, b, c[0], c[1], c[2]); menor < 0 || d < menor {
+			melhor, menor = n, d
+		}
+	}
+	return melhor
+}
+
+func distanciaRGB(r1, g1, b1, r2, g2, b2 uint8) int {
+	dr, dg, db := int(r1)-int(r2), int(g1)-int(g2), int(b1)-int(b2)
+	return dr*dr + dg*dg + db*db
+}
+
+// Converte a cor para o que o terminal consegue mostrar no modo indicado
+func (c corEspecificada) resolver(modo ModoCores) Cor {
+	switch c.tipo {
+	case corBasica:
+		if modo == CoresTrueColor {
+			return termbox.RGBToAttribute(rgbDaPaleta(c.indice)) | c.atributos
+		}
+		return Cor(c.indice+1) | c.atributos
+	case cor256:
+		switch {
+		case modo == CoresTrueColor:
+			return termbox.RGBToAttribute(rgbDaPaleta(c.indice)) | c.atributos
+		case modo == Cores256 || c.indice < 16:
+			return Cor(c.indice+1) | c.atributos
+		}
+		return Cor(basicaMaisProxima(rgbDaPaleta(c.indice))+1) | c.atributos
+	case corRGB:
+		switch modo {
+		case CoresTrueColor:
+			return termbox.RGBToAttribute(c.r, c.g, c.b) | c.atributos
+		case Cores256:
+			return Cor(paletaMaisProxima(c.r, c.g, c.b)+1) | c.atributos
+		}
+		return Cor(basicaMaisProxima(c.r, c.g, c.b)+1) | c.atributos
+	}
+	return CorPadrao | c.atributos
+}
+
+// A cor padrão do jogo (uma cor básica do termbox) como cor especificada
+func corDoTermbox(a Cor) corEspecificada {
+	c := corEspecificada{atributos: a &^ 0x1FF}
+	if n := int(a & 0x1FF); n > 0 {
+		c.tipo, c.indice = corBasica, n-1
+	}
+	return c
+}
+
+// temaVisual é o tema pronto para desenhar, com as cores já ajustadas ao terminal
+type temaVisual struct {
+	modo      ModoCores
+	elementos map[Elemento]Elemento // elemento padrão -> como ele aparece
+}
+
+// Tema em uso; sem tema, os elementos aparecem como foram definidos
+var temaAtual = &temaVisual{modo: Cores8}
+
+// Nomes dos temas da pasta de temas, em ordem alfabética
+func temasDisponiveis() []string {
+	arquivos, _ := filepath.Glob(filepath.Join(PastaTemas, "*.json"))
+	var nomes []string
+	for _, a := range arquivos {
+		nomes = append(nomes, strings.TrimSuffix(filepath.Base(a), ".json"))
+	}
+	return nomes
+}
+
+// Lê um tema: o nome de um tema da pasta temas (ex: "daltonico") ou o caminho de um arquivo
+func temaCarregar(nome string) (*Tema, error) {
+	if nome == "" {
+		return &Tema{}, nil
+	}
+	arquivo := nome
+	if filepath.Ext(nome) == "" && !strings.ContainsRune(nome, os.PathSeparator) {
+		arquivo = filepath.Join(PastaTemas, nome+".json")
+	}
+	dados, err := os.ReadFile(arquivo)
+	if err != nil {
+		return nil, err
+	}
+	var t Tema
+	if err := json.Unmarshal(dados, &t); err != nil {
+		return nil, fmt.Errorf("%s: %w", arquivo, err)
+	}
+	if err := t.Validar(); err != nil {
+		return nil, fmt.Errorf("%s: %w", arquivo, err)
+	}
+	return &t, nil
+}
+
+// Confere se os papéis existem e se as cores e os símbolos são válidos
+func (t *Tema) Validar() error {
+	elementos := temaPapeisElementos()
+	for _, papel := range ordenadas(t.Cores) {
+		_, ehElemento := elementos[papel]
+		_, ehInterface := temaPapeisInterface[papel]
+		_, ehMensagem := temaPapeisMensagens[papel]
+		if !ehElemento && !ehInterface && !ehMensagem {
+			return fmt.Errorf("papel desconhecido: %q", papel)
+		}
+		c := t.Cores[papel]
+		if _, err := corLer(c.Frente); err != nil {
+			return fmt.Errorf("%s: %w", papel, err)
+		}
+		if _, err := corLer(c.Fundo); err != nil {
+			return fmt.Errorf("%s: %w", papel, err)
+		}
+	}
+	for _, papel := range ordenadas(t.Simbolos) {
+		if _, ok := elementos[papel]; !ok {
+			return fmt.Errorf("símbolo para um papel desconhecido: %q", papel)
+		}
+		if n := len([]rune(t.Simbolos[papel])); n != 1 {
+			return fmt.Errorf("símbolo de %q deve ter um caractere", papel)
+		}
+	}
+	return nil
+}
+
+// Chaves de um mapa em ordem, para as mensagens de erro serem sempre as mesmas
+func ordenadas[V any](m map[string]V) []string {
+	chaves := make([]string, 0, len(m))
+	for k := range m {
+		chaves = append(chaves, k)
+	}
+	sort.Strings(chaves)
+	return chaves
+}
+
+// Passa a usar o tema, com as cores ajustadas ao modo do terminal. No modo de
+// acessibilidade as caixas abertas e reveladas ganham símbolos próprios.
+// O tema já deve ter sido validado.
+func temaAplicar(t *Tema, modo ModoCores, acessivel bool) {
+	resolver := func(texto string, padrao Cor) Cor {
+		if texto == "" {
+			return corDoTermbox(padrao).resolver(modo)
+		}
+		c, _ := corLer(texto)
+		return c.resolver(modo)
+	}
+
+	visual := &temaVisual{modo: modo, elementos: make(map[Elemento]Elemento)}
+	for papel, base := range temaPapeisElementos() {
+		e := base
+		c := t.Cores[papel]
+		e.cor = resolver(c.Frente, base.cor)
+		e.corFundo = resolver(c.Fundo, base.corFundo)
+		if s, ok := simbolosAcessiveis[papel]; ok && acessivel {
+			e.simbolo = s
+		}
+		if s, ok := t.Simbolos[papel]; ok {
+			e.simbolo = []rune(s)[0]
+		}
+		visual.elementos[base] = e
+	}
+
+	// as cores da interface partem sempre dos valores padrão
+	for papel, cores := range temaPapeisInterface {
+		frente, fundo := cores[0], cores[1]
+		*frente = resolver(t.Cores[papel].Frente, coresInterfacePadrao[frente])
+		if fundo != nil {
+			*fundo = resolver(t.Cores[papel].Fundo, coresInterfacePadrao[fundo])
+		}
+	}
+	for papel, p := range temaPapeisMensagens {
+		coresPrioridade[p] = resolver(t.Cores[papel].Frente, coresMensagensPadrao[p])
+	}
+	temaAtual = visual
+}
+
+// Como o elemento aparece com o tema atual
+func temaElemento(e Elemento) Elemento {
+	if v, ok := temaAtual.elementos[e]; ok {
+		return v
+	}
+	// elementos que o tema não conhece (ex: de um salvamento antigo) só têm as cores ajustadas
+	e.cor = temaCor(e.cor)
+	e.corFundo = temaCor(e.corFundo)
+	return e
+}
+
+// Ajusta uma cor padrão do jogo (uma cor básica do termbox) ao modo de cores do tema atual
+func temaCor(c Cor) Cor {
+	if temaAtual.modo == Cores8 {
+		return c
+	}
+	return corDoTermbox(c).resolver(temaAtual.modo)
+}
+
+// Lê o modo de cores pedido na linha de comando ("auto" consulta o terminal)
+func modoCoresLer(texto string) (ModoCores, error) {
+	switch texto {
+	case "", "auto":
+		return modoCoresDoAmbiente(), nil
+	case "8", "16":
+		return Cores8, nil
+	case "256":
+		return Cores256, nil
+	case "truecolor", "24bit":
+		return CoresTrueColor, nil
+	}
+	return Cores8, fmt.Errorf("modo de cores desconhecido: %q (use auto, 8, 256 ou truecolor)", texto)
+}
+
+// Quantas cores o terminal suporta, pelas variáveis COLORTERM e TERM
+func modoCoresDoAmbiente() ModoCores {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return CoresTrueColor
+	}
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return Cores256
+	}
+	return Cores8
+}
+
+// Sequência ANSI que pinta o texto com as cores já ajustadas ao modo (ver temaElemento),
+// para desenhar fora do termbox
+func corAnsi(frente, fundo Cor, modo ModoCores) string {
+	codigos := []string{"0"}
+	for _, a := range []struct {
+		attr   Cor
+		codigo string
+	}{
+		{termbox.AttrBold, "1"}, {termbox.AttrDim, "2"}, {termbox.AttrUnderline, "4"},
+		{termbox.AttrBlink, "5"}, {termbox.AttrReverse, "7"},
+	} {
+		if frente&a.attr != 0 {
+			codigos = append(codigos, a.codigo)
+		}
+	}
+
+	cor := func(c Cor, base int) string {
+		if modo == CoresTrueColor && c >= termbox.RGBToAttribute(0, 0, 0) {
+			r, g, b := termbox.AttributeToRGB(c)
+			return fmt.Sprintf("%d;2;%d;%d;%d", base+8, r, g, b)
+		}
+		n := int(c & 0x1FF)
+		switch {
+		case n == 0:
+			return ""
+		case modo == Cores256:
+			return fmt.Sprintf("%d;5;%d", base+8, n-1)
+		case n <= 8:
+			return strconv.Itoa(base + n - 1)
+		}
+		return strconv.Itoa(base + 60 + n - 9)
+	}
+	if c := cor(frente, 30); c != "" {
+		codigos = append(codigos, c)
+	}
+	if c := cor(fundo, 40); c != "" {
+		codigos = append(codigos, c)
+	}
+	return "\x1b[" + strings.Join(codigos, ";") + "m"
+}
@@ -6,4 +6,4 @@ func Abs(x int) int {
 		return -x
 	}
 	return x
-}
\ No newline at end of file
+}